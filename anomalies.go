@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// BASELINEKEYPREFIX - object type for the rolling telemetry baseline of an asset, keyed by assetID
+const BASELINEKEYPREFIX string = "BASELINE"
+
+// ANOMALYKEYPREFIX - object type for anomaly records, keyed by assetID, time and field
+const ANOMALYKEYPREFIX string = "ANOMALY"
+
+// ANOMALYEVENT - name of the event emitted when an update contains anomalous readings
+const ANOMALYEVENT string = "anomaly"
+
+// DEFAULTANOMALYZSCORE - z-score above which a reading is anomalous, unless set at init
+const DEFAULTANOMALYZSCORE float64 = 3
+
+// ANOMALYMINSAMPLES - readings of a field needed before its baseline is used
+const ANOMALYMINSAMPLES int64 = 10
+
+// ANOMALYALPHA - smoothing factor of the exponentially weighted baseline
+const ANOMALYALPHA float64 = 0.05
+
+// FieldBaseline - exponentially weighted mean and variance of one field. The first
+// readings are weighted equally, which gives their exact mean and variance.
+type FieldBaseline struct {
+	Count    int64   `json:"count"`
+	Mean     float64 `json:"mean"`
+	Variance float64 `json:"variance"`
+}
+
+// AssetBaseline - rolling baseline of the telemetry fields of an asset
+type AssetBaseline struct {
+	AssetID string                    `json:"assetID"`
+	Fields  map[string]*FieldBaseline `json:"fields"`
+}
+
+// Anomaly - a reading that deviated from the baseline by more than the configured z-score
+type Anomaly struct {
+	AssetID   string  `json:"assetID"`
+	Field     string  `json:"field"`
+	Timestamp string  `json:"timestamp"`
+	Value     float64 `json:"value"`
+	Mean      float64 `json:"mean"`
+	StdDev    float64 `json:"stdDev"`
+	ZScore    float64 `json:"zScore"`
+}
+
+//******************** readAssetAnomalies ********************/
+
+func (t *SimpleChaincode) readAssetAnomalies(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	stateIn, err := t.validateInput(args)
+	if err != nil {
+		return nil, err
+	}
+	anomalies, err := t.getAnomaliesSince(stub, *stateIn.AssetID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	anomaliesJSON, err := json.Marshal(anomalies)
+	if err != nil {
+		return nil, errors.New("Marshal failed for anomalies" + fmt.Sprint(err))
+	}
+	return anomaliesJSON, nil
+}
+
+/*********************************  internal: anomaly detection ****************************/
+
+// updateBaseline - checks the incoming readings against the baseline of the asset, then adds them
+// to it. Returns the anomalies found, in field order, each is also recorded on the ledger.
+func (t *SimpleChaincode) updateBaseline(stub shim.ChaincodeStubInterface, assetID string, readings map[string]float64) ([]Anomaly, error) {
+	var baseline AssetBaseline
+	var anomalies []Anomaly
+
+	if len(readings) == 0 {
+		return nil, nil
+	}
+	key := compositeKey(BASELINEKEYPREFIX, assetID)
+	baselineBytes, err := stub.GetState(key)
+	if err == nil && len(baselineBytes) > 0 {
+		err = json.Unmarshal(baselineBytes, &baseline)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal baseline data obtained from ledger")
+		}
+	}
+	baseline.AssetID = assetID
+	if baseline.Fields == nil {
+		baseline.Fields = map[string]*FieldBaseline{}
+	}
+	threshold, err := t.anomalyZScore(stub)
+	if err != nil {
+		return nil, err
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(readings))
+	for field := range readings {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		value := readings[field]
+		fb, found := baseline.Fields[field]
+		if !found {
+			fb = &FieldBaseline{}
+			baseline.Fields[field] = fb
+		}
+		stdDev := math.Sqrt(fb.Variance)
+		if fb.Count >= ANOMALYMINSAMPLES && stdDev > 0 {
+			z := math.Abs(value-fb.Mean) / stdDev
+			if z > threshold {
+				anomalies = append(anomalies, Anomaly{
+					AssetID:   assetID,
+					Field:     field,
+					Timestamp: formatTime(now),
+					Value:     value,
+					Mean:      fb.Mean,
+					StdDev:    stdDev,
+					ZScore:    z,
+				})
+			}
+		}
+		// equal weights while warming up, exponential weights after
+		fb.Count++
+		alpha := math.Max(1/float64(fb.Count), ANOMALYALPHA)
+		diff := value - fb.Mean
+		incr := alpha * diff
+		fb.Mean += incr
+		fb.Variance = (1 - alpha) * (fb.Variance + diff*incr)
+	}
+
+	baselineJSON, err := json.Marshal(baseline)
+	if err != nil {
+		return nil, errors.New("Marshal failed for baseline" + fmt.Sprint(err))
+	}
+	err = stub.PutState(key, baselineJSON)
+	if err != nil {
+		return nil, errors.New("PUT ledger state failed for baseline: " + fmt.Sprint(err))
+	}
+	for _, anomaly := range anomalies {
+		anomalyJSON, err := json.Marshal(anomaly)
+		if err != nil {
+			return nil, errors.New("Marshal failed for anomaly" + fmt.Sprint(err))
+		}
+		err = stub.PutState(compositeKey(ANOMALYKEYPREFIX, assetID, timeKey(now), anomaly.Field), anomalyJSON)
+		if err != nil {
+			return nil, errors.New("PUT ledger state failed for anomaly: " + fmt.Sprint(err))
+		}
+	}
+	return anomalies, nil
+}
+
+// currentAnomalies - fields whose latest reading is anomalous. A field stays flagged until
+// it is read again, partial updates of other fields do not clear it.
+func currentAnomalies(oldState *AssetState, readings map[string]float64, anomalies []Anomaly) []string {
+	var flagged []string
+	if oldState != nil {
+		for _, field := range oldState.Anomalies {
+			if _, found := readings[field]; !found {
+				flagged = append(flagged, field)
+			}
+		}
+	}
+	for _, anomaly := range anomalies {
+		flagged = append(flagged, anomaly.Field)
+	}
+	sort.Strings(flagged)
+	return flagged
+}
+
+// emitAnomalies - sends the anomalies of an update as a single event
+func (t *SimpleChaincode) emitAnomalies(stub shim.ChaincodeStubInterface, anomalies []Anomaly) error {
+	if len(anomalies) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(anomalies)
+	if err != nil {
+		return errors.New("Marshal failed for anomaly event" + fmt.Sprint(err))
+	}
+	err = stub.SetEvent(ANOMALYEVENT, payload)
+	if err != nil {
+		return errors.New("Unable to set anomaly event: " + fmt.Sprint(err))
+	}
+	return nil
+}
+
+// getAnomaliesSince - anomalies recorded for an asset at or after since, oldest first
+func (t *SimpleChaincode) getAnomaliesSince(stub shim.ChaincodeStubInterface, assetID string, since time.Time) ([]Anomaly, error) {
+	var anomalies = []Anomaly{}
+	_, endKey := compositeRange(ANOMALYKEYPREFIX, assetID)
+	iter, err := stub.RangeQueryState(compositeKey(ANOMALYKEYPREFIX, assetID, timeKey(since)), endKey)
+	if err != nil {
+		return nil, errors.New("Unable to read anomalies from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		var anomaly Anomaly
+		_, anomalyBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read anomalies from ledger: " + fmt.Sprint(err))
+		}
+		err = json.Unmarshal(anomalyBytes, &anomaly)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal anomaly data obtained from ledger")
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+	return anomalies, nil
+}
+
+// anomalyZScore - the threshold set at init, DEFAULTANOMALYZSCORE when none was set
+func (t *SimpleChaincode) anomalyZScore(stub shim.ChaincodeStubInterface) (float64, error) {
+	state, err := t.getContractState(stub)
+	if err != nil {
+		return 0, err
+	}
+	if state.AnomalyZScore == nil {
+		return DEFAULTANOMALYZSCORE, nil
+	}
+	return *state.AnomalyZScore, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAssetAnomalies(t *testing.T) {
+	var anomalies []Anomaly
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","temperature":71,"energyMeter":0}`)
+	// a steady baseline over a rising energy meter
+	meter := 0.0
+	for i := 0; i < 12; i++ {
+		meter += float64(9 + 2*(i%2))
+		stub.mustInvoke(t, "updateAsset", fmt.Sprintf(`{"assetID":"E1","temperature":%d,"energyMeter":%g}`, 70+2*(i%2), meter))
+	}
+	if view := stub.readAsset(t, "E1"); len(view.Anomalies) != 0 {
+		t.Fatalf("steady readings flagged %v", view.Anomalies)
+	}
+
+	meter += 40
+	stub.mustInvoke(t, "updateAsset", fmt.Sprintf(`{"assetID":"E1","temperature":80,"energyMeter":%g}`, meter))
+	if view := stub.readAsset(t, "E1"); fmt.Sprint(view.Anomalies) != "[energy temperature]" {
+		t.Fatalf("unexpected anomalies %v", view.Anomalies)
+	}
+	if stub.event != ANOMALYEVENT {
+		t.Fatalf("expecting an anomaly event, got %q", stub.event)
+	}
+	// a field stays flagged until it is read again
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","temperature":71}`)
+	if view := stub.readAsset(t, "E1"); fmt.Sprint(view.Anomalies) != "[energy]" {
+		t.Fatalf("unexpected anomalies %v", view.Anomalies)
+	}
+
+	stub.mustQuery(t, "readAssetAnomalies", `{"assetID":"E1"}`, &anomalies)
+	if len(anomalies) != 2 || anomalies[0].Field != FIELDENERGY || anomalies[0].Value != 40 || anomalies[1].Field != FIELDTEMPERATURE {
+		t.Fatalf("unexpected anomaly records %+v", anomalies)
+	}
+}
+
+func TestAssetAnomaliesRejected(t *testing.T) {
+	var anomalies []Anomaly
+	stub := newTestStub(t)
+	stub.mustFailQuery(t, "readAssetAnomalies", `{}`, "mandatory")
+	stub.mustFailQuery(t, "readAssetAnomalies", `{"assetID":1}`, "Unable to unmarshal input JSON data")
+	// an asset without readings has no anomalies
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+	stub.mustQuery(t, "readAssetAnomalies", `{"assetID":"E1"}`, &anomalies)
+	if len(anomalies) != 0 {
+		t.Fatalf("unexpected anomaly records %+v", anomalies)
+	}
+}
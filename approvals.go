@@ -0,0 +1,413 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// APPROVALPOLICYKEYPREFIX - object type for approval policies, keyed by operation
+const APPROVALPOLICYKEYPREFIX string = "APPROVALPOLICY"
+
+// PROPOSALKEYPREFIX - object type for proposed operations, keyed by proposalID
+const PROPOSALKEYPREFIX string = "PROPOSAL"
+
+// APPROVALEXPIRYHOURS - hours a proposal stays open when its policy sets no expiry
+const APPROVALEXPIRYHOURS float64 = 72
+
+// OPINIT - operation name of contract initialization and version migrations
+const OPINIT string = "init"
+
+// approvalOperations - operations an approval policy may be set for
+var approvalOperations = []string{"deleteAsset", "setModel", "setNameplate", "setApprovalPolicy", "updateContractConfig", OPINIT}
+
+// proposal states, a pending proposal past its expiry is reported as expired
+const (
+	PROPOSALPENDING  string = "pending"
+	PROPOSALEXECUTED string = "executed"
+	PROPOSALEXPIRED  string = "expired"
+)
+
+// ApprovalPolicy - approvals an operation needs before it runs. An operation with a policy only
+// runs through proposeOperation, approveOperation and executeOperation.
+type ApprovalPolicy struct {
+	Operation     string   `json:"operation"`
+	Approvals     int      `json:"approvals"`               // distinct organisations that must approve, 0 removes the policy
+	Organizations []string `json:"organizations,omitempty"` // organisations that may propose and approve, any when not set
+	ExpiryHours   float64  `json:"expiryHours,omitempty"`   // hours a proposal stays open, APPROVALEXPIRYHOURS when not set
+	Updated       string   `json:"updated"`
+}
+
+// Approval - an organisation's approval of a proposal
+type Approval struct {
+	Organization string `json:"organization"`
+	Timestamp    string `json:"timestamp"`
+}
+
+// Proposal - an operation waiting for approvals, executed once enough organisations approved
+type Proposal struct {
+	ProposalID string          `json:"proposalID"`
+	Operation  string          `json:"operation"`
+	Args       json.RawMessage `json:"args"` // the JSON argument the operation runs with
+	Note       string          `json:"note,omitempty"`
+	ProposedBy string          `json:"proposedBy"` // organisation of the proposer, also its first approval
+	Required   int             `json:"required"`   // approvals required by the policy when proposed
+	Approvals  []Approval      `json:"approvals"`
+	Status     string          `json:"status"` // pending, executed or expired
+	Created    string          `json:"created"`
+	Expires    string          `json:"expires"`
+	Executed   string          `json:"executed,omitempty"` // transaction time of the execution
+}
+
+//******************** setApprovalPolicy ********************/
+
+// setApprovalPolicy - admin only, sets or removes the approval policy of an operation
+func (t *SimpleChaincode) setApprovalPolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var policy ApprovalPolicy
+
+	err := t.requireRole(stub, ROLEADMIN)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with mandatory operation")
+	}
+	err = json.Unmarshal([]byte(args[0]), &policy)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	if !isOneOf(policy.Operation, approvalOperations...) {
+		return nil, errors.New("Operation must be one of " + strings.Join(approvalOperations, ", "))
+	}
+	key := compositeKey(APPROVALPOLICYKEYPREFIX, policy.Operation)
+	if policy.Approvals == 0 {
+		err = stub.DelState(key)
+		if err != nil {
+			return nil, errors.New("DELSTATE failed for approval policy! : " + fmt.Sprint(err))
+		}
+		return nil, nil
+	}
+	if policy.Approvals < 0 || policy.ExpiryHours < 0 {
+		return nil, errors.New("Approvals and expiryHours cannot be negative")
+	}
+	if len(policy.Organizations) > 0 && policy.Approvals > len(policy.Organizations) {
+		return nil, errors.New("Approvals cannot exceed the number of organizations")
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	policy.Updated = formatTime(now)
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return nil, errors.New("Marshal failed for approval policy" + fmt.Sprint(err))
+	}
+	err = stub.PutState(key, policyJSON)
+	if err != nil {
+		return nil, errors.New("PUT ledger state failed for approval policy: " + fmt.Sprint(err))
+	}
+	return nil, nil
+}
+
+//******************** proposeOperation ********************/
+
+// proposeOperation - proposes an operation under an approval policy, approved by the proposer's
+// organisation. The caller's organisation is read from its certificate.
+func (t *SimpleChaincode) proposeOperation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var input struct {
+		Operation string          `json:"operation"`
+		Args      json.RawMessage `json:"args"`
+		Note      string          `json:"note"`
+	}
+	var operationArgs map[string]interface{}
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with mandatory operation and args")
+	}
+	err := json.Unmarshal([]byte(args[0]), &input)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	policy, err := t.getApprovalPolicy(stub, input.Operation)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, errors.New("No approval policy is set for operation: " + input.Operation)
+	}
+	if json.Unmarshal(input.Args, &operationArgs) != nil {
+		return nil, errors.New("Args must be the JSON object the operation runs with")
+	}
+	org, err := approvingOrganization(stub, *policy)
+	if err != nil {
+		return nil, err
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	expiryHours := policy.ExpiryHours
+	if expiryHours == 0 {
+		expiryHours = APPROVALEXPIRYHOURS
+	}
+	proposal := Proposal{
+		ProposalID: stub.GetTxID(),
+		Operation:  input.Operation,
+		Args:       input.Args,
+		Note:       input.Note,
+		ProposedBy: org,
+		Required:   policy.Approvals,
+		Approvals:  []Approval{{Organization: org, Timestamp: formatTime(now)}},
+		Status:     PROPOSALPENDING,
+		Created:    formatTime(now),
+		Expires:    formatTime(now.Add(time.Duration(expiryHours * float64(time.Hour)))),
+	}
+	return nil, t.putProposal(stub, proposal)
+}
+
+//******************** approveOperation ********************/
+
+// approveOperation - adds the approval of the caller's organisation to a pending proposal
+func (t *SimpleChaincode) approveOperation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	proposal, err := t.getPendingProposal(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := t.getApprovalPolicy(stub, proposal.Operation)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = &ApprovalPolicy{Operation: proposal.Operation}
+	}
+	org, err := approvingOrganization(stub, *policy)
+	if err != nil {
+		return nil, err
+	}
+	for _, approval := range proposal.Approvals {
+		if approval.Organization == org {
+			return nil, errors.New("Proposal already approved by " + org)
+		}
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	proposal.Approvals = append(proposal.Approvals, Approval{Organization: org, Timestamp: formatTime(now)})
+	return nil, t.putProposal(stub, proposal)
+}
+
+//******************** executeOperation ********************/
+
+// executeOperation - runs a proposal once enough organisations approved it, the approvals required
+// when proposed or by the current policy, whichever is higher. The operation applies its own
+// checks, such as the caller role, as when called directly.
+func (t *SimpleChaincode) executeOperation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	proposal, err := t.getPendingProposal(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := t.getApprovalPolicy(stub, proposal.Operation)
+	if err != nil {
+		return nil, err
+	}
+	required := proposal.Required
+	if policy != nil && policy.Approvals > required {
+		required = policy.Approvals
+	}
+	if len(proposal.Approvals) < required {
+		return nil, fmt.Errorf("Proposal has %d of %d approvals", len(proposal.Approvals), required)
+	}
+	proposal.Status = PROPOSALEXECUTED
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	proposal.Executed = formatTime(now)
+	err = t.putProposal(stub, proposal)
+	if err != nil {
+		return nil, err
+	}
+	operationArgs := []string{string(proposal.Args)}
+	switch proposal.Operation {
+	case "deleteAsset":
+		return t.deleteAsset(stub, operationArgs)
+	case "setModel":
+		return t.setModel(stub, operationArgs)
+	case "setNameplate":
+		return t.setNameplate(stub, operationArgs)
+	case "setApprovalPolicy":
+		return t.setApprovalPolicy(stub, operationArgs)
+	case "updateContractConfig":
+		return t.updateContractConfig(stub, operationArgs)
+	case OPINIT:
+		return t.initContract(stub, operationArgs)
+	}
+	return nil, errors.New("Unknown operation: " + proposal.Operation)
+}
+
+//******************** readProposals ********************/
+
+// readProposals - proposals, optionally only those in a given status
+func (t *SimpleChaincode) readProposals(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var query struct {
+		Status string `json:"status"`
+	}
+	var proposals = []Proposal{}
+
+	if len(args) > 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting an optional JSON string with status")
+	}
+	if len(args) == 1 {
+		err := json.Unmarshal([]byte(args[0]), &query)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal input JSON data")
+		}
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	startKey, endKey := compositeRange(PROPOSALKEYPREFIX)
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return nil, errors.New("Unable to read proposals from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		var proposal Proposal
+		_, proposalBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read proposals from ledger: " + fmt.Sprint(err))
+		}
+		err = json.Unmarshal(proposalBytes, &proposal)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal proposal data obtained from ledger")
+		}
+		if proposalExpired(proposal, now) {
+			proposal.Status = PROPOSALEXPIRED
+		}
+		if query.Status == "" || query.Status == proposal.Status {
+			proposals = append(proposals, proposal)
+		}
+	}
+	return json.Marshal(proposals)
+}
+
+//******************** readApprovalPolicies ********************/
+
+func (t *SimpleChaincode) readApprovalPolicies(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var policies = []ApprovalPolicy{}
+	for _, operation := range approvalOperations {
+		policy, err := t.getApprovalPolicy(stub, operation)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			policies = append(policies, *policy)
+		}
+	}
+	return json.Marshal(policies)
+}
+
+/*********************************  internal: approvals ****************************/
+
+// requireNoApprovalPolicy - fails when an operation is under an approval policy, which means it
+// only runs through executeOperation
+func (t *SimpleChaincode) requireNoApprovalPolicy(stub shim.ChaincodeStubInterface, operation string) error {
+	if !isOneOf(operation, approvalOperations...) {
+		return nil
+	}
+	policy, err := t.getApprovalPolicy(stub, operation)
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		return fmt.Errorf("Operation %s needs %d approvals, use proposeOperation", operation, policy.Approvals)
+	}
+	return nil
+}
+
+// getApprovalPolicy - the policy of an operation, nil when it needs no approvals
+func (t *SimpleChaincode) getApprovalPolicy(stub shim.ChaincodeStubInterface, operation string) (*ApprovalPolicy, error) {
+	var policy ApprovalPolicy
+	policyBytes, err := stub.GetState(compositeKey(APPROVALPOLICYKEYPREFIX, operation))
+	if err != nil || len(policyBytes) == 0 {
+		return nil, nil
+	}
+	err = json.Unmarshal(policyBytes, &policy)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal approval policy obtained from ledger")
+	}
+	return &policy, nil
+}
+
+// getPendingProposal - the proposal named by the single JSON argument, which must be pending
+// and not expired
+func (t *SimpleChaincode) getPendingProposal(stub shim.ChaincodeStubInterface, args []string) (Proposal, error) {
+	var input struct {
+		ProposalID string `json:"proposalID"`
+	}
+	var proposal Proposal
+
+	if len(args) != 1 {
+		return proposal, errors.New("Incorrect number of arguments. Expecting a JSON string with mandatory proposalID")
+	}
+	err := json.Unmarshal([]byte(args[0]), &input)
+	if err != nil {
+		return proposal, errors.New("Unable to unmarshal input JSON data")
+	}
+	proposalBytes, err := stub.GetState(compositeKey(PROPOSALKEYPREFIX, input.ProposalID))
+	if err != nil || len(proposalBytes) == 0 {
+		return proposal, errors.New("Proposal does not exist: " + input.ProposalID)
+	}
+	err = json.Unmarshal(proposalBytes, &proposal)
+	if err != nil {
+		return proposal, errors.New("Unable to unmarshal proposal data obtained from ledger")
+	}
+	if proposal.Status != PROPOSALPENDING {
+		return proposal, errors.New("Proposal is " + proposal.Status + ": " + proposal.ProposalID)
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return proposal, err
+	}
+	if proposalExpired(proposal, now) {
+		return proposal, errors.New("Proposal expired at " + proposal.Expires)
+	}
+	return proposal, nil
+}
+
+func (t *SimpleChaincode) putProposal(stub shim.ChaincodeStubInterface, proposal Proposal) error {
+	proposalJSON, err := json.Marshal(proposal)
+	if err != nil {
+		return errors.New("Marshal failed for proposal" + fmt.Sprint(err))
+	}
+	err = stub.PutState(compositeKey(PROPOSALKEYPREFIX, proposal.ProposalID), proposalJSON)
+	if err != nil {
+		return errors.New("PUT ledger state failed for proposal: " + fmt.Sprint(err))
+	}
+	return nil
+}
+
+// approvingOrganization - the caller's organisation, which the policy must allow to approve
+func approvingOrganization(stub shim.ChaincodeStubInterface, policy ApprovalPolicy) (string, error) {
+	org := callerOrganization(stub)
+	if org == "" {
+		return "", errors.New("Caller certificate carries no " + ORGATTRIBUTE + " attribute")
+	}
+	if len(policy.Organizations) > 0 && !isOneOf(org, policy.Organizations...) {
+		return "", errors.New("Organization " + org + " may not approve " + policy.Operation)
+	}
+	return org, nil
+}
+
+// proposalExpired - true for a pending proposal past its expiry
+func proposalExpired(proposal Proposal, now time.Time) bool {
+	expires, err := parseTime(proposal.Expires)
+	return proposal.Status == PROPOSALPENDING && err == nil && now.After(expires)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApprovalExecuted(t *testing.T) {
+	var proposals []Proposal
+	stub := newTestStub(t)
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+	stub.mustInvoke(t, "setApprovalPolicy", `{"operation":"deleteAsset","approvals":2,"organizations":["A","B","C"]}`)
+	stub.mustFail(t, "deleteAsset", `{"assetID":"E1"}`, "Operation deleteAsset needs 2 approvals, use proposeOperation")
+
+	stub.as("", "A")
+	stub.mustInvoke(t, "proposeOperation", `{"operation":"deleteAsset","args":{"assetID":"E1"}}`)
+	stub.mustQuery(t, "readProposals", `{"status":"pending"}`, &proposals)
+	if len(proposals) != 1 || proposals[0].Required != 2 || proposals[0].ProposedBy != "A" || len(proposals[0].Approvals) != 1 {
+		t.Fatalf("unexpected proposals %+v", proposals)
+	}
+	id := `{"proposalID":"` + proposals[0].ProposalID + `"}`
+	stub.mustFail(t, "executeOperation", id, "Proposal has 1 of 2 approvals")
+	stub.as("", "B")
+	stub.mustInvoke(t, "approveOperation", id)
+
+	// the policy raised after the proposal applies at execution
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "setApprovalPolicy", `{"operation":"deleteAsset","approvals":3,"organizations":["A","B","C"]}`)
+	stub.mustFail(t, "executeOperation", id, "Proposal has 2 of 3 approvals")
+	stub.as("", "C")
+	stub.mustInvoke(t, "approveOperation", id)
+	stub.as(ROLEADMIN, "C")
+	stub.mustInvoke(t, "executeOperation", id)
+	stub.mustFailQuery(t, "readAsset", `{"assetID":"E1"}`, "Unable to get asset state")
+
+	var executed []Proposal
+	stub.mustQuery(t, "readProposals", `{"status":"executed"}`, &executed)
+	if len(executed) != 1 || executed[0].Executed == "" {
+		t.Fatalf("unexpected executed proposals %+v", executed)
+	}
+	stub.mustFail(t, "executeOperation", id, "Proposal is executed")
+}
+
+func TestApprovalRejected(t *testing.T) {
+	var proposals []Proposal
+	stub := newTestStub(t)
+	stub.mustFail(t, "setApprovalPolicy", `{"operation":"deleteAsset","approvals":2}`, "not allowed")
+	stub.as(ROLEADMIN, "")
+	stub.mustFail(t, "setApprovalPolicy", `{"operation":"createAsset","approvals":2}`, "Operation must be one of")
+	stub.mustFail(t, "setApprovalPolicy", `{"operation":"deleteAsset","approvals":-1}`, "cannot be negative")
+	stub.mustFail(t, "setApprovalPolicy", `{"operation":"deleteAsset","approvals":3,"organizations":["A","B"]}`, "Approvals cannot exceed")
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+
+	stub.as("", "A")
+	stub.mustFail(t, "proposeOperation", `{"operation":"deleteAsset","args":{"assetID":"E1"}}`, "No approval policy is set")
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "setApprovalPolicy", `{"operation":"deleteAsset","approvals":2,"organizations":["A","B"],"expiryHours":1}`)
+	stub.as("", "")
+	stub.mustFail(t, "proposeOperation", `{"operation":"deleteAsset","args":{"assetID":"E1"}}`, "carries no organization attribute")
+	stub.as("", "X")
+	stub.mustFail(t, "proposeOperation", `{"operation":"deleteAsset","args":{"assetID":"E1"}}`, "Organization X may not approve deleteAsset")
+	stub.as("", "A")
+	stub.mustFail(t, "proposeOperation", `{"operation":"deleteAsset","args":"E1"}`, "Args must be the JSON object")
+	stub.mustInvoke(t, "proposeOperation", `{"operation":"deleteAsset","args":{"assetID":"E1"}}`)
+	stub.mustQuery(t, "readProposals", ``, &proposals)
+	id := `{"proposalID":"` + proposals[0].ProposalID + `"}`
+	stub.mustFail(t, "approveOperation", id, "Proposal already approved by A")
+	stub.mustFail(t, "approveOperation", `{"proposalID":"none"}`, "Proposal does not exist")
+
+	stub.now = stub.now.Add(2 * time.Hour)
+	stub.as("", "B")
+	stub.mustFail(t, "approveOperation", id, "Proposal expired at")
+	var expired []Proposal
+	stub.mustQuery(t, "readProposals", `{"status":"expired"}`, &expired)
+	if len(expired) != 1 {
+		t.Fatalf("unexpected expired proposals %+v", expired)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// BATCHMAXITEMS - most partial states accepted by one batchUpdateAssets or batchCreateAssets transaction
+const BATCHMAXITEMS int = 100
+
+// batch item outcomes
+const (
+	BATCHCREATED string = "created"
+	BATCHUPDATED string = "updated"
+	BATCHFAILED  string = "failed"
+	BATCHSKIPPED string = "notApplied" // valid, but discarded with the batch because another item failed
+)
+
+// BatchResult - outcome of one item of a batch, in the order the items were passed
+type BatchResult struct {
+	Index   int    `json:"index"`
+	AssetID string `json:"assetID,omitempty"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+//******************** batchUpdateAssets ********************/
+
+// batchUpdateAssets - creates or updates the assets in an array of partial states, with the same
+// validation and merge as createAsset and updateAsset. Items apply in order, so an asset may appear
+// more than once. Either every item applies and the per-item results are returned, or the
+// transaction fails with the per-item results in the error and no asset changes.
+func (t *SimpleChaincode) batchUpdateAssets(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	return t.applyBatch(stub, args, false)
+}
+
+//******************** batchCreateAssets ********************/
+
+// batchCreateAssets - creates the assets in an array of states as batchUpdateAssets does, but an
+// item whose asset already exists fails, so the batch never overwrites an asset. The import command
+// produces these unless asked to update.
+func (t *SimpleChaincode) batchCreateAssets(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	return t.applyBatch(stub, args, true)
+}
+
+/*********************************  internal: applyBatch ****************************/
+
+// applyBatch - applies the items of a batch in order, all or none. With createOnly an item fails
+// when its asset exists, an asset created by an earlier item included.
+func (t *SimpleChaincode) applyBatch(stub shim.ChaincodeStubInterface, args []string, createOnly bool) ([]byte, error) {
+	var items []json.RawMessage
+	var anomalies []Anomaly
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON array of asset states")
+	}
+	err := json.Unmarshal([]byte(args[0]), &items)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	if len(items) == 0 {
+		return nil, errors.New("Batch contains no asset states")
+	}
+	if len(items) > BATCHMAXITEMS {
+		return nil, errors.New("Batch exceeds " + strconv.Itoa(BATCHMAXITEMS) + " asset states")
+	}
+	contract, err := t.getContractState(stub)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]BatchResult, len(items))
+	failed := false
+	for i, item := range items {
+		results[i].Index = i
+		stateIn, err := t.validateInput([]string{string(item)})
+		if err == nil {
+			results[i].AssetID = *stateIn.AssetID
+			err = validateStrict(contract, item)
+		}
+		if err == nil && createOnly {
+			assetBytes, getErr := stub.GetState(*stateIn.AssetID)
+			if getErr == nil && len(assetBytes) > 0 {
+				err = errors.New("Asset already exists: " + *stateIn.AssetID)
+			}
+		}
+		if err == nil && !failed {
+			// once an item failed the rest are only validated, the batch is discarded anyway
+			var created bool
+			var found []Anomaly
+			created, found, err = t.putAssetState(stub, stateIn)
+			if err == nil {
+				anomalies = append(anomalies, found...)
+				results[i].Status = BATCHUPDATED
+				if created {
+					results[i].Status = BATCHCREATED
+				}
+			}
+		}
+		if err != nil {
+			failed = true
+			results[i].Status = BATCHFAILED
+			results[i].Error = err.Error()
+		} else if results[i].Status == "" {
+			results[i].Status = BATCHSKIPPED
+		}
+	}
+	if failed {
+		// items applied before the failure are rolled back with the transaction
+		for i := range results {
+			if results[i].Status != BATCHFAILED {
+				results[i].Status = BATCHSKIPPED
+			}
+		}
+	}
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return nil, errors.New("Marshal failed for batch results" + fmt.Sprint(err))
+	}
+	if failed {
+		return nil, errors.New("Batch update failed, no asset was changed: " + string(resultsJSON))
+	}
+	err = t.emitAnomalies(stub, anomalies)
+	if err != nil {
+		return nil, err
+	}
+	return resultsJSON, nil
+}
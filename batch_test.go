@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBatchUpdateAssets(t *testing.T) {
+	var results []BatchResult
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E2","floor":1}`)
+	resultsJSON := stub.mustInvoke(t, "batchUpdateAssets", `[{"assetID":"E1","floor":1},{"assetID":"E2","floor":3},{"assetID":"E1","temperature":70}]`)
+	err := json.Unmarshal(resultsJSON, &results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || results[0].Status != BATCHCREATED || results[1].Status != BATCHUPDATED || results[2].Status != BATCHUPDATED || results[2].Index != 2 {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	// later items merge into the state written by earlier ones
+	view := stub.readAsset(t, "E1")
+	if *view.Floor != 1 || *view.Temperature != 70 {
+		t.Fatalf("unexpected state %+v", view.AssetState)
+	}
+	if view = stub.readAsset(t, "E2"); *view.Floor != 3 {
+		t.Fatalf("unexpected state %+v", view.AssetState)
+	}
+}
+
+func TestBatchUpdateAssetsRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFail(t, "batchUpdateAssets", `{"assetID":"E1"}`, "Unable to unmarshal input JSON data")
+	stub.mustFail(t, "batchUpdateAssets", `[]`, "Batch contains no asset states")
+	stub.mustFail(t, "batchUpdateAssets", "["+strings.Repeat(`{"assetID":"E1"},`, BATCHMAXITEMS)+`{"assetID":"E1"}]`, "Batch exceeds 100 asset states")
+
+	// one invalid item discards the whole batch
+	_, err := stub.invoke("batchUpdateAssets", `[{"assetID":"E1"},{"floor":2},{"assetID":"E3"}]`)
+	if err == nil || !strings.Contains(err.Error(), "no asset was changed") {
+		t.Fatalf("expecting the batch to fail, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `"index":0,"assetID":"E1","status":"notApplied"`) || !strings.Contains(err.Error(), `"index":1,"status":"failed"`) ||
+		!strings.Contains(err.Error(), `"index":2,"assetID":"E3","status":"notApplied"`) {
+		t.Fatalf("unexpected item results %v", err)
+	}
+	stub.mustFailQuery(t, "readAsset", `{"assetID":"E1"}`, "")
+}
+
+func TestBatchCreateAssets(t *testing.T) {
+	var results []BatchResult
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E2","floor":1}`)
+	// an existing asset discards the whole batch
+	stub.mustFail(t, "batchCreateAssets", `[{"assetID":"E1"},{"assetID":"E2","floor":3}]`, "Asset already exists: E2")
+	stub.mustFailQuery(t, "readAsset", `{"assetID":"E1"}`, "")
+	if view := stub.readAsset(t, "E2"); *view.Floor != 1 {
+		t.Fatalf("unexpected state %+v", view.AssetState)
+	}
+	stub.mustFail(t, "batchCreateAssets", `[{"assetID":"E1"},{"assetID":"E1","floor":3}]`, "Asset already exists: E1")
+
+	resultsJSON := stub.mustInvoke(t, "batchCreateAssets", `[{"assetID":"E1","floor":1},{"assetID":"E3","floor":2}]`)
+	err := json.Unmarshal(resultsJSON, &results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Status != BATCHCREATED || results[1].Status != BATCHCREATED {
+		t.Fatalf("unexpected results %+v", results)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// CONFIDENTIALKEYPREFIX - object type for the encrypted confidential fields of an asset, keyed by assetID
+const CONFIDENTIALKEYPREFIX string = "CONFIDENTIAL"
+
+// ConfidentialRecord - the confidential fields of an asset, such as tenant terms, access codes and
+// inspector notes, encrypted by the client. The contract never sees the key or the plaintext, it
+// stores the ciphertext and returns it to the organisation holding the key and to admins.
+type ConfidentialRecord struct {
+	AssetID      string `json:"assetID"`
+	KeyID        string `json:"keyID"`                  // names the key the fields were encrypted with, chosen by the client
+	Nonce        string `json:"nonce,omitempty"`        // base64 nonce of the cipher, when it uses one
+	Ciphertext   string `json:"ciphertext"`             // base64 encrypted JSON of the confidential fields
+	Organization string `json:"organization,omitempty"` // organisation holding the key
+	Updated      string `json:"updated"`
+}
+
+//******************** setConfidentialFields ********************/
+
+// setConfidentialFields - replaces the encrypted confidential fields of an asset. Admins set new
+// records and keys, the organisation holding the key may replace the ciphertext under the same key.
+func (t *SimpleChaincode) setConfidentialFields(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var record ConfidentialRecord
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with mandatory assetID, keyID and ciphertext")
+	}
+	err := json.Unmarshal([]byte(args[0]), &record)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	record.AssetID = strings.TrimSpace(record.AssetID)
+	record.KeyID = strings.TrimSpace(record.KeyID)
+	record.Organization = strings.TrimSpace(record.Organization)
+	if record.AssetID == "" {
+		return nil, errors.New("Asset id is mandatory in the input JSON data")
+	}
+	if strings.Contains(record.AssetID, KEYSEPARATOR) {
+		return nil, errors.New("Asset id contains an invalid character")
+	}
+	if record.KeyID == "" {
+		return nil, errors.New("Key id is mandatory in the input JSON data")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(record.Ciphertext)
+	if err != nil || len(ciphertext) == 0 {
+		return nil, errors.New("Ciphertext is mandatory and must be base64 encoded")
+	}
+	if _, err = base64.StdEncoding.DecodeString(record.Nonce); err != nil {
+		return nil, errors.New("Nonce must be base64 encoded")
+	}
+	assetBytes, err := stub.GetState(record.AssetID)
+	if err != nil || len(assetBytes) == 0 {
+		return nil, errors.New("Asset does not exist: " + record.AssetID)
+	}
+	if record.Organization == "" {
+		record.Organization = callerOrganization(stub)
+	}
+	oldRecord, err := t.getConfidentialRecord(stub, record.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	// the holder of the key may replace the ciphertext, anything else is for admins
+	holder := oldRecord != nil && oldRecord.Organization != "" && oldRecord.Organization == callerOrganization(stub)
+	if !holder || oldRecord.KeyID != record.KeyID || oldRecord.Organization != record.Organization {
+		err = t.requireRole(stub, ROLEADMIN)
+		if err != nil {
+			return nil, errors.New("Only an admin may set a new confidential key or holder: " + fmt.Sprint(err))
+		}
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	record.Updated = formatTime(now)
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return nil, errors.New("Marshal failed for confidential record" + fmt.Sprint(err))
+	}
+	err = stub.PutState(compositeKey(CONFIDENTIALKEYPREFIX, record.AssetID), recordJSON)
+	if err != nil {
+		return nil, errors.New("PUT ledger state failed for confidential record: " + fmt.Sprint(err))
+	}
+	return nil, nil
+}
+
+/*********************************  internal: confidential fields ****************************/
+
+// readConfidentialRecord - the encrypted confidential fields of an asset for a caller of the
+// organisation holding the key or an admin, nil for other callers or when none are set
+func (t *SimpleChaincode) readConfidentialRecord(stub shim.ChaincodeStubInterface, assetID string) (*ConfidentialRecord, error) {
+	record, err := t.getConfidentialRecord(stub, assetID)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	org := callerOrganization(stub)
+	if (org == "" || org != record.Organization) && t.requireRole(stub, ROLEADMIN) != nil {
+		return nil, nil
+	}
+	return record, nil
+}
+
+// getConfidentialRecord - the encrypted confidential fields of an asset, nil when none are set
+func (t *SimpleChaincode) getConfidentialRecord(stub shim.ChaincodeStubInterface, assetID string) (*ConfidentialRecord, error) {
+	var record ConfidentialRecord
+	recordBytes, err := stub.GetState(compositeKey(CONFIDENTIALKEYPREFIX, assetID))
+	if err != nil || len(recordBytes) == 0 {
+		return nil, nil
+	}
+	err = json.Unmarshal(recordBytes, &record)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal confidential record obtained from ledger")
+	}
+	return &record, nil
+}
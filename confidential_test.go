@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+func TestConfidentialFields(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+	stub.as(ROLEADMIN, "Main Street Properties")
+	stub.mustInvoke(t, "setConfidentialFields", `{"assetID":"E1","keyID":"k1","nonce":"3q2+7wAAAAAAAAAA","ciphertext":"c2VhbGVk","organization":"Acme"}`)
+
+	// the organisation holding the key and admins get the record, others do not
+	stub.as("", "Acme")
+	record := stub.readAsset(t, "E1").Confidential
+	if record == nil || record.KeyID != "k1" || record.Ciphertext != "c2VhbGVk" || record.Organization != "Acme" || record.Updated != "2016-09-01T12:00:00Z" {
+		t.Fatalf("unexpected confidential record %+v", record)
+	}
+	stub.as("", "Summit")
+	if record = stub.readAsset(t, "E1").Confidential; record != nil {
+		t.Fatalf("confidential record returned to another organisation %+v", record)
+	}
+	stub.as(ROLEADMIN, "")
+	if record = stub.readAsset(t, "E1").Confidential; record == nil {
+		t.Fatal("confidential record not returned to an admin")
+	}
+
+	// the holder replaces the ciphertext under the same key
+	stub.as("", "Acme")
+	stub.mustInvoke(t, "setConfidentialFields", `{"assetID":"E1","keyID":"k1","ciphertext":"bmV3"}`)
+	if record = stub.readAsset(t, "E1").Confidential; record.Ciphertext != "bmV3" || record.Nonce != "" {
+		t.Fatalf("unexpected confidential record %+v", record)
+	}
+	stub.mustFail(t, "setConfidentialFields", `{"assetID":"E1","keyID":"k2","ciphertext":"bmV3"}`, "Only an admin may set a new confidential key")
+	stub.mustFail(t, "setConfidentialFields", `{"assetID":"E1","keyID":"k1","ciphertext":"bmV3","organization":"Summit"}`, "Only an admin may set a new confidential key")
+
+	stub.mustInvoke(t, "deleteAsset", `{"assetID":"E1"}`)
+	if _, found := stub.State[compositeKey(CONFIDENTIALKEYPREFIX, "E1")]; found {
+		t.Fatal("confidential record left after deleteAsset")
+	}
+}
+
+func TestConfidentialFieldsRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.as("", "Acme")
+	stub.mustFail(t, "setConfidentialFields", `{"assetID":"E1","keyID":"k1","ciphertext":"c2VhbGVk"}`, "Asset does not exist")
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+	stub.mustFail(t, "setConfidentialFields", `{"assetID":"E1","keyID":"k1","ciphertext":"c2VhbGVk"}`, "Only an admin may set a new confidential key")
+	stub.as(ROLEADMIN, "")
+	stub.mustFail(t, "setConfidentialFields", `{"keyID":"k1","ciphertext":"c2VhbGVk"}`, "Asset id is mandatory")
+	stub.mustFail(t, "setConfidentialFields", `{"assetID":"E\u00001","keyID":"k1","ciphertext":"c2VhbGVk"}`, "Asset id contains an invalid character")
+	stub.mustFail(t, "setConfidentialFields", `{"assetID":"E1","ciphertext":"c2VhbGVk"}`, "Key id is mandatory")
+	stub.mustFail(t, "setConfidentialFields", `{"assetID":"E1","keyID":"k1"}`, "Ciphertext is mandatory")
+	stub.mustFail(t, "setConfidentialFields", `{"assetID":"E1","keyID":"k1","ciphertext":"not base64!"}`, "must be base64 encoded")
+	stub.mustFail(t, "setConfidentialFields", `{"assetID":"E1","keyID":"k1","ciphertext":"c2VhbGVk","nonce":"%%"}`, "Nonce must be base64 encoded")
+	stub.mustFail(t, "setConfidentialFields", `{"assetID":"E1","fields":{"tenantTerms":"plain"}}`, "Key id is mandatory")
+}
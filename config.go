@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// validation modes, lenient ignores unknown fields of an asset state as the contract always did
+const (
+	VALIDATIONSTRICT  string = "strict"
+	VALIDATIONLENIENT string = "lenient"
+)
+
+// features that can be switched off in the contract configuration, all are on by default
+const (
+	FEATUREANOMALIES       string = "anomalyDetection" // baselines and anomaly records and events
+	FEATURETELEMETRY       string = "telemetry"        // hourly and daily telemetry buckets
+	FEATUREALARMWORKORDERS string = "alarmWorkOrders"  // work orders opened when an asset enters an alarm
+	FEATUREPROVENANCE      string = "provenance"       // field provenance of asset state writes
+)
+
+var contractFeatures = []string{FEATUREANOMALIES, FEATURETELEMETRY, FEATUREALARMWORKORDERS, FEATUREPROVENANCE}
+
+// asset state fields set by the contract rather than by callers, rejected as input in strict mode
+var computedFields = []string{"alarms", "anomalies", "deviceID"}
+
+// Units - units readings and limits are recorded in. The contract does not convert readings, but
+// the default maximum temperature follows the temperature unit.
+type Units struct {
+	Weight      string `json:"weight,omitempty"`      // lb, the default, or kg
+	Temperature string `json:"temperature,omitempty"` // F, the default, or C
+	Speed       string `json:"speed,omitempty"`       // fpm, feet per minute and the default, or mps
+}
+
+// DEFAULTHISTORYDAYS - days asset history is kept when its retention window is not set
+const DEFAULTHISTORYDAYS int = 365
+
+// Retention - days records are kept, records older than the window are removed when the asset is
+// next updated. Asset history is kept for DEFAULTHISTORYDAYS and other records forever when their
+// window is not set.
+type Retention struct {
+	HistoryDays   *int `json:"historyDays,omitempty"`   // asset history
+	TelemetryDays *int `json:"telemetryDays,omitempty"` // hourly and daily telemetry buckets
+	AnomalyDays   *int `json:"anomalyDays,omitempty"`   // anomaly records
+}
+
+//******************** updateContractConfig ********************/
+
+// updateContractConfig - admin only, changes the contract configuration set at init. Fields that are
+// not passed keep their value, features are merged with those already set.
+func (t *SimpleChaincode) updateContractConfig(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var update ContractState
+
+	err := t.requireRole(stub, ROLEADMIN)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with the configuration to change")
+	}
+	err = json.Unmarshal([]byte(args[0]), &update)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	state, err := t.getContractState(stub)
+	if err != nil {
+		return nil, err
+	}
+	if update.Version != "" && update.Version != state.Version {
+		return nil, errors.New("The version only changes with Init, expecting " + state.Version)
+	}
+	mergeContractConfig(&state, update)
+	return nil, t.putContractState(stub, state)
+}
+
+// ContractStatus - the contract state with counts read from the asset registry and alarm index
+type ContractStatus struct {
+	ContractState
+	Assets       int `json:"assets"`       // entries of the asset registry
+	ActiveAlarms int `json:"activeAlarms"` // alarms active on assets, an asset in two alarms counts twice
+}
+
+//******************** readContractState ********************/
+
+// readContractState - the version, configuration and last migration of the contract with the
+// number of assets and active alarms. See diagnostics for whether the counts can be trusted.
+func (t *SimpleChaincode) readContractState(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	state, err := t.getContractState(stub)
+	if err != nil {
+		return nil, err
+	}
+	status := ContractStatus{ContractState: state}
+	startKey, endKey := compositeRange(ASSETKEYPREFIX)
+	assetIDs, err := t.getIndexKeys(stub, startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	status.Assets = len(assetIDs)
+	startKey, endKey = compositeRange(ALARMINDEXKEYPREFIX)
+	alarms, err := t.getIndexKeys(stub, startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	status.ActiveAlarms = len(alarms)
+	return json.Marshal(status)
+}
+
+/*********************************  internal: contract configuration ****************************/
+
+// putContractState - validates and stores the contract state and configuration
+func (t *SimpleChaincode) putContractState(stub shim.ChaincodeStubInterface, state ContractState) error {
+	if state.AnomalyZScore != nil && *state.AnomalyZScore <= 0 {
+		return errors.New("anomalyZScore must be positive")
+	}
+	if state.ValidationMode != "" && !isOneOf(state.ValidationMode, VALIDATIONSTRICT, VALIDATIONLENIENT) {
+		return errors.New("validationMode must be strict or lenient")
+	}
+	if state.Units != nil && (!isOneOf(state.Units.Weight, "", "lb", "kg") ||
+		!isOneOf(state.Units.Temperature, "", "F", "C") || !isOneOf(state.Units.Speed, "", "fpm", "mps")) {
+		return errors.New("Units must be lb or kg, F or C, and fpm or mps")
+	}
+	if state.Retention != nil {
+		for _, days := range []*int{state.Retention.HistoryDays, state.Retention.TelemetryDays, state.Retention.AnomalyDays} {
+			if days != nil && *days <= 0 {
+				return errors.New("Retention windows must be positive")
+			}
+		}
+	}
+	for feature := range state.Features {
+		if !isOneOf(feature, contractFeatures...) {
+			return errors.New("Unknown feature " + feature + ", expecting one of " + strings.Join(contractFeatures, ", "))
+		}
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return err
+	}
+	state.Updated = formatTime(now)
+	contractStateJSON, err := json.Marshal(state)
+	if err != nil {
+		return errors.New("Marshal failed for contract state" + fmt.Sprint(err))
+	}
+	err = stub.PutState(CONTRACTSTATEKEY, contractStateJSON)
+	if err != nil {
+		return errors.New("Contract state failed PUT to ledger: " + fmt.Sprint(err))
+	}
+	return nil
+}
+
+// mergeContractConfig - sets the configuration passed in update on state. Fields that are not
+// passed keep their value, features are merged with those already set.
+func mergeContractConfig(state *ContractState, update ContractState) {
+	if update.Nickname != "" {
+		state.Nickname = update.Nickname
+	}
+	if update.AnomalyZScore != nil {
+		state.AnomalyZScore = update.AnomalyZScore
+	}
+	if update.Units != nil {
+		state.Units = update.Units
+	}
+	if update.Retention != nil {
+		state.Retention = update.Retention
+	}
+	if update.ValidationMode != "" {
+		state.ValidationMode = update.ValidationMode
+	}
+	for feature, enabled := range update.Features {
+		if state.Features == nil {
+			state.Features = map[string]bool{}
+		}
+		state.Features[feature] = enabled
+	}
+}
+
+// featureEnabled - true unless the configuration switched the feature off
+func featureEnabled(state ContractState, feature string) bool {
+	enabled, found := state.Features[feature]
+	return !found || enabled
+}
+
+// defaultMaxTemperature - MAXTEMPERATURE in the configured temperature unit
+func defaultMaxTemperature(units *Units) float64 {
+	if units != nil && units.Temperature == "C" {
+		return (MAXTEMPERATURE - 32) * 5 / 9
+	}
+	return MAXTEMPERATURE
+}
+
+// validateStrict - in strict mode, fails for fields of an incoming asset state the contract does
+// not know, at any depth, and for the fields it computes, which lenient mode silently ignores
+func validateStrict(state ContractState, input []byte) error {
+	var fields map[string]json.RawMessage
+	if state.ValidationMode != VALIDATIONSTRICT {
+		return nil
+	}
+	err := json.Unmarshal(input, &fields)
+	if err != nil {
+		return errors.New("Unable to unmarshal input JSON data")
+	}
+	for _, name := range computedFields {
+		if _, found := fields[name]; found {
+			return errors.New("Computed field " + name + " rejected in strict validation mode")
+		}
+	}
+	return validateKnownFields(reflect.TypeOf(AssetState{}), fields, "")
+}
+
+// validateKnownFields - fails for a field that is not in the JSON tags of the struct it decodes
+// into, checking nested objects against their own struct. Fields are checked in name order so
+// every peer reports the same one.
+func validateKnownFields(known reflect.Type, fields map[string]json.RawMessage, prefix string) error {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var fieldType reflect.Type
+		for i := 0; i < known.NumField() && fieldType == nil; i++ {
+			if strings.Split(known.Field(i).Tag.Get("json"), ",")[0] == name {
+				fieldType = known.Field(i).Type
+			}
+		}
+		if fieldType == nil {
+			return errors.New("Unknown field " + prefix + name + " rejected in strict validation mode")
+		}
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		var nested map[string]json.RawMessage
+		if fieldType.Kind() == reflect.Struct && json.Unmarshal(fields[name], &nested) == nil {
+			err := validateKnownFields(fieldType, nested, prefix+name+".")
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pruneRecords - removes the records of an asset older than the retention windows
+func (t *SimpleChaincode) pruneRecords(stub shim.ChaincodeStubInterface, state ContractState, assetID string) error {
+	retention := Retention{}
+	if state.Retention != nil {
+		retention = *state.Retention
+	}
+	historyDays := DEFAULTHISTORYDAYS
+	if retention.HistoryDays != nil {
+		historyDays = *retention.HistoryDays
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return err
+	}
+	prune := func(days *int, objectType string, attributes ...string) error {
+		if days == nil {
+			return nil
+		}
+		startKey, _ := compositeRange(objectType, attributes...)
+		endKey := compositeKey(objectType, append(attributes, timeKey(now.AddDate(0, 0, -*days)))...)
+		keys, err := t.getIndexKeys(stub, startKey, endKey)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			err = stub.DelState(key)
+			if err != nil {
+				return errors.New("DELSTATE failed for expired record! : " + fmt.Sprint(err))
+			}
+		}
+		return nil
+	}
+	err = prune(&historyDays, ASSETHISTORYKEYPREFIX, assetID)
+	if err != nil {
+		return err
+	}
+	err = prune(retention.TelemetryDays, TELEMETRYKEYPREFIX, assetID, GRANULARITYHOUR)
+	if err != nil {
+		return err
+	}
+	err = prune(retention.TelemetryDays, TELEMETRYKEYPREFIX, assetID, GRANULARITYDAY)
+	if err != nil {
+		return err
+	}
+	return prune(retention.AnomalyDays, ANOMALYKEYPREFIX, assetID)
+}
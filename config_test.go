@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestContractConfig(t *testing.T) {
+	var status ContractStatus
+	stub := newTestStub(t)
+	stub.mustInit(t, `{"version":"`+MYVERSION+`","nickname":"elevators","features":{"telemetry":false}}`)
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "updateContractConfig", `{"anomalyZScore":2.5,"features":{"provenance":false}}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+
+	// a repeated init keeps what its argument does not set
+	stub.mustInit(t, `{"version":"`+MYVERSION+`","units":{"weight":"kg"}}`)
+	stub.mustQuery(t, "readContractState", ``, &status)
+	if status.Nickname != "elevators" || status.AnomalyZScore == nil || *status.AnomalyZScore != 2.5 || status.Units == nil || status.Units.Weight != "kg" {
+		t.Fatalf("init did not merge the configuration %+v", status)
+	}
+	if featureEnabled(status.ContractState, FEATURETELEMETRY) || featureEnabled(status.ContractState, FEATUREPROVENANCE) || !featureEnabled(status.ContractState, FEATUREANOMALIES) {
+		t.Fatalf("unexpected features %+v", status.Features)
+	}
+	if status.LastMigration == nil || status.LastMigration.FromVersion != MYVERSION || status.LastMigration.TxID != "tx5" || status.Assets != 1 {
+		t.Fatalf("unexpected migration %+v", status)
+	}
+
+	stub.mustInit(t, `{"version":"`+MYVERSION+`","nickname":"lifts","features":{"telemetry":true}}`)
+	var renamed ContractStatus
+	stub.mustQuery(t, "readContractState", ``, &renamed)
+	if renamed.Nickname != "lifts" || !featureEnabled(renamed.ContractState, FEATURETELEMETRY) || featureEnabled(renamed.ContractState, FEATUREPROVENANCE) {
+		t.Fatalf("init did not override the configuration %+v", renamed)
+	}
+}
+
+func TestContractConfigRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFail(t, "createAsset", `{"assetID":"`+CONTRACTSTATEKEY+`"}`, "AssetID is reserved")
+	stub.mustFail(t, "updateAsset", `{"assetID":"`+CONTRACTSTATEKEY+`","temperature":50}`, "AssetID is reserved")
+	stub.mustFail(t, "deleteAsset", `{"assetID":"`+CONTRACTSTATEKEY+`"}`, "AssetID is reserved")
+	stub.mustFail(t, "updateContractConfig", `{"nickname":"lifts"}`, "not allowed")
+
+	stub.as(ROLEADMIN, "")
+	stub.mustFail(t, "updateContractConfig", `{"version":"0.9"}`, "The version only changes with Init")
+	stub.mustFail(t, "updateContractConfig", `{"anomalyZScore":0}`, "anomalyZScore must be positive")
+	stub.mustFail(t, "updateContractConfig", `{"validationMode":"loose"}`, "validationMode must be strict or lenient")
+	stub.mustFail(t, "updateContractConfig", `{"units":{"weight":"t"}}`, "Units must be")
+	stub.mustFail(t, "updateContractConfig", `{"retention":{"historyDays":0}}`, "Retention windows must be positive")
+	stub.mustFail(t, "updateContractConfig", `{"features":{"teleport":true}}`, "Unknown feature teleport")
+
+	_, err := stub.transact(func() ([]byte, error) {
+		return new(SimpleChaincode).Init(stub, "init", []string{`{"version":"0.9"}`})
+	})
+	if err == nil {
+		t.Fatal("init accepted another version")
+	}
+	var state ContractState
+	stub.mustQuery(t, "readContractState", ``, &state)
+	if state.Version != MYVERSION || state.Nickname != "" {
+		t.Fatalf("rejected changes were stored %+v", state)
+	}
+}
+
+func TestStrictValidation(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","alarms":["overheat"],"system":{"gpu":1}}`)
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "updateContractConfig", `{"validationMode":"strict"}`)
+	stub.mustFail(t, "updateAsset", `{"assetID":"E1","colour":"red"}`, "Unknown field colour")
+	stub.mustFail(t, "updateAsset", `{"assetID":"E1","system":{"cpu":20,"gpu":1}}`, "Unknown field system.gpu")
+	stub.mustFail(t, "updateAsset", `{"assetID":"E1","alarms":[]}`, "Computed field alarms")
+	stub.mustFail(t, "updateAsset", `{"assetID":"E1","anomalies":["temperature"]}`, "Computed field anomalies")
+	stub.mustFail(t, "updateAsset", `{"assetID":"E1","deviceID":"sensor-1"}`, "Computed field deviceID")
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","system":{"cpu":20},"temperature":70}`)
+}
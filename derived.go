@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// DerivedFields - values computed from an asset's state and records at read time, never stored.
+// A field is left out when what it is computed from is unknown.
+type DerivedFields struct {
+	LoadFactor    *float64 `json:"loadFactor,omitempty"`    // weight divided by the rated load, above 1 when overloaded
+	ThermalMargin *float64 `json:"thermalMargin,omitempty"` // degrees below the maximum temperature, negative when above
+	EnergyPerTrip *float64 `json:"energyPerTrip,omitempty"` // kWh metered per completed trip over the asset's lifetime
+}
+
+// AssetView - the stored state of an asset with the fields derived from it kept apart, and its
+// encrypted confidential fields when the caller's organisation holds their key
+type AssetView struct {
+	AssetState
+	Derived      DerivedFields       `json:"derived"`
+	Confidential *ConfidentialRecord `json:"confidential,omitempty"`
+}
+
+/*********************************  internal: derived fields ****************************/
+
+// getDerivedFields - computes the derived fields of an asset against the limits in effect
+// for it, see getAssetLimits, and its usage and energy records
+func (t *SimpleChaincode) getDerivedFields(stub shim.ChaincodeStubInterface, state AssetState) (DerivedFields, error) {
+	var derived DerivedFields
+	var usage AssetUsage
+	var meter EnergyMeter
+	assetID := *state.AssetID
+
+	limits, err := t.getAssetLimits(stub, assetID)
+	if err != nil {
+		return derived, err
+	}
+	if state.Weight != nil && limits.RatedLoad != nil {
+		loadFactor := *state.Weight / *limits.RatedLoad
+		derived.LoadFactor = &loadFactor
+	}
+	if state.Temperature != nil && limits.MaxTemperature != nil {
+		thermalMargin := *limits.MaxTemperature - *state.Temperature
+		derived.ThermalMargin = &thermalMargin
+	}
+
+	usageBytes, err := stub.GetState(compositeKey(USAGEKEYPREFIX, assetID))
+	if err != nil || len(usageBytes) == 0 {
+		return derived, nil
+	}
+	err = json.Unmarshal(usageBytes, &usage)
+	if err != nil {
+		return derived, errors.New("Unable to unmarshal usage data obtained from ledger")
+	}
+	meterBytes, err := stub.GetState(compositeKey(METERKEYPREFIX, assetID))
+	if err != nil || len(meterBytes) == 0 || usage.TotalTrips == 0 {
+		return derived, nil
+	}
+	err = json.Unmarshal(meterBytes, &meter)
+	if err != nil {
+		return derived, errors.New("Unable to unmarshal meter data obtained from ledger")
+	}
+	energyPerTrip := meter.TotalKwh / float64(usage.TotalTrips)
+	derived.EnergyPerTrip = &energyPerTrip
+	return derived, nil
+}
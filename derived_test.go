@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDerivedFields(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","weight":400,"temperature":70,"direction":"stopped","energyMeter":100}`)
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "setNameplate", `{"assetID":"E1","ratedLoad":800}`)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","direction":"up"}`)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","direction":"stopped","energyMeter":110}`)
+
+	derived := stub.readAsset(t, "E1").Derived
+	if *derived.LoadFactor != 0.5 || *derived.ThermalMargin != MAXTEMPERATURE-70 || *derived.EnergyPerTrip != 10 {
+		t.Fatalf("unexpected derived fields %+v", derived)
+	}
+	// derived fields are never stored
+	if strings.Contains(string(stub.State["E1"]), "derived") || strings.Contains(string(stub.State["E1"]), "loadFactor") {
+		t.Fatalf("derived fields stored %s", stub.State["E1"])
+	}
+	// unknown inputs leave their fields out
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E2"}`)
+	if derived = stub.readAsset(t, "E2").Derived; derived.LoadFactor != nil || derived.ThermalMargin != nil || derived.EnergyPerTrip != nil {
+		t.Fatalf("unexpected derived fields %+v", derived)
+	}
+}
+
+func TestDerivedFieldsRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "updateContractConfig", `{"validationMode":"strict"}`)
+	stub.mustFail(t, "createAsset", `{"assetID":"E1","derived":{"loadFactor":0.1}}`, "Unknown field derived")
+	stub.mustFailQuery(t, "readAsset", `{"assetID":"E1"}`, "")
+}
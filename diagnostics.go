@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// DIAGNOSTICSMAXDETAILS - problems described per check, the rest are only counted
+const DIAGNOSTICSMAXDETAILS int = 100
+
+// DiagnosticCheck - result of one consistency check
+type DiagnosticCheck struct {
+	Name     string   `json:"name"`              // contract, registry, usage or the name of a secondary index
+	Checked  int      `json:"checked"`           // records and entries checked
+	Problems int      `json:"problems"`          // inconsistencies found
+	Details  []string `json:"details,omitempty"` // the first inconsistencies found
+}
+
+// Diagnostics - result of the consistency checks, rebuildIndexes repairs the registry and indexes
+type Diagnostics struct {
+	Version    string            `json:"version"`    // version of the running chaincode
+	Consistent bool              `json:"consistent"` // no check found a problem
+	Assets     int               `json:"assets"`     // assets listed in the registry
+	Checks     []DiagnosticCheck `json:"checks"`
+}
+
+//******************** diagnostics ********************/
+
+// diagnostics - checks the stored contract state, the asset registry, the secondary indexes and
+// the usage counters against the registered assets, to verify a peer after a restart or upgrade.
+// An asset whose registry entry was lost shows as stale index entries and usage counters.
+func (t *SimpleChaincode) diagnostics(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	result := Diagnostics{Version: MYVERSION, Consistent: true}
+	assets, err := t.getRegisteredAssets(stub)
+	if err != nil {
+		return nil, err
+	}
+	result.Assets = len(assets)
+
+	checks := []func(shim.ChaincodeStubInterface, []AssetState) ([]DiagnosticCheck, error){
+		t.checkContractState, t.checkIndexes, t.checkUsage,
+	}
+	for _, check := range checks {
+		found, err := check(stub, assets)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range found {
+			if c.Problems > 0 {
+				result.Consistent = false
+			}
+			result.Checks = append(result.Checks, c)
+		}
+	}
+	return json.Marshal(result)
+}
+
+/*********************************  internal: diagnostics ****************************/
+
+// problem - counts an inconsistency and describes it while there are few
+func (c *DiagnosticCheck) problem(detail string) {
+	c.Problems++
+	if len(c.Details) < DIAGNOSTICSMAXDETAILS {
+		c.Details = append(c.Details, detail)
+	}
+}
+
+// checkContractState - the stored contract state is readable and of the running version
+func (t *SimpleChaincode) checkContractState(stub shim.ChaincodeStubInterface, assets []AssetState) ([]DiagnosticCheck, error) {
+	var state ContractState
+	check := DiagnosticCheck{Name: "contract", Checked: 1}
+	stateBytes, err := stub.GetState(CONTRACTSTATEKEY)
+	if err != nil || len(stateBytes) == 0 {
+		check.problem("No contract state stored, init has not run")
+	} else if json.Unmarshal(stateBytes, &state) != nil {
+		check.problem("Unable to unmarshal contract state obtained from ledger")
+	} else if state.Version != MYVERSION {
+		check.problem("Stored version " + state.Version + " differs from chaincode version " + MYVERSION + ", init has not run since the upgrade")
+	}
+	return []DiagnosticCheck{check}, nil
+}
+
+// checkIndexes - the asset registry and every secondary index hold exactly the entries the
+// registered assets are indexed under
+func (t *SimpleChaincode) checkIndexes(stub shim.ChaincodeStubInterface, assets []AssetState) ([]DiagnosticCheck, error) {
+	var checks []DiagnosticCheck
+	expected := map[string]map[string]bool{ASSETKEYPREFIX: {}}
+	for _, prefix := range assetIndexes {
+		expected[prefix] = map[string]bool{}
+	}
+	for _, state := range assets {
+		assetID := *state.AssetID
+		expected[ASSETKEYPREFIX][compositeKey(ASSETKEYPREFIX, assetID)] = true
+		values, err := t.assetIndexValues(stub, state)
+		if err != nil {
+			return nil, err
+		}
+		for index, indexValues := range values {
+			for _, value := range indexValues {
+				expected[assetIndexes[index]][compositeKey(assetIndexes[index], value, assetID)] = true
+			}
+		}
+	}
+
+	names := map[string]string{"registry": ASSETKEYPREFIX}
+	order := []string{"registry"}
+	for index, prefix := range assetIndexes {
+		names[index] = prefix
+		order = append(order, index)
+	}
+	sort.Strings(order[1:])
+	for _, name := range order {
+		prefix := names[name]
+		check := DiagnosticCheck{Name: name}
+		startKey, endKey := compositeRange(prefix)
+		keys, err := t.getIndexKeys(stub, startKey, endKey)
+		if err != nil {
+			return nil, err
+		}
+		found := map[string]bool{}
+		for _, key := range keys {
+			found[key] = true
+			check.Checked++
+			if !expected[prefix][key] {
+				check.problem("Stale entry " + readableKey(key))
+			}
+		}
+		missing := []string{}
+		for key := range expected[prefix] {
+			if !found[key] {
+				missing = append(missing, key)
+			}
+		}
+		sort.Strings(missing)
+		for _, key := range missing {
+			check.problem("Missing entry " + readableKey(key))
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// checkUsage - usage counters belong to the asset they are stored for and none are negative, and
+// no counters are left of deleted assets. An asset without counters, such as one created before
+// they were kept, is not tracked yet and starts counting on its next update.
+func (t *SimpleChaincode) checkUsage(stub shim.ChaincodeStubInterface, assets []AssetState) ([]DiagnosticCheck, error) {
+	check := DiagnosticCheck{Name: "usage"}
+	known := map[string]bool{}
+	for _, state := range assets {
+		known[*state.AssetID] = true
+	}
+	startKey, endKey := compositeRange(USAGEKEYPREFIX)
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return nil, errors.New("Unable to read usage counters from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		var usage AssetUsage
+		key, usageBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read usage counters from ledger: " + fmt.Sprint(err))
+		}
+		check.Checked++
+		assetID := strings.TrimPrefix(key, startKey)
+		if !known[assetID] {
+			check.problem("Usage counters of missing asset " + assetID)
+			continue
+		}
+		if json.Unmarshal(usageBytes, &usage) != nil {
+			check.problem("Unable to unmarshal usage counters of asset " + assetID)
+			continue
+		}
+		if usage.AssetID != assetID {
+			check.problem("Usage counters of asset " + assetID + " name asset " + usage.AssetID)
+		}
+		if usage.TotalTrips < 0 || usage.FloorsTravelled < 0 || usage.DoorCycles < 0 || usage.RunningHours < 0 {
+			check.problem("Negative usage counter for asset " + assetID)
+		}
+		if _, err := parseTime(usage.LastUpdated); err != nil {
+			check.problem("Invalid lastUpdated in usage counters of asset " + assetID)
+		}
+	}
+	return []DiagnosticCheck{check}, nil
+}
+
+// readableKey - a composite key with its parts separated by slashes
+func readableKey(key string) string {
+	return strings.Replace(key, KEYSEPARATOR, "/", -1)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// checkProblems - the problems the named diagnostic check reported
+func checkProblems(t *testing.T, result Diagnostics, name string) []string {
+	for _, check := range result.Checks {
+		if check.Name == name {
+			return check.Details
+		}
+	}
+	t.Fatalf("no %s check in %+v", name, result)
+	return nil
+}
+
+func TestDiagnostics(t *testing.T) {
+	var result Diagnostics
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","building":"B1"}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E2","building":"B1"}`)
+	stub.mustQuery(t, "diagnostics", ``, &result)
+	if !result.Consistent || result.Assets != 2 || result.Version != MYVERSION {
+		t.Fatalf("unexpected diagnostics %+v", result)
+	}
+
+	// an asset created before usage counters were kept is not tracked yet
+	stub.transact(func() ([]byte, error) {
+		return nil, stub.DelState(compositeKey(USAGEKEYPREFIX, "E1"))
+	})
+	var untracked Diagnostics
+	stub.mustQuery(t, "diagnostics", ``, &untracked)
+	if !untracked.Consistent {
+		t.Fatalf("missing usage counters reported %+v", untracked)
+	}
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","floor":3}`)
+	var usage AssetUsage
+	stub.mustQuery(t, "readAssetUsage", `{"assetID":"E1"}`, &usage)
+	if usage.AssetID != "E1" {
+		t.Fatalf("usage counters not started %+v", usage)
+	}
+
+	// a lost registry entry leaves the index entries of the asset stale until its next update
+	stub.transact(func() ([]byte, error) {
+		return nil, stub.DelState(compositeKey(ASSETKEYPREFIX, "E2"))
+	})
+	var lost Diagnostics
+	stub.mustQuery(t, "diagnostics", ``, &lost)
+	if lost.Consistent || lost.Assets != 1 || fmt.Sprint(checkProblems(t, lost, "building")) != "[Stale entry BUILDING/B1/E2]" {
+		t.Fatalf("lost registry entry not reported %+v", lost)
+	}
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E2","floor":1}`)
+	var listed Diagnostics
+	stub.mustQuery(t, "diagnostics", ``, &listed)
+	if !listed.Consistent || listed.Assets != 2 {
+		t.Fatalf("update did not list the asset again %+v", listed)
+	}
+
+	// rebuildIndexes drops a registry entry of an asset that is gone
+	stub.transact(func() ([]byte, error) {
+		return nil, stub.PutState(compositeKey(ASSETKEYPREFIX, "E9"), []byte("E9"))
+	})
+	var broken Diagnostics
+	stub.mustQuery(t, "diagnostics", ``, &broken)
+	if broken.Consistent || len(checkProblems(t, broken, "registry")) != 1 {
+		t.Fatalf("stale registry entry not reported %+v", broken)
+	}
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "rebuildIndexes", ``)
+	var repaired Diagnostics
+	stub.mustQuery(t, "diagnostics", ``, &repaired)
+	if !repaired.Consistent {
+		t.Fatalf("rebuildIndexes did not repair %+v", repaired)
+	}
+}
+
+func TestDiagnosticsProblems(t *testing.T) {
+	var result Diagnostics
+	stub := newTestStub(t)
+	stub.mustFail(t, "rebuildIndexes", ``, "not allowed")
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+	stub.transact(func() ([]byte, error) {
+		err := stub.PutState(compositeKey(USAGEKEYPREFIX, "E9"), []byte(`{"assetID":"E9"}`))
+		if err != nil {
+			return nil, err
+		}
+		err = stub.PutState(compositeKey(USAGEKEYPREFIX, "E1"), []byte(`{"assetID":"E1","totalTrips":-1,"lastUpdated":"2016-09-01T12:00:00Z"}`))
+		if err != nil {
+			return nil, err
+		}
+		return nil, stub.DelState(CONTRACTSTATEKEY)
+	})
+	stub.mustQuery(t, "diagnostics", ``, &result)
+	if result.Consistent {
+		t.Fatalf("problems not reported %+v", result)
+	}
+	usage := strings.Join(checkProblems(t, result, "usage"), "\n")
+	if !strings.Contains(usage, "Negative usage counter for asset E1") || !strings.Contains(usage, "Usage counters of missing asset E9") {
+		t.Fatalf("unexpected usage problems %s", usage)
+	}
+	if contract := checkProblems(t, result, "contract"); len(contract) != 1 || !strings.Contains(contract[0], "No contract state stored") {
+		t.Fatalf("unexpected contract problems %v", contract)
+	}
+}
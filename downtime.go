@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// OUTAGEKEYPREFIX - object type for out of service intervals, keyed by assetID, start time and reason
+const OUTAGEKEYPREFIX string = "OUTAGE"
+
+// OUTAGEWORKORDER - reason prefix of outages opened by a work order, followed by the workOrderID
+const OUTAGEWORKORDER string = "workOrder:"
+
+// Outage - an interval during which an asset was out of service, End is empty while it lasts.
+// Outages of an asset may overlap, for example a work order during the out of service mode.
+type Outage struct {
+	AssetID string `json:"assetID"`
+	Start   string `json:"start"`
+	End     string `json:"end,omitempty"`
+	Reason  string `json:"reason"` // outOfService for the operating mode, or workOrder:<workOrderID>
+}
+
+// Availability - uptime KPIs of an asset, or of several assets summed, over a period
+type Availability struct {
+	AssetID       string   `json:"assetID,omitempty"`
+	UptimePercent float64  `json:"uptimePercent"`
+	UptimeHours   float64  `json:"uptimeHours"`
+	DowntimeHours float64  `json:"downtimeHours"`
+	Failures      int      `json:"failures"`            // out of service intervals starting in the period
+	MTBFHours     *float64 `json:"mtbfHours,omitempty"` // mean time between failures, set when there were failures
+	MTTRHours     *float64 `json:"mttrHours,omitempty"` // mean time to repair, set when there were failures
+}
+
+// AvailabilityReport - availability of an asset or a building, with each asset of a building listed
+type AvailabilityReport struct {
+	Availability
+	Building string         `json:"building,omitempty"`
+	From     string         `json:"from"`
+	To       string         `json:"to"`
+	Assets   []Availability `json:"assets,omitempty"`
+}
+
+//******************** readAvailabilityReport ********************/
+
+// readAvailabilityReport - uptime percentage, MTBF and MTTR of an asset or a building over
+// [from, to). The period ends at the transaction time, or earlier when to is passed.
+func (t *SimpleChaincode) readAvailabilityReport(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var assetIDs []string
+	query, err := t.validateSLAQuery(args)
+	if err != nil {
+		return nil, err
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := reportPeriod(query.From, query.To, now)
+	if err != nil {
+		return nil, err
+	}
+	if to.After(now) {
+		to = now
+		if !to.After(from) {
+			return nil, errors.New("Period must start before the transaction time")
+		}
+	}
+	if query.AssetID != "" {
+		assetIDs = []string{query.AssetID}
+	} else {
+		assetIDs, err = t.getBuildingAssets(stub, query.Building)
+		if err != nil {
+			return nil, err
+		}
+	}
+	report := AvailabilityReport{Building: query.Building, From: formatTime(from), To: formatTime(to)}
+	var uptime, downtime time.Duration
+	for _, assetID := range assetIDs {
+		outages, err := t.getOutages(stub, assetID)
+		if err != nil {
+			return nil, err
+		}
+		intervals := downtimeIntervals(outages, from, to, now)
+		var down time.Duration
+		failures := 0
+		for _, interval := range intervals {
+			down += interval[1].Sub(interval[0])
+			if !interval[0].Equal(from) {
+				failures++
+			}
+		}
+		uptime += to.Sub(from) - down
+		downtime += down
+		report.Failures += failures
+		if query.Building != "" {
+			report.Assets = append(report.Assets, availability(assetID, to.Sub(from)-down, down, failures))
+		}
+	}
+	report.Availability = availability(query.AssetID, uptime, downtime, report.Failures)
+	return json.Marshal(report)
+}
+
+/*********************************  internal: outages ****************************/
+
+// updateOutages - opens an outage when an asset enters the out of service mode and closes it when it leaves
+func (t *SimpleChaincode) updateOutages(stub shim.ChaincodeStubInterface, oldState *AssetState, newState AssetState) error {
+	wasDown := oldState != nil && isOutOfService(*oldState)
+	isDown := isOutOfService(newState)
+	if wasDown == isDown {
+		return nil
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return err
+	}
+	if isDown {
+		return t.putOutage(stub, Outage{AssetID: *newState.AssetID, Start: formatTime(now), Reason: MODEOUTOFSERVICE})
+	}
+	return t.closeOutages(stub, *newState.AssetID, MODEOUTOFSERVICE, now)
+}
+
+// closeOutages - ends the open outages of an asset with the given reason, or all of them when reason is empty
+func (t *SimpleChaincode) closeOutages(stub shim.ChaincodeStubInterface, assetID string, reason string, now time.Time) error {
+	outages, err := t.getOutages(stub, assetID)
+	if err != nil {
+		return err
+	}
+	for _, outage := range outages {
+		if outage.End != "" || (reason != "" && outage.Reason != reason) {
+			continue
+		}
+		outage.End = formatTime(now)
+		err = t.putOutage(stub, outage)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isOutOfService - true when the state reports the out of service mode
+func isOutOfService(state AssetState) bool {
+	return state.OperatingMode != nil && *state.OperatingMode == MODEOUTOFSERVICE
+}
+
+// downtimeIntervals - the outages clipped to [from, to) and merged where they overlap, in time order.
+// Open outages last until now.
+func downtimeIntervals(outages []Outage, from time.Time, to time.Time, now time.Time) [][2]time.Time {
+	var intervals [][2]time.Time
+	for _, outage := range outages {
+		start, err := parseTime(outage.Start)
+		if err != nil {
+			continue
+		}
+		end := now
+		if outage.End != "" {
+			end, err = parseTime(outage.End)
+			if err != nil {
+				continue
+			}
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			intervals = append(intervals, [2]time.Time{start, end})
+		}
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i][0].Before(intervals[j][0]) })
+	var merged [][2]time.Time
+	for _, interval := range intervals {
+		last := len(merged) - 1
+		if last >= 0 && !interval[0].After(merged[last][1]) {
+			if interval[1].After(merged[last][1]) {
+				merged[last][1] = interval[1]
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+// downtimeIn - total time the asset was out of service in [from, to)
+func downtimeIn(outages []Outage, from time.Time, to time.Time, now time.Time) time.Duration {
+	var downtime time.Duration
+	for _, interval := range downtimeIntervals(outages, from, to, now) {
+		downtime += interval[1].Sub(interval[0])
+	}
+	return downtime
+}
+
+// availability - KPIs from up and down time, MTBF and MTTR are per failure
+func availability(assetID string, uptime time.Duration, downtime time.Duration, failures int) Availability {
+	result := Availability{
+		AssetID:       assetID,
+		UptimeHours:   uptime.Hours(),
+		DowntimeHours: downtime.Hours(),
+		Failures:      failures,
+	}
+	if uptime+downtime > 0 {
+		result.UptimePercent = 100 * uptime.Hours() / (uptime + downtime).Hours()
+	}
+	if failures > 0 {
+		mtbf := uptime.Hours() / float64(failures)
+		mttr := downtime.Hours() / float64(failures)
+		result.MTBFHours = &mtbf
+		result.MTTRHours = &mttr
+	}
+	return result
+}
+
+// getOutages - all outages of an asset, oldest first
+func (t *SimpleChaincode) getOutages(stub shim.ChaincodeStubInterface, assetID string) ([]Outage, error) {
+	var outages []Outage
+	startKey, endKey := compositeRange(OUTAGEKEYPREFIX, assetID)
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return nil, errors.New("Unable to read outages from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		var outage Outage
+		_, outageBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read outages from ledger: " + fmt.Sprint(err))
+		}
+		err = json.Unmarshal(outageBytes, &outage)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal outage data obtained from ledger")
+		}
+		outages = append(outages, outage)
+	}
+	return outages, nil
+}
+
+func (t *SimpleChaincode) putOutage(stub shim.ChaincodeStubInterface, outage Outage) error {
+	start, err := parseTime(outage.Start)
+	if err != nil {
+		return errors.New("Invalid outage start: " + outage.Start)
+	}
+	if strings.Contains(outage.Reason, KEYSEPARATOR) {
+		return errors.New("Outage reason contains an invalid character")
+	}
+	outageJSON, err := json.Marshal(outage)
+	if err != nil {
+		return errors.New("Marshal failed for outage" + fmt.Sprint(err))
+	}
+	err = stub.PutState(compositeKey(OUTAGEKEYPREFIX, outage.AssetID, timeKey(start), outage.Reason), outageJSON)
+	if err != nil {
+		return errors.New("PUT ledger state failed for outage: " + fmt.Sprint(err))
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAvailabilityReport(t *testing.T) {
+	var report, building, open AvailabilityReport
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","building":"B1","operatingMode":"normal"}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E2","building":"B1","operatingMode":"normal"}`)
+	stub.now = stub.now.Add(2 * time.Hour)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","operatingMode":"outOfService"}`)
+	stub.now = stub.now.Add(2 * time.Hour)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","operatingMode":"normal"}`)
+	stub.now = stub.now.Add(4 * time.Hour)
+
+	stub.mustQuery(t, "readAvailabilityReport", `{"assetID":"E1","from":"2016-09-01T12:00:00Z"}`, &report)
+	if report.To != "2016-09-01T20:00:00Z" || report.UptimeHours != 6 || report.DowntimeHours != 2 || report.UptimePercent != 75 || report.Failures != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if *report.MTBFHours != 6 || *report.MTTRHours != 2 {
+		t.Fatalf("unexpected MTBF %v or MTTR %v", *report.MTBFHours, *report.MTTRHours)
+	}
+	stub.mustQuery(t, "readAvailabilityReport", `{"building":"B1","from":"2016-09-01T12:00:00Z"}`, &building)
+	if len(building.Assets) != 2 || building.UptimeHours != 14 || building.DowntimeHours != 2 || building.MTBFHours == nil {
+		t.Fatalf("unexpected building report %+v", building)
+	}
+
+	// an open outage lasts until the transaction time, and one already running at the start is no failure
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E2","operatingMode":"outOfService"}`)
+	stub.now = stub.now.Add(time.Hour)
+	stub.mustQuery(t, "readAvailabilityReport", `{"assetID":"E2","from":"2016-09-01T20:30:00Z"}`, &open)
+	if open.DowntimeHours != 0.5 || open.UptimePercent != 0 || open.Failures != 0 || open.MTBFHours != nil {
+		t.Fatalf("unexpected report %+v", open)
+	}
+}
+
+func TestAvailabilityReportRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFailQuery(t, "readAvailabilityReport", `{"from":"2016-09-01"}`, "Expecting either an assetID or a building")
+	stub.mustFailQuery(t, "readAvailabilityReport", `{"assetID":"E1","building":"B1","from":"2016-09-01"}`, "Expecting either an assetID or a building")
+	stub.mustFailQuery(t, "readAvailabilityReport", `{"assetID":"E1"}`, "Invalid or missing from")
+	stub.mustFailQuery(t, "readAvailabilityReport", `{"assetID":"E1","from":"2016-09-01","to":"2016-08-01"}`, "Period end must be after its start")
+	stub.mustFailQuery(t, "readAvailabilityReport", `{"assetID":"E1","from":"2016-09-05","to":"2016-09-06"}`, "Period must start before the transaction time")
+}
@@ -29,8 +29,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 )
@@ -39,19 +42,84 @@ import (
 type SimpleChaincode struct {
 }
 
-// CONTRACTSTATEKEY  - Store contract state key
+// CONTRACTSTATEKEY  - Store contract state key, reserved and not allowed as an assetID
 const CONTRACTSTATEKEY string = "ContractStateKey"
 
 // MYVERSION Store contract state. Only version in this example
 const MYVERSION string = "1.0"
 
+// KEYSEPARATOR separates the parts of composite ledger keys, not allowed in an assetID
+const KEYSEPARATOR string = "\x00"
+
+// elevator travel directions
+const (
+	DIRECTIONUP      string = "up"
+	DIRECTIONDOWN    string = "down"
+	DIRECTIONSTOPPED string = "stopped"
+)
+
+// elevator door states
+const (
+	DOOROPEN       string = "open"
+	DOORCLOSING    string = "closing"
+	DOORCLOSED     string = "closed"
+	DOOROBSTRUCTED string = "obstructed"
+)
+
+// elevator operating modes
+const (
+	MODENORMAL       string = "normal"
+	MODEINSPECTION   string = "inspection"
+	MODEFIRESERVICE  string = "fireService"
+	MODEOUTOFSERVICE string = "outOfService"
+)
+
+// alarms raised on an asset by createOrUpdateAsset
+const (
+	ALARMTEMPERATURE string = "temperature" // temperature outside the operating range
+	ALARMOVERLOAD    string = "overload"    // weight above the rated load
+	ALARMOVERSPEED   string = "overspeed"   // speed above the rated speed
+)
+
+// ROLEATTRIBUTE - certificate attribute holding the role of the caller
+const ROLEATTRIBUTE string = "role"
+
+// ORGATTRIBUTE - certificate attribute holding the organisation of the caller
+const ORGATTRIBUTE string = "organization"
+
+// caller roles
+const (
+	ROLEADMIN     string = "admin"
+	ROLEINSPECTOR string = "inspector" // records safety inspections
+)
+
+// MAXTEMPERATURE temperature in Fahrenheit above which an asset is in alarm, unless its model or nameplate set another
+const MAXTEMPERATURE float64 = 104
+
 // ************************************
 // asset and contract state
 // ************************************
 
-// ContractState - structure to store contract state (version)
+// ContractState - structure to store contract state (version) and the configuration set at init,
+// see updateContractConfig
 type ContractState struct {
-	Version string `json:"version"`
+	Version        string          `json:"version"`
+	Nickname       string          `json:"nickname,omitempty"`       // name the contract was deployed under
+	AnomalyZScore  *float64        `json:"anomalyZScore,omitempty"`  // z-score above which a reading is anomalous
+	Units          *Units          `json:"units,omitempty"`          // units readings and limits are recorded in
+	Retention      *Retention      `json:"retention,omitempty"`      // days records are kept, forever when not set
+	ValidationMode string          `json:"validationMode,omitempty"` // strict or lenient, the default
+	Features       map[string]bool `json:"features,omitempty"`       // features switched on or off, all are on unless set false
+	Updated        string          `json:"updated,omitempty"`        // time the configuration was last changed
+	LastMigration  *Migration      `json:"lastMigration,omitempty"`  // the last run of init, set by the contract
+}
+
+// Migration - a run of init, on deployment or upgrade
+type Migration struct {
+	FromVersion string `json:"fromVersion,omitempty"` // version stored before, empty on first deployment
+	ToVersion   string `json:"toVersion"`
+	TxID        string `json:"txID"`
+	Timestamp   string `json:"timestamp"`
 }
 
 // System - structure to store device system details
@@ -62,18 +130,40 @@ type System struct {
 
 // AssetState - structure to store asset details
 type AssetState struct {
-	AssetID     *string  `json:"assetID,omitempty"`     // all assets must have an ID, primary key of contract
-	Weight      *float64 `json:"weight,omitempty"`      // asset weight
-	System      *System  `json:"system,omitempty"`      // current system usage
-	Temperature *float64 `json:"temperature,omitempty"` // asset temperature
-	Speed       *float64 `json:"speed,omitempty"`       // asset speed
-	Power       *float64 `json:"power,omitempty"`       // asset power consumption
+	AssetID       *string  `json:"assetID,omitempty"`       // all assets must have an ID, primary key of contract
+	Building      *string  `json:"building,omitempty"`      // building the elevator is installed in
+	Owner         *string  `json:"owner,omitempty"`         // organisation owning the elevator, only admins change it once set
+	Provider      *string  `json:"provider,omitempty"`      // organisation maintaining the elevator, changed by acceptTransfer once set
+	Weight        *float64 `json:"weight,omitempty"`        // asset weight
+	System        *System  `json:"system,omitempty"`        // current system usage
+	Temperature   *float64 `json:"temperature,omitempty"`   // asset temperature
+	Speed         *float64 `json:"speed,omitempty"`         // asset speed
+	Power         *float64 `json:"power,omitempty"`         // asset power consumption
+	EnergyMeter   *float64 `json:"energyMeter,omitempty"`   // cumulative energy meter reading in kWh
+	Floor         *int     `json:"floor,omitempty"`         // floor the car is currently at or passing
+	Direction     *string  `json:"direction,omitempty"`     // travel direction: up, down or stopped
+	DoorStatus    *string  `json:"doorStatus,omitempty"`    // door state: open, closing, closed or obstructed
+	OperatingMode *string  `json:"operatingMode,omitempty"` // operating mode: normal, inspection, fireService or outOfService
+	Alarms        []string `json:"alarms,omitempty"`        // active alarms, computed by the contract on every update
+	Anomalies     []string `json:"anomalies,omitempty"`     // fields whose latest reading is anomalous, computed by the contract
+	DeviceID      *string  `json:"deviceID,omitempty"`      // device an update came from, recorded in the field provenance and not stored
 }
 
-var contractState = ContractState{MYVERSION}
+var contractState = ContractState{Version: MYVERSION}
 
 // Init - contract initialization
 func (t *SimpleChaincode) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	// migrations under an approval policy only run through executeOperation
+	err := t.requireNoApprovalPolicy(stub, OPINIT)
+	if err != nil {
+		return nil, err
+	}
+	return t.initContract(stub, args)
+}
+
+// initContract - validates and stores the contract state passed to Init, merged with the
+// configuration already stored
+func (t *SimpleChaincode) initContract(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	var stateArg ContractState
 	var err error
 	if len(args) != 1 {
@@ -86,19 +176,52 @@ func (t *SimpleChaincode) Init(stub shim.ChaincodeStubInterface, function string
 	if stateArg.Version != MYVERSION {
 		return nil, errors.New("Contract version " + MYVERSION + " must match version argument: " + stateArg.Version)
 	}
-	contractStateJSON, err := json.Marshal(stateArg)
+	// a repeated init keeps the configuration its argument does not set
+	state := ContractState{}
+	now, err := txTime(stub)
 	if err != nil {
-		return nil, errors.New("Marshal failed for contract state" + fmt.Sprint(err))
+		return nil, err
+	}
+	migration := Migration{ToVersion: MYVERSION, TxID: stub.GetTxID(), Timestamp: formatTime(now)}
+	previousBytes, err := stub.GetState(CONTRACTSTATEKEY)
+	if err == nil && len(previousBytes) > 0 {
+		var previous ContractState
+		if json.Unmarshal(previousBytes, &previous) == nil {
+			state = previous
+			migration.FromVersion = previous.Version
+		}
 	}
-	err = stub.PutState(CONTRACTSTATEKEY, contractStateJSON)
+	state.Version = MYVERSION
+	mergeContractConfig(&state, stateArg)
+	state.LastMigration = &migration
+	err = t.putContractState(stub, state)
 	if err != nil {
-		return nil, errors.New("Contract state failed PUT to ledger: " + fmt.Sprint(err))
+		return nil, err
 	}
-	return nil, nil
+	return nil, t.backfillAssetRegistry(stub)
+}
+
+// getContractState - the contract state stored at init
+func (t *SimpleChaincode) getContractState(stub shim.ChaincodeStubInterface) (ContractState, error) {
+	var state ContractState
+	stateBytes, err := stub.GetState(CONTRACTSTATEKEY)
+	if err != nil || len(stateBytes) == 0 {
+		return contractState, nil
+	}
+	err = json.Unmarshal(stateBytes, &state)
+	if err != nil {
+		return state, errors.New("Unable to unmarshal contract state obtained from ledger")
+	}
+	return state, nil
 }
 
 // Invoke - implementation of invoke method
 func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	// operations under an approval policy only run through executeOperation
+	err := t.requireNoApprovalPolicy(stub, function)
+	if err != nil {
+		return nil, err
+	}
 	// Handle different functions
 	if function == "createAsset" {
 		// create assetID
@@ -109,6 +232,67 @@ func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface, function stri
 	} else if function == "deleteAsset" {
 		// Deletes an asset by ID from the ledger
 		return t.deleteAsset(stub, args)
+	} else if function == "openWorkOrder" {
+		// opens a maintenance work order for an assetID
+		return t.openWorkOrder(stub, args)
+	} else if function == "assignTechnician" {
+		return t.assignTechnician(stub, args)
+	} else if function == "updateWorkOrder" {
+		return t.updateWorkOrder(stub, args)
+	} else if function == "closeWorkOrder" {
+		return t.closeWorkOrder(stub, args)
+	} else if function == "setSLA" {
+		// admin only, stores service level agreement terms for an assetID or a building
+		return t.setSLA(stub, args)
+	} else if function == "setHealthWeights" {
+		// admin only, stores the weights of the health score components
+		return t.setHealthWeights(stub, args)
+	} else if function == "reportIncident" {
+		// records or updates an entrapment, alarm button, emergency stop or fire service recall
+		return t.reportIncident(stub, args)
+	} else if function == "recordInspection" {
+		// records a safety inspection and renews the certificate when passed, inspector or admin only
+		return t.recordInspection(stub, args)
+	} else if function == "batchUpdateAssets" {
+		// creates or updates several assets, all or none
+		return t.batchUpdateAssets(stub, args)
+	} else if function == "batchCreateAssets" {
+		// creates several assets that do not exist yet, all or none
+		return t.batchCreateAssets(stub, args)
+	} else if function == "rebuildIndexes" {
+		// admin only, recreates the asset registry and secondary indexes from the assets
+		return t.rebuildIndexes(stub, args)
+	} else if function == "setNameplate" {
+		// admin only, sets the static manufacturer data and ratings of an assetID
+		return t.setNameplate(stub, args)
+	} else if function == "setModel" {
+		// admin only, adds or replaces a model in the catalogue
+		return t.setModel(stub, args)
+	} else if function == "setConfidentialFields" {
+		// stores fields of an asset encrypted by the client, the contract never sees the key
+		return t.setConfidentialFields(stub, args)
+	} else if function == "proposeTransfer" {
+		// proposes a new maintenance provider for an asset
+		return t.proposeTransfer(stub, args)
+	} else if function == "acceptTransfer" {
+		// the new provider takes over the asset, its open work orders and SLA terms
+		return t.acceptTransfer(stub, args)
+	} else if function == "cancelTransfer" {
+		return t.cancelTransfer(stub, args)
+	} else if function == "setApprovalPolicy" {
+		// admin only, sets the approvals an operation needs before it runs
+		return t.setApprovalPolicy(stub, args)
+	} else if function == "proposeOperation" {
+		// proposes an operation under an approval policy
+		return t.proposeOperation(stub, args)
+	} else if function == "approveOperation" {
+		return t.approveOperation(stub, args)
+	} else if function == "executeOperation" {
+		// runs a proposed operation once enough organizations approved it
+		return t.executeOperation(stub, args)
+	} else if function == "updateContractConfig" {
+		// admin only, changes the contract configuration set at init
+		return t.updateContractConfig(stub, args)
 	}
 	return nil, errors.New("Received unknown invocation: " + function)
 }
@@ -117,7 +301,8 @@ func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface, function stri
 func (t *SimpleChaincode) Query(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 	// Handle different functions
 	if function == "readAsset" {
-		// gets the state for an assetID as a JSON struct
+		// gets the state for an assetID as a JSON struct, with the fields derived from it
+		// and the encrypted confidential fields for the organisation holding their key
 		return t.readAsset(stub, args)
 	} else if function == "readAssetObjectModel" {
 		return t.readAssetObjectModel(stub, args)
@@ -127,6 +312,77 @@ func (t *SimpleChaincode) Query(stub shim.ChaincodeStubInterface, function strin
 	} else if function == "readAssetSchemas" {
 		// returns selected sample objects
 		return t.readAssetSchemas(stub, args)
+	} else if function == "readAssetUsage" {
+		// returns the usage counters for an assetID
+		return t.readAssetUsage(stub, args)
+	} else if function == "readAssetAggregates" {
+		// returns hourly or daily telemetry aggregates for an assetID
+		return t.readAssetAggregates(stub, args)
+	} else if function == "readEnergyConsumption" {
+		// returns kWh and cost per billing period for an assetID or a building
+		return t.readEnergyConsumption(stub, args)
+	} else if function == "readAssetAnomalies" {
+		// returns the anomalies recorded for an assetID
+		return t.readAssetAnomalies(stub, args)
+	} else if function == "readAssetHealth" {
+		// returns a 0-100 health score for an assetID
+		return t.readAssetHealth(stub, args)
+	} else if function == "readHealthWeights" {
+		return t.readHealthWeights(stub, args)
+	} else if function == "readWorkOrders" {
+		// returns the work orders for an assetID as a JSON array
+		return t.readWorkOrders(stub, args)
+	} else if function == "readSLA" {
+		return t.readSLA(stub, args)
+	} else if function == "readSLAReport" {
+		// returns SLA breaches and penalties for a billing period
+		return t.readSLAReport(stub, args)
+	} else if function == "readIncidents" {
+		// lists incidents by assetID, building and time range
+		return t.readIncidents(stub, args)
+	} else if function == "readAvailabilityReport" {
+		// returns uptime, MTBF and MTTR for an assetID or a building
+		return t.readAvailabilityReport(stub, args)
+	} else if function == "readInspections" {
+		return t.readInspections(stub, args)
+	} else if function == "readCertificationStatus" {
+		// returns the certificate status for an assetID
+		return t.readCertificationStatus(stub, args)
+	} else if function == "readExpiringCertificates" {
+		// lists assets with expired or soon to expire certificates
+		return t.readExpiringCertificates(stub, args)
+	} else if function == "exportAssets" {
+		// returns a page of assets, optionally with history, as NDJSON or CSV
+		return t.exportAssets(stub, args)
+	} else if function == "queryAssets" {
+		// finds assets with a CouchDB style selector, sorted, projected and paged
+		return t.queryAssets(stub, args)
+	} else if function == "readAssetsByIndex" {
+		// lists assetIDs by building, model, alarm or certificate expiry
+		return t.readAssetsByIndex(stub, args)
+	} else if function == "readNameplate" {
+		return t.readNameplate(stub, args)
+	} else if function == "readModels" {
+		return t.readModels(stub, args)
+	} else if function == "readAssetProvenance" {
+		// returns the writer, transaction, device and time of the last write of each field
+		return t.readAssetProvenance(stub, args)
+	} else if function == "readProposals" {
+		return t.readProposals(stub, args)
+	} else if function == "readApprovalPolicies" {
+		return t.readApprovalPolicies(stub, args)
+	} else if function == "readTransfers" {
+		// returns the maintenance provider transfers of an asset
+		return t.readTransfers(stub, args)
+	} else if function == "readAssetLimits" {
+		// returns the limits in effect for an assetID and where they come from
+		return t.readAssetLimits(stub, args)
+	} else if function == "readContractState" {
+		// returns the contract version, configuration, last migration and asset and alarm counts
+		return t.readContractState(stub, args)
+	} else if function == "diagnostics" {
+		// checks the asset registry, secondary indexes and usage counters against the assets
+		return t.diagnostics(stub, args)
 	}
 	return nil, errors.New("Received unknown invocation: " + function)
 }
@@ -134,6 +390,13 @@ func (t *SimpleChaincode) Query(stub shim.ChaincodeStubInterface, function strin
 /**********main implementation *************/
 
 func main() {
+	// offline tools run from the same binary, see runImport and runExport
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImport(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		os.Exit(runExport(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
 	err := shim.Start(new(SimpleChaincode))
 	if err != nil {
 		fmt.Printf("Error starting Simple Chaincode: %s", err)
@@ -171,12 +434,91 @@ func (t *SimpleChaincode) deleteAsset(stub shim.ChaincodeStubInterface, args []s
 		return nil, err
 	}
 	assetID = *stateIn.AssetID
+	// Keep the existing state to clean up the records kept alongside it
+	assetBytes, err := stub.GetState(assetID)
+	if err == nil && len(assetBytes) > 0 {
+		var stateOld AssetState
+		err = json.Unmarshal(assetBytes, &stateOld)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal state data obtained from ledger")
+		}
+		err = t.updateAssetIndexes(stub, assetID, &stateOld, nil)
+		if err != nil {
+			return nil, err
+		}
+		var cert Certificate
+		certBytes, err := stub.GetState(compositeKey(CERTIFICATEKEYPREFIX, assetID))
+		if err == nil && len(certBytes) > 0 && json.Unmarshal(certBytes, &cert) == nil {
+			err = t.updateCertificateIndex(stub, assetID, &cert, nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+		nameplate, err := t.getNameplate(stub, assetID)
+		if err != nil {
+			return nil, err
+		}
+		err = t.updateIndexEntries(stub, MODELINDEXKEYPREFIX, assetID, nameplateIndexValues(nameplate), nil)
+		if err != nil {
+			return nil, err
+		}
+		// history stays on the ledger, the registry lists current assets only
+		err = t.updateAssetRegistry(stub, assetID, nil)
+		if err != nil {
+			return nil, err
+		}
+		// outages stay as history, but end with the asset
+		now, err := txTime(stub)
+		if err != nil {
+			return nil, err
+		}
+		err = t.closeOutages(stub, assetID, "", now)
+		if err != nil {
+			return nil, err
+		}
+	}
 	// Delete the key / asset from the ledger
 	err = stub.DelState(assetID)
 	if err != nil {
 		err = errors.New("DELSTATE failed! : " + fmt.Sprint(err))
 		return nil, err
 	}
+	// and the records kept alongside it
+	err = stub.DelState(compositeKey(USAGEKEYPREFIX, assetID))
+	if err != nil {
+		err = errors.New("DELSTATE failed for asset usage! : " + fmt.Sprint(err))
+		return nil, err
+	}
+	err = stub.DelState(compositeKey(CERTIFICATEKEYPREFIX, assetID))
+	if err != nil {
+		err = errors.New("DELSTATE failed for asset certificate! : " + fmt.Sprint(err))
+		return nil, err
+	}
+	err = stub.DelState(compositeKey(METERKEYPREFIX, assetID))
+	if err != nil {
+		err = errors.New("DELSTATE failed for asset meter! : " + fmt.Sprint(err))
+		return nil, err
+	}
+	err = stub.DelState(compositeKey(BASELINEKEYPREFIX, assetID))
+	if err != nil {
+		err = errors.New("DELSTATE failed for asset baseline! : " + fmt.Sprint(err))
+		return nil, err
+	}
+	err = stub.DelState(compositeKey(NAMEPLATEKEYPREFIX, assetID))
+	if err != nil {
+		err = errors.New("DELSTATE failed for asset nameplate! : " + fmt.Sprint(err))
+		return nil, err
+	}
+	err = stub.DelState(compositeKey(PROVENANCEKEYPREFIX, assetID))
+	if err != nil {
+		err = errors.New("DELSTATE failed for asset provenance! : " + fmt.Sprint(err))
+		return nil, err
+	}
+	err = stub.DelState(compositeKey(CONFIDENTIALKEYPREFIX, assetID))
+	if err != nil {
+		err = errors.New("DELSTATE failed for asset confidential fields! : " + fmt.Sprint(err))
+		return nil, err
+	}
 	return nil, nil
 }
 
@@ -206,7 +548,21 @@ func (t *SimpleChaincode) readAsset(stub shim.ChaincodeStubInterface, args []str
 		err = errors.New("Unable to unmarshal state data obtained from ledger")
 		return nil, err
 	}
-	return assetBytes, nil
+	// derived fields are computed on every read and returned apart from the stored state
+	derived, err := t.getDerivedFields(stub, state)
+	if err != nil {
+		return nil, err
+	}
+	// encrypted confidential fields are merged in for the organisation holding their key
+	confidential, err := t.readConfidentialRecord(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+	viewJSON, err := json.Marshal(AssetView{AssetState: state, Derived: derived, Confidential: confidential})
+	if err != nil {
+		return nil, errors.New("Marshal failed for asset view" + fmt.Sprint(err))
+	}
+	return viewJSON, nil
 }
 
 //*************readAssetObjectModel*****************/
@@ -264,6 +620,18 @@ func (t *SimpleChaincode) validateInput(args []string) (stateIn AssetState, err
 			err = errors.New("AssetID not passed")
 			return state, err
 		}
+		if strings.Contains(assetID, KEYSEPARATOR) {
+			err = errors.New("AssetID contains an invalid character")
+			return state, err
+		}
+		if assetID == CONTRACTSTATEKEY {
+			err = errors.New("AssetID is reserved: " + assetID)
+			return state, err
+		}
+		if stateIn.Building != nil && strings.Contains(*stateIn.Building, KEYSEPARATOR) {
+			err = errors.New("Building contains an invalid character")
+			return state, err
+		}
 	} else {
 		err = errors.New("Asset id is mandatory in the input JSON data")
 		return state, err
@@ -276,10 +644,8 @@ func (t *SimpleChaincode) validateInput(args []string) (stateIn AssetState, err
 //******************** createOrUpdateAsset ********************/
 
 func (t *SimpleChaincode) createOrUpdateAsset(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	var assetID string // asset ID                    // used when looking in map
 	var err error
 	var stateIn AssetState
-	var stateStub AssetState
 
 	// validate input data for number of args, Unmarshaling to asset state and obtain asset id
 
@@ -287,7 +653,43 @@ func (t *SimpleChaincode) createOrUpdateAsset(stub shim.ChaincodeStubInterface,
 	if err != nil {
 		return nil, err
 	}
+	// strict validation mode rejects fields the contract would ignore
+	contract, err := t.getContractState(stub)
+	if err != nil {
+		return nil, err
+	}
+	err = validateStrict(contract, []byte(args[0]))
+	if err != nil {
+		return nil, err
+	}
+	_, anomalies, err := t.putAssetState(stub, stateIn)
+	if err != nil {
+		return nil, err
+	}
+	err = t.emitAnomalies(stub, anomalies)
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+/*********************************  internal: putAssetState ****************************/
+
+// putAssetState - creates an asset or merges a validated partial state into it, and updates the
+// records kept alongside it. Returns whether the asset was created and the anomalies found,
+// which the caller emits since a transaction carries a single event.
+func (t *SimpleChaincode) putAssetState(stub shim.ChaincodeStubInterface, stateIn AssetState) (bool, []Anomaly, error) {
+	var assetID string // asset ID                    // used when looking in map
+	var err error
+	var stateStub AssetState
+	var stateOld *AssetState // previous state, nil on create
+	var anomalies []Anomaly
+
 	assetID = *stateIn.AssetID
+	contract, err := t.getContractState(stub)
+	if err != nil {
+		return false, nil, err
+	}
 	// Partial updates introduced here
 	// Check if asset record existed in stub
 	assetBytes, err := stub.GetState(assetID)
@@ -299,19 +701,52 @@ func (t *SimpleChaincode) createOrUpdateAsset(stub shim.ChaincodeStubInterface,
 		err = json.Unmarshal(assetBytes, &stateStub)
 		if err != nil {
 			err = errors.New("Unable to unmarshal JSON data from stub")
-			return nil, err
+			return false, nil, err
 			// state is an empty instance of asset state
 		}
+		priorState := stateStub
+		stateOld = &priorState
 		// Merge partial state updates
 		stateStub, err = t.mergePartialState(stateStub, stateIn)
 		if err != nil {
 			err = errors.New("Unable to merge state")
-			return nil, err
+			return false, nil, err
 		}
 	}
+	// the device is only kept in the provenance of the fields it set
+	stateStub.DeviceID = nil
+	// Owner and provider change hands through admins and transfers
+	err = t.validateOwnership(stub, stateOld, stateStub)
+	if err != nil {
+		return false, nil, err
+	}
+	// Check elevator enum values and state transitions
+	err = t.validateElevatorState(stateOld, stateStub)
+	if err != nil {
+		return false, nil, err
+	}
+	limits, err := t.getAssetLimits(stub, assetID)
+	if err != nil {
+		return false, nil, err
+	}
+	stateStub.Alarms = t.evaluateAlarms(stateStub, limits)
+	// Account energy from the energy meter reading
+	consumed, err := t.updateEnergy(stub, stateIn)
+	if err != nil {
+		return false, nil, err
+	}
+	readings := telemetryReadings(stateIn, consumed)
+	// Check the incoming readings against the rolling baseline
+	if featureEnabled(contract, FEATUREANOMALIES) {
+		anomalies, err = t.updateBaseline(stub, assetID, readings)
+		if err != nil {
+			return false, nil, err
+		}
+	}
+	stateStub.Anomalies = currentAnomalies(stateOld, readings, anomalies)
 	stateJSON, err := json.Marshal(stateStub)
 	if err != nil {
-		return nil, errors.New("Marshal failed for contract state" + fmt.Sprint(err))
+		return false, nil, errors.New("Marshal failed for contract state" + fmt.Sprint(err))
 	}
 	// Get existing state from the stub
 
@@ -319,9 +754,53 @@ func (t *SimpleChaincode) createOrUpdateAsset(stub shim.ChaincodeStubInterface,
 	err = stub.PutState(assetID, stateJSON)
 	if err != nil {
 		err = errors.New("PUT ledger state failed: " + fmt.Sprint(err))
-		return nil, err
+		return false, nil, err
 	}
-	return nil, nil
+	// Derive usage counters from the transition
+	err = t.updateAssetUsage(stub, stateOld, stateStub)
+	if err != nil {
+		return false, nil, err
+	}
+	// Aggregate the incoming readings in telemetry buckets
+	if featureEnabled(contract, FEATURETELEMETRY) {
+		err = t.updateTelemetry(stub, assetID, readings)
+		if err != nil {
+			return false, nil, err
+		}
+	}
+	// Alarms entered with this update open a work order
+	if featureEnabled(contract, FEATUREALARMWORKORDERS) {
+		err = t.openAlarmWorkOrders(stub, stateOld, stateStub)
+		if err != nil {
+			return false, nil, err
+		}
+	}
+	// Track out of service intervals and index entries
+	err = t.updateOutages(stub, stateOld, stateStub)
+	if err != nil {
+		return false, nil, err
+	}
+	err = t.updateAssetIndexes(stub, assetID, stateOld, &stateStub)
+	if err != nil {
+		return false, nil, err
+	}
+	err = t.updateAssetRegistry(stub, assetID, &stateStub)
+	if err != nil {
+		return false, nil, err
+	}
+	// Record who and what wrote each incoming field
+	if featureEnabled(contract, FEATUREPROVENANCE) {
+		err = t.updateProvenance(stub, stateIn)
+		if err != nil {
+			return false, nil, err
+		}
+	}
+	// Drop the records that fell out of the retention windows
+	err = t.pruneRecords(stub, contract, assetID)
+	if err != nil {
+		return false, nil, err
+	}
+	return stateOld == nil, anomalies, nil
 }
 
 /*********************************  internal: mergePartialState ****************************/
@@ -338,3 +817,153 @@ func (t *SimpleChaincode) mergePartialState(oldState AssetState, newState AssetS
 	}
 	return oldState, nil
 }
+
+/*********************************  internal: validateElevatorState ****************************/
+func (t *SimpleChaincode) validateElevatorState(oldState *AssetState, newState AssetState) error {
+	if newState.Direction != nil && !isOneOf(*newState.Direction, DIRECTIONUP, DIRECTIONDOWN, DIRECTIONSTOPPED) {
+		return errors.New("Invalid direction: " + *newState.Direction)
+	}
+	if newState.DoorStatus != nil && !isOneOf(*newState.DoorStatus, DOOROPEN, DOORCLOSING, DOORCLOSED, DOOROBSTRUCTED) {
+		return errors.New("Invalid doorStatus: " + *newState.DoorStatus)
+	}
+	if newState.OperatingMode != nil && !isOneOf(*newState.OperatingMode, MODENORMAL, MODEINSPECTION, MODEFIRESERVICE, MODEOUTOFSERVICE) {
+		return errors.New("Invalid operatingMode: " + *newState.OperatingMode)
+	}
+	moving := isMoving(newState)
+	// the car may only travel with the doors closed
+	if moving && newState.DoorStatus != nil && *newState.DoorStatus != DOORCLOSED {
+		return errors.New("Elevator cannot be moving while doors are " + *newState.DoorStatus)
+	}
+	if moving && newState.OperatingMode != nil && *newState.OperatingMode == MODEOUTOFSERVICE {
+		return errors.New("Elevator cannot be moving while out of service")
+	}
+	if oldState == nil {
+		return nil
+	}
+	// a floor change means the car travelled since the last update
+	if oldState.Floor != nil && newState.Floor != nil && *oldState.Floor != *newState.Floor &&
+		oldState.DoorStatus != nil && *oldState.DoorStatus != DOORCLOSED &&
+		newState.DoorStatus != nil && *newState.DoorStatus != DOORCLOSED {
+		return errors.New("Elevator cannot change floor while doors are " + *newState.DoorStatus)
+	}
+	// the car must stop before reversing
+	if oldState.Direction != nil && newState.Direction != nil &&
+		((*oldState.Direction == DIRECTIONUP && *newState.Direction == DIRECTIONDOWN) ||
+			(*oldState.Direction == DIRECTIONDOWN && *newState.Direction == DIRECTIONUP)) {
+		return errors.New("Elevator must stop before changing direction from " + *oldState.Direction + " to " + *newState.Direction)
+	}
+	return nil
+}
+
+/*********************************  internal: evaluateAlarms ****************************/
+
+// evaluateAlarms - returns the alarms active for a state against the asset's limits, nil when there are none
+func (t *SimpleChaincode) evaluateAlarms(state AssetState, limits AssetLimits) []string {
+	var alarms []string
+	if state.Temperature != nil && ((limits.MaxTemperature != nil && *state.Temperature > *limits.MaxTemperature) ||
+		(limits.MinTemperature != nil && *state.Temperature < *limits.MinTemperature)) {
+		alarms = append(alarms, ALARMTEMPERATURE)
+	}
+	if limits.RatedLoad != nil && state.Weight != nil && *state.Weight > *limits.RatedLoad {
+		alarms = append(alarms, ALARMOVERLOAD)
+	}
+	if limits.RatedSpeed != nil && state.Speed != nil && math.Abs(*state.Speed) > *limits.RatedSpeed {
+		alarms = append(alarms, ALARMOVERSPEED)
+	}
+	return alarms
+}
+
+// isMoving - true when the state reports travel in either direction or a non zero speed
+func isMoving(state AssetState) bool {
+	if state.Direction != nil && *state.Direction != DIRECTIONSTOPPED {
+		return true
+	}
+	return state.Speed != nil && *state.Speed != 0
+}
+
+// isOneOf - true when value matches one of the allowed values
+func isOneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+// stringValue - the value of an optional string property, empty when it is not set
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+/*********************************  internal: caller roles ****************************/
+
+// requireRole - fails unless the caller's certificate carries one of the roles
+func (t *SimpleChaincode) requireRole(stub shim.ChaincodeStubInterface, roles ...string) error {
+	role, err := stub.ReadCertAttribute(ROLEATTRIBUTE)
+	if err != nil {
+		return errors.New("Unable to read the role of the caller: " + fmt.Sprint(err))
+	}
+	if !isOneOf(string(role), roles...) {
+		return errors.New("Caller role " + string(role) + " is not allowed, expecting " + strings.Join(roles, " or "))
+	}
+	return nil
+}
+
+// callerOrganization - organisation from the caller's certificate, empty when it carries none
+func callerOrganization(stub shim.ChaincodeStubInterface) string {
+	org, err := stub.ReadCertAttribute(ORGATTRIBUTE)
+	if err != nil {
+		return ""
+	}
+	return string(org)
+}
+
+/*********************************  internal: ledger keys and time ****************************/
+
+// compositeKey - builds a ledger key for records kept alongside assets, e.g. USAGE\x00assetID
+func compositeKey(objectType string, attributes ...string) string {
+	return objectType + KEYSEPARATOR + strings.Join(attributes, KEYSEPARATOR)
+}
+
+// compositeRange - start and end keys covering every composite key that extends the given prefix
+func compositeRange(objectType string, attributes ...string) (string, string) {
+	prefix := objectType + KEYSEPARATOR
+	for _, attribute := range attributes {
+		prefix += attribute + KEYSEPARATOR
+	}
+	return prefix, prefix + "\xff"
+}
+
+// timeKey - fixed width UTC timestamp for use in composite keys, sorts in time order
+func timeKey(tm time.Time) string {
+	return tm.UTC().Format("20060102T150405.000000000Z")
+}
+
+// txTime - transaction timestamp, the same on every peer endorsing the transaction. There is no
+// fallback to the local clock, which would differ between peers.
+func txTime(stub shim.ChaincodeStubInterface) (time.Time, error) {
+	ts, err := stub.GetTxTimestamp()
+	if err != nil || ts == nil {
+		return time.Time{}, errors.New("Unable to read the transaction timestamp: " + fmt.Sprint(err))
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC(), nil
+}
+
+// formatTime - timestamps are stored on the ledger as RFC3339 strings
+func formatTime(tm time.Time) string {
+	return tm.UTC().Format(time.RFC3339Nano)
+}
+
+// parseTime - parses a timestamp stored by formatTime or supplied by a client,
+// clients may also pass a plain date such as 2016-09-21
+func parseTime(value string) (time.Time, error) {
+	tm, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		tm, err = time.Parse("2006-01-02", value)
+	}
+	return tm, err
+}
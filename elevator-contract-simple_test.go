@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// testStub - a MockStub with the caller attributes, transaction time and events the MockStub
+// does not provide. Failed invocations are rolled back, as the peer discards them.
+type testStub struct {
+	*shim.MockStub
+	attributes map[string]string
+	now        time.Time
+	event      string
+	payload    []byte
+	tx         int
+}
+
+func newTestStub(t *testing.T) *testStub {
+	stub := &testStub{
+		MockStub:   shim.NewMockStub("elevator", new(SimpleChaincode)),
+		attributes: map[string]string{},
+		now:        time.Date(2016, 9, 1, 12, 0, 0, 0, time.UTC),
+	}
+	stub.mustInit(t, `{"version":"`+MYVERSION+`"}`)
+	return stub
+}
+
+// mustInit - runs Init as a transaction, as a deploy or upgrade does
+func (s *testStub) mustInit(t *testing.T, arg string) {
+	_, err := s.transact(func() ([]byte, error) {
+		return new(SimpleChaincode).Init(s, "init", []string{arg})
+	})
+	if err != nil {
+		t.Fatal("init failed: ", err)
+	}
+}
+
+func (s *testStub) ReadCertAttribute(name string) ([]byte, error) {
+	return []byte(s.attributes[name]), nil
+}
+
+func (s *testStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return &timestamp.Timestamp{Seconds: s.now.Unix()}, nil
+}
+
+func (s *testStub) SetEvent(name string, payload []byte) error {
+	s.event, s.payload = name, payload
+	return nil
+}
+
+// as - sets the role and organization the caller's certificate carries
+func (s *testStub) as(role string, organization string) *testStub {
+	s.attributes[ROLEATTRIBUTE] = role
+	s.attributes[ORGATTRIBUTE] = organization
+	return s
+}
+
+// transact - runs fn as a transaction and undoes its writes when it fails
+func (s *testStub) transact(fn func() ([]byte, error)) ([]byte, error) {
+	s.tx++
+	s.event, s.payload = "", nil
+	return mockTransact(s.MockStub, fmt.Sprintf("tx%d", s.tx), fn)
+}
+
+func (s *testStub) invoke(function string, arg string) ([]byte, error) {
+	return s.transact(func() ([]byte, error) {
+		return new(SimpleChaincode).Invoke(s, function, []string{arg})
+	})
+}
+
+func (s *testStub) query(function string, arg string) ([]byte, error) {
+	var args []string
+	if arg != "" {
+		args = []string{arg}
+	}
+	return new(SimpleChaincode).Query(s, function, args)
+}
+
+// mustInvoke - fails the test unless the invocation succeeds
+func (s *testStub) mustInvoke(t *testing.T, function string, arg string) []byte {
+	result, err := s.invoke(function, arg)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", function, arg, err)
+	}
+	return result
+}
+
+// mustFail - fails the test unless the invocation fails with an error containing want
+func (s *testStub) mustFail(t *testing.T, function string, arg string, want string) {
+	_, err := s.invoke(function, arg)
+	if err == nil {
+		t.Fatalf("%s %s succeeded, expecting %q", function, arg, want)
+	}
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("%s %s failed with %q, expecting %q", function, arg, err, want)
+	}
+}
+
+// mustQuery - fails the test unless the query succeeds, and unmarshals its result into result
+func (s *testStub) mustQuery(t *testing.T, function string, arg string, result interface{}) {
+	resultBytes, err := s.query(function, arg)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", function, arg, err)
+	}
+	if result != nil {
+		err = json.Unmarshal(resultBytes, result)
+		if err != nil {
+			t.Fatalf("%s %s returned %s: %v", function, arg, resultBytes, err)
+		}
+	}
+}
+
+// mustFailQuery - fails the test unless the query fails with an error containing want
+func (s *testStub) mustFailQuery(t *testing.T, function string, arg string, want string) {
+	_, err := s.query(function, arg)
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("%s %s returned error %v, expecting %q", function, arg, err, want)
+	}
+}
+
+// readAsset - the stored state of an asset
+func (s *testStub) readAsset(t *testing.T, assetID string) AssetView {
+	var view AssetView
+	s.mustQuery(t, "readAsset", `{"assetID":"`+assetID+`"}`, &view)
+	return view
+}
+
+func TestElevatorState(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","floor":1,"direction":"stopped","doorStatus":"open","operatingMode":"normal"}`)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","doorStatus":"closed","direction":"up","speed":200}`)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","floor":4}`)
+
+	view := stub.readAsset(t, "E1")
+	if view.Floor == nil || *view.Floor != 4 || *view.Direction != DIRECTIONUP || *view.DoorStatus != DOORCLOSED {
+		t.Fatalf("unexpected state %+v", view.AssetState)
+	}
+}
+
+func TestElevatorStateRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFail(t, "createAsset", `{"assetID":"E1","direction":"sideways"}`, "Invalid direction")
+	stub.mustFail(t, "createAsset", `{"assetID":"E1","doorStatus":"ajar"}`, "Invalid doorStatus")
+	stub.mustFail(t, "createAsset", `{"assetID":"E1","operatingMode":"party"}`, "Invalid operatingMode")
+	stub.mustFail(t, "createAsset", `{"assetID":"E1","direction":"up","doorStatus":"open"}`, "doors are open")
+	stub.mustFail(t, "createAsset", `{"assetID":"E1","speed":100,"operatingMode":"outOfService"}`, "out of service")
+	stub.mustFail(t, "createAsset", `{"assetID":"E\u00001"}`, "invalid character")
+
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","floor":1,"direction":"stopped","doorStatus":"open"}`)
+	stub.mustFail(t, "updateAsset", `{"assetID":"E1","floor":2}`, "cannot change floor")
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","doorStatus":"closed","direction":"up"}`)
+	stub.mustFail(t, "updateAsset", `{"assetID":"E1","direction":"down"}`, "must stop")
+
+	// the failed updates left the state alone
+	view := stub.readAsset(t, "E1")
+	if *view.Floor != 1 || *view.Direction != DIRECTIONUP {
+		t.Fatalf("failed updates changed the state %+v", view.AssetState)
+	}
+}
+
+func TestElevatorStateWithoutTimestamp(t *testing.T) {
+	// the plain mock stub has no transaction timestamp, and the contract has no clock of its own
+	stub := shim.NewMockStub("elevator", new(SimpleChaincode))
+	_, err := stub.MockInit("tx1", "init", []string{`{"version":"` + MYVERSION + `"}`})
+	if err == nil || !strings.Contains(err.Error(), "Unable to read the transaction timestamp") {
+		t.Fatalf("expecting init to fail without a timestamp, got %v", err)
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// METERKEYPREFIX - object type for the energy meter record of an asset, keyed by assetID
+const METERKEYPREFIX string = "METER"
+
+// ENERGYKEYPREFIX - object type for daily energy consumption, keyed by assetID and day
+const ENERGYKEYPREFIX string = "ENERGY"
+
+// billing period lengths
+const (
+	PERIODDAY   string = "day"
+	PERIODMONTH string = "month"
+)
+
+// EnergyMeter - last energy meter reading of an asset, a cumulative reading in kWh
+type EnergyMeter struct {
+	AssetID     string  `json:"assetID"`
+	LastReading float64 `json:"lastReading"` // last meter reading in kWh
+	LastRead    string  `json:"lastRead"`    // transaction time of the last reading
+	TotalKwh    float64 `json:"totalKwh"`    // energy consumed since the first reading
+	Resets      int64   `json:"resets"`      // number of meter resets detected
+}
+
+// EnergyDay - energy consumed by an asset during one UTC day
+type EnergyDay struct {
+	AssetID string  `json:"assetID"`
+	Day     string  `json:"day"`
+	Kwh     float64 `json:"kwh"`
+}
+
+// EnergyPeriod - energy consumed during one billing period, cost is set when a tariff was passed
+type EnergyPeriod struct {
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+	Kwh   float64  `json:"kwh"`
+	Cost  *float64 `json:"cost,omitempty"`
+}
+
+// EnergyReport - energy consumed by an asset or a building per billing period
+type EnergyReport struct {
+	AssetID   string         `json:"assetID,omitempty"`
+	Building  string         `json:"building,omitempty"`
+	Period    string         `json:"period"`
+	Tariff    *float64       `json:"tariff,omitempty"`
+	Currency  string         `json:"currency,omitempty"`
+	Periods   []EnergyPeriod `json:"periods"`
+	TotalKwh  float64        `json:"totalKwh"`
+	TotalCost *float64       `json:"totalCost,omitempty"`
+}
+
+//******************** readEnergyConsumption ********************/
+
+// readEnergyConsumption - returns kWh consumed by an asset, or by every asset in a building,
+// per day or calendar month in [from, to). Whole days are reported, a tariff per kWh adds costs.
+func (t *SimpleChaincode) readEnergyConsumption(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var query struct {
+		AssetID  string   `json:"assetID"`
+		Building string   `json:"building"`
+		From     string   `json:"from"`
+		To       string   `json:"to"`
+		Period   string   `json:"period"`
+		Tariff   *float64 `json:"tariff"`
+		Currency string   `json:"currency"`
+	}
+	var assetIDs []string
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with assetID or building")
+	}
+	err := json.Unmarshal([]byte(args[0]), &query)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	query.AssetID = strings.TrimSpace(query.AssetID)
+	query.Building = strings.TrimSpace(query.Building)
+	// validates the scope the same way as SLA terms
+	_, err = slaKey(query.AssetID, query.Building)
+	if err != nil {
+		return nil, err
+	}
+	if query.Period == "" {
+		query.Period = PERIODMONTH
+	}
+	if !isOneOf(query.Period, PERIODDAY, PERIODMONTH) {
+		return nil, errors.New("Invalid period: " + query.Period)
+	}
+	if query.Tariff != nil && *query.Tariff < 0 {
+		return nil, errors.New("Tariff cannot be negative")
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := reportPeriod(query.From, query.To, now)
+	if err != nil {
+		return nil, err
+	}
+	from = bucketStart(from, GRANULARITYDAY)
+	if query.AssetID != "" {
+		assetIDs = []string{query.AssetID}
+	} else {
+		assetIDs, err = t.getBuildingAssets(stub, query.Building)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// one entry per billing period, in time order
+	report := EnergyReport{
+		AssetID:  query.AssetID,
+		Building: query.Building,
+		Period:   query.Period,
+		Tariff:   query.Tariff,
+		Currency: query.Currency,
+		Periods:  []EnergyPeriod{},
+	}
+	index := map[string]int{}
+	for start := from; start.Before(to); {
+		end := start.AddDate(0, 0, 1)
+		if query.Period == PERIODMONTH {
+			end = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+		}
+		if end.After(to) {
+			end = to
+		}
+		index[formatTime(start)] = len(report.Periods)
+		report.Periods = append(report.Periods, EnergyPeriod{Start: formatTime(start), End: formatTime(end)})
+		start = end
+	}
+	for _, assetID := range assetIDs {
+		days, err := t.getEnergyDays(stub, assetID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, day := range days {
+			start, _ := parseTime(day.Day)
+			if query.Period == PERIODMONTH {
+				start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+				if start.Before(from) {
+					start = from
+				}
+			}
+			i, found := index[formatTime(start)]
+			if !found {
+				continue
+			}
+			report.Periods[i].Kwh += day.Kwh
+			report.TotalKwh += day.Kwh
+		}
+	}
+	if query.Tariff != nil {
+		totalCost := report.TotalKwh * *query.Tariff
+		report.TotalCost = &totalCost
+		for i := range report.Periods {
+			cost := report.Periods[i].Kwh * *query.Tariff
+			report.Periods[i].Cost = &cost
+		}
+	}
+	return json.Marshal(report)
+}
+
+/*********************************  internal: energy accounting ****************************/
+
+// updateEnergy - accounts the energy consumed since the previous energy meter reading. A reading
+// below the previous one is a meter reset and counts from zero. Consumption over a gap between
+// readings is spread over the days of the gap in proportion to time. Returns the energy consumed,
+// nil when the update carries no reading or the first one.
+func (t *SimpleChaincode) updateEnergy(stub shim.ChaincodeStubInterface, stateIn AssetState) (*float64, error) {
+	var meter EnergyMeter
+	var consumed *float64
+	if stateIn.EnergyMeter == nil {
+		return nil, nil
+	}
+	assetID := *stateIn.AssetID
+	reading := *stateIn.EnergyMeter
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	key := compositeKey(METERKEYPREFIX, assetID)
+
+	meterBytes, err := stub.GetState(key)
+	if err == nil && len(meterBytes) > 0 {
+		err = json.Unmarshal(meterBytes, &meter)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal meter data obtained from ledger")
+		}
+		kwh := reading - meter.LastReading
+		if reading < meter.LastReading {
+			meter.Resets++
+			kwh = reading
+		}
+		last, err := parseTime(meter.LastRead)
+		if err != nil || !now.After(last) {
+			last = now
+		}
+		err = t.addEnergy(stub, assetID, last, now, kwh)
+		if err != nil {
+			return nil, err
+		}
+		meter.TotalKwh += kwh
+		consumed = &kwh
+	}
+	// the first reading only sets the baseline
+	meter.AssetID = assetID
+	meter.LastReading = reading
+	meter.LastRead = formatTime(now)
+	meterJSON, err := json.Marshal(meter)
+	if err != nil {
+		return nil, errors.New("Marshal failed for meter" + fmt.Sprint(err))
+	}
+	err = stub.PutState(key, meterJSON)
+	if err != nil {
+		return nil, errors.New("PUT ledger state failed for meter: " + fmt.Sprint(err))
+	}
+	return consumed, nil
+}
+
+// addEnergy - spreads kwh consumed over [from, to] across the days it covers
+func (t *SimpleChaincode) addEnergy(stub shim.ChaincodeStubInterface, assetID string, from time.Time, to time.Time, kwh float64) error {
+	if kwh == 0 {
+		return nil
+	}
+	span := to.Sub(from)
+	for day := bucketStart(from, GRANULARITYDAY); ; day = day.AddDate(0, 0, 1) {
+		share := kwh
+		if span > 0 {
+			start, end := day, day.AddDate(0, 0, 1)
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			share = kwh * float64(end.Sub(start)) / float64(span)
+		}
+		err := t.putEnergyDay(stub, assetID, day, share)
+		if err != nil {
+			return err
+		}
+		if !day.AddDate(0, 0, 1).Before(to) {
+			return nil
+		}
+	}
+}
+
+func (t *SimpleChaincode) putEnergyDay(stub shim.ChaincodeStubInterface, assetID string, day time.Time, kwh float64) error {
+	var energy EnergyDay
+	key := compositeKey(ENERGYKEYPREFIX, assetID, timeKey(day))
+	energyBytes, err := stub.GetState(key)
+	if err == nil && len(energyBytes) > 0 {
+		err = json.Unmarshal(energyBytes, &energy)
+		if err != nil {
+			return errors.New("Unable to unmarshal energy data obtained from ledger")
+		}
+	}
+	energy.AssetID = assetID
+	energy.Day = formatTime(day)
+	energy.Kwh += kwh
+	energyJSON, err := json.Marshal(energy)
+	if err != nil {
+		return errors.New("Marshal failed for energy" + fmt.Sprint(err))
+	}
+	err = stub.PutState(key, energyJSON)
+	if err != nil {
+		return errors.New("PUT ledger state failed for energy: " + fmt.Sprint(err))
+	}
+	return nil
+}
+
+// getEnergyDays - daily consumption of an asset for days starting in [from, to)
+func (t *SimpleChaincode) getEnergyDays(stub shim.ChaincodeStubInterface, assetID string, from time.Time, to time.Time) ([]EnergyDay, error) {
+	var days []EnergyDay
+	iter, err := stub.RangeQueryState(
+		compositeKey(ENERGYKEYPREFIX, assetID, timeKey(from)),
+		compositeKey(ENERGYKEYPREFIX, assetID, timeKey(to)))
+	if err != nil {
+		return nil, errors.New("Unable to read energy from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		var energy EnergyDay
+		_, energyBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read energy from ledger: " + fmt.Sprint(err))
+		}
+		err = json.Unmarshal(energyBytes, &energy)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal energy data obtained from ledger")
+		}
+		day, err := parseTime(energy.Day)
+		if err != nil || !day.Before(to) {
+			continue
+		}
+		days = append(days, energy)
+	}
+	return days, nil
+}
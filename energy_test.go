@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEnergyConsumption(t *testing.T) {
+	var report, monthly EnergyReport
+	var hours []TelemetryBucket
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","building":"B1","energyMeter":100}`)
+	stub.now = stub.now.Add(time.Hour)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","power":12.5,"energyMeter":110}`)
+	// a gap over midnight is spread over both days
+	stub.now = stub.now.Add(24 * time.Hour)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","energyMeter":134}`)
+	// the meter was reset
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","energyMeter":4}`)
+
+	stub.mustQuery(t, "readEnergyConsumption", `{"assetID":"E1","from":"2016-09-01","to":"2016-09-03","period":"day","tariff":0.5}`, &report)
+	if len(report.Periods) != 2 || report.Periods[0].Kwh != 21 || report.Periods[1].Kwh != 17 {
+		t.Fatalf("unexpected daily consumption %+v", report.Periods)
+	}
+	if report.TotalKwh != 38 || report.TotalCost == nil || *report.TotalCost != 19 {
+		t.Fatalf("unexpected totals %+v", report)
+	}
+	stub.mustQuery(t, "readEnergyConsumption", `{"building":"B1","from":"2016-09-01","to":"2016-10-01"}`, &monthly)
+	if len(monthly.Periods) != 1 || monthly.TotalKwh != 38 || monthly.TotalCost != nil {
+		t.Fatalf("unexpected monthly consumption %+v", monthly)
+	}
+
+	// the aggregates carry the energy consumed next to the power reading, not the meter reading
+	stub.mustQuery(t, "readAssetAggregates", `{"assetID":"E1","from":"2016-09-01"}`, &hours)
+	if len(hours) == 0 || hours[0].Start != "2016-09-01T13:00:00Z" || len(hours[0].Fields) != 2 {
+		t.Fatalf("unexpected hourly buckets %+v", hours)
+	}
+	if power := hours[0].Fields[FIELDPOWER]; power == nil || power.Count != 1 || power.Max != 12.5 {
+		t.Fatalf("unexpected power aggregate %+v", hours[0])
+	}
+	if energy := hours[0].Fields[FIELDENERGY]; energy == nil || energy.Count != 1 || energy.Max != 10 {
+		t.Fatalf("unexpected energy aggregate %+v", hours[0])
+	}
+}
+
+func TestEnergyConsumptionRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFailQuery(t, "readEnergyConsumption", `{"from":"2016-09-01"}`, "Expecting either an assetID or a building")
+	stub.mustFailQuery(t, "readEnergyConsumption", `{"assetID":"E1","from":"2016-09-01","period":"week"}`, "Invalid period")
+	stub.mustFailQuery(t, "readEnergyConsumption", `{"assetID":"E1","from":"2016-09-01","tariff":-1}`, "Tariff cannot be negative")
+	stub.mustFailQuery(t, "readEnergyConsumption", `{"assetID":"E1"}`, "Invalid or missing from")
+}
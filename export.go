@@ -0,0 +1,572 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// ASSETKEYPREFIX - object type for the registry of assets, keyed by assetID
+const ASSETKEYPREFIX string = "ASSET"
+
+// ASSETHISTORYKEYPREFIX - object type for past asset states, keyed by assetID, time and txID
+const ASSETHISTORYKEYPREFIX string = "ASSETHISTORY"
+
+// EXPORTPAGESIZE - records per exportAssets page by default, and the most a page may hold
+const (
+	EXPORTPAGESIZE    int = 100
+	EXPORTMAXPAGESIZE int = 1000
+)
+
+// snapshot formats
+const (
+	SNAPSHOTNDJSON string = "ndjson"
+	SNAPSHOTCSV    string = "csv"
+)
+
+// snapshot record types
+const (
+	SNAPSHOTASSET   string = "asset"   // current state of an asset
+	SNAPSHOTHISTORY string = "history" // state of an asset after a past transaction
+)
+
+// SnapshotHeader - metadata of an asset snapshot, the first line of an export
+type SnapshotHeader struct {
+	ContractVersion string `json:"contractVersion"`
+	Timestamp       string `json:"timestamp"`
+	History         bool   `json:"history"`
+	Assets          int    `json:"assets"`                // assets in this page
+	Next            string `json:"next,omitempty"`        // start of the next page, empty on the last page
+	NextHistory     string `json:"nextHistory,omitempty"` // historyStart of the next page when it resumes the history of next
+}
+
+// SnapshotRecord - a line of an asset snapshot
+type SnapshotRecord struct {
+	Record    string          `json:"record"`
+	Timestamp string          `json:"timestamp,omitempty"` // history records only
+	TxID      string          `json:"txID,omitempty"`      // history records only
+	State     json.RawMessage `json:"state"`
+}
+
+//******************** exportAssets ********************/
+
+// exportAssets - a page of at most limit records in assetID order as NDJSON, a header line followed
+// by a record per line, or as CSV with nested fields in dotted columns and the header in a leading
+// # comment. With history each asset is followed by its past states, oldest first. A page that
+// fills up within the history of an asset ends there, the next page resumes it at historyStart.
+func (t *SimpleChaincode) exportAssets(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var query struct {
+		Format       string `json:"format"`
+		History      bool   `json:"history"`
+		Start        string `json:"start"`        // first assetID of the page
+		HistoryStart string `json:"historyStart"` // history record of start the page resumes at
+		Limit        int    `json:"limit"`
+	}
+	if len(args) > 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting an optional JSON string with format, history, start, historyStart and limit")
+	}
+	if len(args) == 1 {
+		err := json.Unmarshal([]byte(args[0]), &query)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal input JSON data")
+		}
+	}
+	if query.Format == "" {
+		query.Format = SNAPSHOTNDJSON
+	}
+	if !isOneOf(query.Format, SNAPSHOTNDJSON, SNAPSHOTCSV) {
+		return nil, errors.New("Invalid format: " + query.Format)
+	}
+	if query.Limit == 0 {
+		query.Limit = EXPORTPAGESIZE
+	}
+	if query.Limit < 0 || query.Limit > EXPORTMAXPAGESIZE {
+		return nil, fmt.Errorf("Limit must be between 1 and %d", EXPORTMAXPAGESIZE)
+	}
+	if strings.Contains(query.Start, KEYSEPARATOR) || strings.Contains(query.HistoryStart, KEYSEPARATOR) {
+		return nil, errors.New("Start contains an invalid character")
+	}
+	if query.HistoryStart != "" && (query.Start == "" || !query.History) {
+		return nil, errors.New("HistoryStart expects start and history")
+	}
+	contract, err := t.getContractState(stub)
+	if err != nil {
+		return nil, err
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	header := SnapshotHeader{ContractVersion: contract.Version, Timestamp: formatTime(now), History: query.History}
+	var records []SnapshotRecord
+
+	startKey, endKey := compositeRange(ASSETKEYPREFIX)
+	if query.Start != "" {
+		startKey = compositeKey(ASSETKEYPREFIX, query.Start)
+	}
+	// every asset adds a record, but the one resumed from the previous page may add none
+	count := query.Limit + 1
+	if query.HistoryStart != "" {
+		count++
+	}
+	assetIDs, err := t.getAssetIDs(stub, startKey, endKey, count)
+	if err != nil {
+		return nil, err
+	}
+	if len(assetIDs) == count {
+		header.Next = assetIDs[count-1]
+		assetIDs = assetIDs[:count-1]
+	}
+	for i, assetID := range assetIDs {
+		if len(records) == query.Limit {
+			header.Next = assetID
+			break
+		}
+		historyStart := ""
+		if i == 0 && query.HistoryStart != "" && assetID == query.Start {
+			// the asset record is on the previous page
+			historyStart = query.HistoryStart
+		} else {
+			assetBytes, err := stub.GetState(assetID)
+			if err != nil || len(assetBytes) == 0 {
+				continue
+			}
+			header.Assets++
+			records = append(records, SnapshotRecord{Record: SNAPSHOTASSET, State: assetBytes})
+		}
+		if query.History {
+			history, next, err := t.getAssetHistory(stub, assetID, historyStart, query.Limit-len(records))
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, history...)
+			if next != "" {
+				header.Next, header.NextHistory = assetID, next
+				break
+			}
+		}
+	}
+	var out bytes.Buffer
+	if query.Format == SNAPSHOTCSV {
+		err = writeSnapshotCSV(&out, header, records)
+	} else {
+		err = writeSnapshotNDJSON(&out, header, records)
+	}
+	if err != nil {
+		return nil, errors.New("Unable to write snapshot: " + fmt.Sprint(err))
+	}
+	return out.Bytes(), nil
+}
+
+//******************** export command ********************/
+
+// runExport - offline extract of the assets for BI tools, run as
+//
+//	elevator-contract-simple export [-format ndjson|csv] [-history] -peer url -chaincode name [-secureContext user]
+//	elevator-contract-simple export [-format ndjson|csv] [file]
+//
+// The first form pages through exportAssets on a peer's REST API, the second converts an NDJSON
+// snapshot saved earlier. Writes a single snapshot to stdout, with the header of the first page.
+func runExport(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", SNAPSHOTNDJSON, "output format, ndjson or csv")
+	history := flags.Bool("history", false, "include past states of each asset")
+	peer := flags.String("peer", "", "REST endpoint of a peer, e.g. http://localhost:7050")
+	chaincode := flags.String("chaincode", "", "chaincode name, required with -peer")
+	secureContext := flags.String("secureContext", "", "enrolled user the query is sent as")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if !isOneOf(*format, SNAPSHOTNDJSON, SNAPSHOTCSV) {
+		fmt.Fprintln(stderr, "Invalid format: "+*format)
+		return 2
+	}
+	var header SnapshotHeader
+	var records []SnapshotRecord
+	var err error
+	if *peer != "" {
+		if *chaincode == "" || flags.NArg() > 0 {
+			fmt.Fprintln(stderr, "export from a peer expects -chaincode and no file")
+			return 2
+		}
+		start, historyStart := "", ""
+		for first := true; first || start != ""; first = false {
+			var page []byte
+			var pageHeader SnapshotHeader
+			var pageRecords []SnapshotRecord
+			queryJSON, _ := json.Marshal(map[string]interface{}{"format": SNAPSHOTNDJSON, "history": *history, "start": start, "historyStart": historyStart})
+			page, err = queryPeer(*peer, *chaincode, *secureContext, "exportAssets", string(queryJSON))
+			if err == nil {
+				pageHeader, pageRecords, err = readSnapshotNDJSON(bytes.NewReader(page))
+			}
+			if err != nil {
+				break
+			}
+			if first {
+				header = pageHeader
+				header.Assets = 0
+			}
+			header.Assets += pageHeader.Assets
+			records = append(records, pageRecords...)
+			start, historyStart = pageHeader.Next, pageHeader.NextHistory
+		}
+		header.Next, header.NextHistory = "", ""
+	} else {
+		in := stdin
+		if flags.NArg() > 1 {
+			fmt.Fprintln(stderr, "export expects at most one file")
+			return 2
+		} else if flags.NArg() == 1 && flags.Arg(0) != "-" {
+			file, err := os.Open(flags.Arg(0))
+			if err != nil {
+				fmt.Fprintln(stderr, err)
+				return 2
+			}
+			defer file.Close()
+			in = file
+		}
+		header, records, err = readSnapshotNDJSON(in)
+	}
+	if err == nil {
+		out := bufio.NewWriter(stdout)
+		if *format == SNAPSHOTCSV {
+			err = writeSnapshotCSV(out, header, records)
+		} else {
+			err = writeSnapshotNDJSON(out, header, records)
+		}
+		if err == nil {
+			err = out.Flush()
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+/*********************************  internal: asset registry and history ****************************/
+
+// updateAssetRegistry - lists an asset and records its new state in the asset's history, or
+// removes a deleted asset when newState is nil. Assets created before the registry existed are
+// listed by the first Init that finds the registry empty, see backfillAssetRegistry.
+func (t *SimpleChaincode) updateAssetRegistry(stub shim.ChaincodeStubInterface, assetID string, newState *AssetState) error {
+	key := compositeKey(ASSETKEYPREFIX, assetID)
+	if newState == nil {
+		err := stub.DelState(key)
+		if err != nil {
+			return errors.New("DELSTATE failed for asset registry entry! : " + fmt.Sprint(err))
+		}
+		return nil
+	}
+	err := stub.PutState(key, []byte(assetID))
+	if err != nil {
+		return errors.New("PUT ledger state failed for asset registry entry: " + fmt.Sprint(err))
+	}
+	stateJSON, err := json.Marshal(newState)
+	if err != nil {
+		return errors.New("Marshal failed for asset history" + fmt.Sprint(err))
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return err
+	}
+	entryJSON, err := json.Marshal(SnapshotRecord{Record: SNAPSHOTHISTORY, Timestamp: formatTime(now), TxID: stub.GetTxID(), State: stateJSON})
+	if err != nil {
+		return errors.New("Marshal failed for asset history" + fmt.Sprint(err))
+	}
+	err = stub.PutState(compositeKey(ASSETHISTORYKEYPREFIX, assetID, timeKey(now), stub.GetTxID()), entryJSON)
+	if err != nil {
+		return errors.New("PUT ledger state failed for asset history: " + fmt.Sprint(err))
+	}
+	return nil
+}
+
+// backfillAssetRegistry - lists the assets on the ledger while the registry is empty, as after an
+// upgrade from a version without it. Their history starts with their next update.
+func (t *SimpleChaincode) backfillAssetRegistry(stub shim.ChaincodeStubInterface) error {
+	startKey, endKey := compositeRange(ASSETKEYPREFIX)
+	assetIDs, err := t.getAssetIDs(stub, startKey, endKey, 1)
+	if err != nil || len(assetIDs) > 0 {
+		return err
+	}
+	assets, err := t.scanLedgerAssets(stub)
+	if err != nil {
+		return err
+	}
+	for _, state := range assets {
+		err = stub.PutState(compositeKey(ASSETKEYPREFIX, *state.AssetID), []byte(*state.AssetID))
+		if err != nil {
+			return errors.New("PUT ledger state failed for asset registry entry: " + fmt.Sprint(err))
+		}
+	}
+	return nil
+}
+
+// getRegisteredAssets - the assets listed in the registry, leaving out entries whose asset is gone
+func (t *SimpleChaincode) getRegisteredAssets(stub shim.ChaincodeStubInterface) ([]AssetState, error) {
+	var assets []AssetState
+	startKey, endKey := compositeRange(ASSETKEYPREFIX)
+	assetIDs, err := t.getAssetIDs(stub, startKey, endKey, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, assetID := range assetIDs {
+		state, ok := t.getRegisteredAsset(stub, assetID)
+		if ok {
+			assets = append(assets, state)
+		}
+	}
+	return assets, nil
+}
+
+// getRegisteredAsset - the state of a registered asset, false when the entry is stale
+func (t *SimpleChaincode) getRegisteredAsset(stub shim.ChaincodeStubInterface, assetID string) (AssetState, bool) {
+	var state AssetState
+	assetBytes, err := stub.GetState(assetID)
+	if err != nil || json.Unmarshal(assetBytes, &state) != nil || state.AssetID == nil || *state.AssetID != assetID {
+		return state, false
+	}
+	return state, true
+}
+
+// scanLedgerAssets - every asset in the ledger, found by scanning all of it rather than through
+// the registry. Assets are the keys that are neither composite nor contract records.
+func (t *SimpleChaincode) scanLedgerAssets(stub shim.ChaincodeStubInterface) ([]AssetState, error) {
+	var assets []AssetState
+	iter, err := stub.RangeQueryState("", "\xff")
+	if err != nil {
+		return nil, errors.New("Unable to read assets from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		var state AssetState
+		assetID, assetBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read assets from ledger: " + fmt.Sprint(err))
+		}
+		if strings.Contains(assetID, KEYSEPARATOR) || assetID == CONTRACTSTATEKEY {
+			continue
+		}
+		if json.Unmarshal(assetBytes, &state) != nil || state.AssetID == nil || *state.AssetID != assetID {
+			continue
+		}
+		assets = append(assets, state)
+	}
+	return assets, nil
+}
+
+// getAssetIDs - up to limit assetIDs of the registry between startKey and endKey, in order, all when limit is 0
+func (t *SimpleChaincode) getAssetIDs(stub shim.ChaincodeStubInterface, startKey string, endKey string, limit int) ([]string, error) {
+	var assetIDs []string
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return nil, errors.New("Unable to read asset registry from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() && (limit == 0 || len(assetIDs) < limit) {
+		_, assetIDBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read asset registry from ledger: " + fmt.Sprint(err))
+		}
+		assetIDs = append(assetIDs, string(assetIDBytes))
+	}
+	return assetIDs, nil
+}
+
+// getAssetHistory - up to limit past states of an asset from start on, oldest first. start and the
+// position returned for the record after the last one read, empty when none is left, are the time
+// key and txID of a history record joined by a slash.
+func (t *SimpleChaincode) getAssetHistory(stub shim.ChaincodeStubInterface, assetID string, start string, limit int) ([]SnapshotRecord, string, error) {
+	var history []SnapshotRecord
+	prefix, endKey := compositeRange(ASSETHISTORYKEYPREFIX, assetID)
+	startKey := prefix + strings.Replace(start, "/", KEYSEPARATOR, 1)
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return nil, "", errors.New("Unable to read asset history from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		var entry SnapshotRecord
+		key, entryBytes, err := iter.Next()
+		if err != nil {
+			return nil, "", errors.New("Unable to read asset history from ledger: " + fmt.Sprint(err))
+		}
+		if len(history) == limit {
+			return history, strings.Replace(strings.TrimPrefix(key, prefix), KEYSEPARATOR, "/", 1), nil
+		}
+		err = json.Unmarshal(entryBytes, &entry)
+		if err != nil {
+			return nil, "", errors.New("Unable to unmarshal asset history obtained from ledger")
+		}
+		history = append(history, entry)
+	}
+	return history, "", nil
+}
+
+/*********************************  internal: snapshot formats ****************************/
+
+func writeSnapshotNDJSON(w io.Writer, header SnapshotHeader, records []SnapshotRecord) error {
+	encoder := json.NewEncoder(w)
+	err := encoder.Encode(header)
+	for i := 0; err == nil && i < len(records); i++ {
+		err = encoder.Encode(records[i])
+	}
+	return err
+}
+
+func readSnapshotNDJSON(r io.Reader) (SnapshotHeader, []SnapshotRecord, error) {
+	var header SnapshotHeader
+	var records []SnapshotRecord
+	decoder := json.NewDecoder(r)
+	err := decoder.Decode(&header)
+	if err != nil {
+		return header, nil, errors.New("Unable to read snapshot header: " + fmt.Sprint(err))
+	}
+	for decoder.More() {
+		var record SnapshotRecord
+		err = decoder.Decode(&record)
+		if err != nil {
+			return header, nil, errors.New("Unable to read snapshot record: " + fmt.Sprint(err))
+		}
+		records = append(records, record)
+	}
+	return header, records, nil
+}
+
+// writeSnapshotCSV - a # comment with the JSON header, then a row per record with the record
+// type, timestamp and txID followed by the asset state fields. Lists are joined with ;
+func writeSnapshotCSV(w io.Writer, header SnapshotHeader, records []SnapshotRecord) error {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "# %s\n", headerJSON)
+	if err != nil {
+		return err
+	}
+	columns := stateColumns(reflect.TypeOf(AssetState{}), "")
+	writer := csv.NewWriter(w)
+	err = writer.Write(append([]string{"record", "timestamp", "txID"}, columns...))
+	for i := 0; err == nil && i < len(records); i++ {
+		var state map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(records[i].State))
+		decoder.UseNumber()
+		err = decoder.Decode(&state)
+		if err != nil {
+			return err
+		}
+		values := map[string]string{}
+		flattenState(state, "", values)
+		row := []string{records[i].Record, records[i].Timestamp, records[i].TxID}
+		for _, column := range columns {
+			row = append(row, values[column])
+		}
+		err = writer.Write(row)
+	}
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// stateColumns - dotted JSON names of the fields of a state struct, in declaration order
+func stateColumns(stateType reflect.Type, prefix string) []string {
+	var columns []string
+	for i := 0; i < stateType.NumField(); i++ {
+		field := stateType.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct {
+			columns = append(columns, stateColumns(fieldType, prefix+name+".")...)
+		} else {
+			columns = append(columns, prefix+name)
+		}
+	}
+	return columns
+}
+
+// flattenState - the values of a decoded state keyed by dotted name
+func flattenState(state map[string]interface{}, prefix string, values map[string]string) {
+	for name, value := range state {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flattenState(v, prefix+name+".", values)
+		case []interface{}:
+			var items []string
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[prefix+name] = strings.Join(items, ";")
+		case nil:
+		default:
+			values[prefix+name] = fmt.Sprint(v)
+		}
+	}
+}
+
+// queryPeer - runs a chaincode query through the JSON-RPC endpoint of a peer's REST API
+func queryPeer(peer string, chaincode string, secureContext string, function string, arg string) ([]byte, error) {
+	var response struct {
+		Result *struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+			Data    string `json:"data"`
+		} `json:"error"`
+	}
+	params := map[string]interface{}{
+		"type":        1,
+		"chaincodeID": map[string]string{"name": chaincode},
+		"ctorMsg":     map[string]interface{}{"function": function, "args": []string{arg}},
+	}
+	if secureContext != "" {
+		params["secureContext"] = secureContext
+	}
+	requestJSON, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "method": "query", "params": params, "id": 1})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.Post(strings.TrimSuffix(peer, "/")+"/chaincode", "application/json", bytes.NewReader(requestJSON))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, errors.New("Unexpected response from peer: " + string(body))
+	}
+	if response.Error != nil {
+		return nil, errors.New("Query failed: " + response.Error.Message + " " + response.Error.Data)
+	}
+	if response.Result == nil {
+		return nil, errors.New("Unexpected response from peer: " + string(body))
+	}
+	return []byte(response.Result.Message), nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// exportSnapshot - the header and records of an NDJSON exportAssets page
+func (s *testStub) exportSnapshot(t *testing.T, arg string) (SnapshotHeader, []SnapshotRecord) {
+	page, err := s.query("exportAssets", arg)
+	if err != nil {
+		t.Fatalf("exportAssets %s failed: %v", arg, err)
+	}
+	header, records, err := readSnapshotNDJSON(bytes.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return header, records
+}
+
+func TestExportAssets(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","floor":1}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E2","system":{"cpu":20}}`)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","floor":2}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E3"}`)
+
+	header, records := stub.exportSnapshot(t, `{"history":true,"limit":4}`)
+	if header.ContractVersion != MYVERSION || header.Assets != 2 || header.Next != "E2" || !strings.HasSuffix(header.NextHistory, "/tx3") || !header.History {
+		t.Fatalf("unexpected header %+v", header)
+	}
+	// each asset is followed by its past states, the limit counts them too
+	if len(records) != 4 || records[0].Record != SNAPSHOTASSET || records[1].Record != SNAPSHOTHISTORY || records[1].TxID != "tx2" ||
+		records[2].TxID != "tx4" || records[3].Record != SNAPSHOTASSET {
+		t.Fatalf("unexpected records %+v", records)
+	}
+	// the next page resumes the history of E2
+	header, records = stub.exportSnapshot(t, `{"history":true,"limit":4,"start":"E2","historyStart":"`+header.NextHistory+`"}`)
+	if header.Assets != 1 || header.Next != "" || header.NextHistory != "" || len(records) != 3 ||
+		records[0].TxID != "tx3" || records[1].Record != SNAPSHOTASSET || records[2].TxID != "tx5" {
+		t.Fatalf("unexpected resumed page %+v %+v", header, records)
+	}
+	header, records = stub.exportSnapshot(t, `{"start":"E3"}`)
+	if header.Assets != 1 || header.Next != "" || len(records) != 1 {
+		t.Fatalf("unexpected last page %+v %+v", header, records)
+	}
+
+	page, err := stub.query("exportAssets", `{"format":"csv","limit":2}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(page)), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "# {") || !strings.HasPrefix(lines[1], "record,timestamp,txID,assetID,") ||
+		!strings.Contains(lines[1], ",system.cpu,") || !strings.HasPrefix(lines[3], "asset,,,E2,") {
+		t.Fatalf("unexpected CSV snapshot %s", page)
+	}
+}
+
+func TestExportAssetsCreatedBeforeTheRegistry(t *testing.T) {
+	stub := newTestStub(t)
+	// an asset written by an earlier contract version, with no registry entry
+	stub.transact(func() ([]byte, error) {
+		return nil, stub.PutState("E0", []byte(`{"assetID":"E0","floor":3}`))
+	})
+	header, _ := stub.exportSnapshot(t, "")
+	if header.Assets != 0 {
+		t.Fatalf("unexpected header %+v", header)
+	}
+	stub.mustInit(t, `{"version":"`+MYVERSION+`"}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+	header, records := stub.exportSnapshot(t, "")
+	if header.Assets != 2 || !strings.Contains(string(records[0].State), `"assetID":"E0"`) {
+		t.Fatalf("unexpected snapshot %+v %+v", header, records)
+	}
+
+	// once the registry lists assets, init no longer scans the ledger
+	stub.transact(func() ([]byte, error) {
+		return nil, stub.PutState("E9", []byte(`{"assetID":"E9"}`))
+	})
+	stub.mustInit(t, `{"version":"`+MYVERSION+`"}`)
+	if header, _ = stub.exportSnapshot(t, ""); header.Assets != 2 {
+		t.Fatalf("unexpected header after init %+v", header)
+	}
+}
+
+func TestExportHistoryRetention(t *testing.T) {
+	stub := newTestStub(t)
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "updateContractConfig", `{"retention":{"historyDays":1}}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","floor":1}`)
+	stub.now = stub.now.Add(time.Hour)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","floor":2}`)
+	stub.now = stub.now.Add(48 * time.Hour)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","floor":3}`)
+
+	// the states older than a day are removed with the update
+	_, records := stub.exportSnapshot(t, `{"history":true}`)
+	if len(records) != 2 || records[1].Timestamp != "2016-09-03T13:00:00Z" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+
+	// without a window history is kept for DEFAULTHISTORYDAYS
+	unset := newTestStub(t)
+	unset.mustInvoke(t, "createAsset", `{"assetID":"E1","floor":1}`)
+	unset.now = unset.now.AddDate(0, 0, DEFAULTHISTORYDAYS-1)
+	unset.mustInvoke(t, "updateAsset", `{"assetID":"E1","floor":2}`)
+	if _, kept := unset.exportSnapshot(t, `{"history":true}`); len(kept) != 3 {
+		t.Fatalf("unexpected records %+v", kept)
+	}
+	unset.now = unset.now.AddDate(0, 0, 2)
+	unset.mustInvoke(t, "updateAsset", `{"assetID":"E1","floor":3}`)
+	if _, pruned := unset.exportSnapshot(t, `{"history":true}`); len(pruned) != 3 || pruned[1].TxID != "tx3" {
+		t.Fatalf("unexpected records %+v", pruned)
+	}
+}
+
+func TestExportAssetsRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFailQuery(t, "exportAssets", `{"format":"xml"}`, "Invalid format")
+	stub.mustFailQuery(t, "exportAssets", `{"limit":1001}`, "Limit must be between 1 and 1000")
+	stub.mustFailQuery(t, "exportAssets", `{"start":"E\u00001"}`, "Start contains an invalid character")
+	stub.mustFailQuery(t, "exportAssets", `{"history":true,"historyStart":"20160901T120000.000000000Z/tx2"}`, "HistoryStart expects start and history")
+	stub.mustFailQuery(t, "exportAssets", `[]`, "Unable to unmarshal input JSON data")
+}
+
+func TestExportCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	header, _ := json.Marshal(SnapshotHeader{ContractVersion: MYVERSION, Assets: 1})
+	snapshot := string(header) + "\n" + `{"record":"asset","state":{"assetID":"E1","alarms":["overTemperature","doorFault"]}}` + "\n"
+	status := runExport([]string{"-format", "csv"}, strings.NewReader(snapshot), &stdout, &stderr)
+	if status != 0 || !strings.Contains(stdout.String(), "overTemperature;doorFault") {
+		t.Fatalf("unexpected export %d %s %s", status, stdout.String(), stderr.String())
+	}
+	stdout.Reset()
+	status = runExport([]string{"-format", "xml"}, strings.NewReader(snapshot), &stdout, &stderr)
+	if status != 2 {
+		t.Fatalf("expecting exit status 2, got %d", status)
+	}
+	status = runExport([]string{"-peer", "http://localhost:7050"}, strings.NewReader(""), &stdout, &stderr)
+	if status != 2 || !strings.Contains(stderr.String(), "expects -chaincode") {
+		t.Fatalf("unexpected result %d %s", status, stderr.String())
+	}
+}
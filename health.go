@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// HEALTHWEIGHTSKEYPREFIX - object type for the health score weights, a single record
+const HEALTHWEIGHTSKEYPREFIX string = "HEALTHWEIGHTS"
+
+// HEALTHWINDOWDAYS - alarms and anomalies within this many days count towards the health score
+const HEALTHWINDOWDAYS int = 30
+
+// DOORCYCLELIFE - door cycles after which door equipment is considered fully worn
+const DOORCYCLELIFE float64 = 1000000
+
+// health score components
+const (
+	HEALTHTEMPERATURE string = "temperature"
+	HEALTHSPEED       string = "speed"
+	HEALTHPOWER       string = "power"
+	HEALTHCPU         string = "cpu"
+	HEALTHMEMORY      string = "memory"
+	HEALTHUSAGE       string = "usage"
+	HEALTHALARMS      string = "alarms"
+)
+
+// HealthWeights - relative weight of each component in the health score
+type HealthWeights struct {
+	Temperature float64 `json:"temperature"` // temperature against the asset's maximum temperature
+	Speed       float64 `json:"speed"`       // speed reading flagged as anomalous
+	Power       float64 `json:"power"`       // power reading flagged as anomalous
+	CPU         float64 `json:"cpu"`         // controller cpu load above 50%
+	Memory      float64 `json:"memory"`      // controller memory use above 50%
+	Usage       float64 `json:"usage"`       // door cycles against DOORCYCLELIFE
+	Alarms      float64 `json:"alarms"`      // active alarms, alarm work orders and anomalies in the last HEALTHWINDOWDAYS
+}
+
+var defaultHealthWeights = HealthWeights{
+	Temperature: 20,
+	Speed:       10,
+	Power:       10,
+	CPU:         5,
+	Memory:      5,
+	Usage:       20,
+	Alarms:      30,
+}
+
+// HealthComponent - contribution of one component, a penalty of 0 is healthy and 1 is worst
+type HealthComponent struct {
+	Weight  float64 `json:"weight"`
+	Penalty float64 `json:"penalty"`
+}
+
+// AssetHealth - health score of an asset, 100 is best. Components without data are left out.
+type AssetHealth struct {
+	AssetID    string                     `json:"assetID"`
+	Score      float64                    `json:"score"`
+	Components map[string]HealthComponent `json:"components"`
+	Computed   string                     `json:"computed"`
+}
+
+//******************** setHealthWeights ********************/
+
+// setHealthWeights - admin only, replaces the weights of the health score components
+func (t *SimpleChaincode) setHealthWeights(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var weights HealthWeights
+	err := t.requireRole(stub, ROLEADMIN)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with health score weights")
+	}
+	err = json.Unmarshal([]byte(args[0]), &weights)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	all := []float64{weights.Temperature, weights.Speed, weights.Power, weights.CPU, weights.Memory, weights.Usage, weights.Alarms}
+	total := 0.0
+	for _, w := range all {
+		if w < 0 {
+			return nil, errors.New("Health score weights cannot be negative")
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, errors.New("At least one health score weight must be positive")
+	}
+	weightsJSON, err := json.Marshal(weights)
+	if err != nil {
+		return nil, errors.New("Marshal failed for health weights" + fmt.Sprint(err))
+	}
+	err = stub.PutState(compositeKey(HEALTHWEIGHTSKEYPREFIX), weightsJSON)
+	if err != nil {
+		return nil, errors.New("PUT ledger state failed for health weights: " + fmt.Sprint(err))
+	}
+	return nil, nil
+}
+
+//******************** readHealthWeights ********************/
+
+func (t *SimpleChaincode) readHealthWeights(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	weights, err := t.getHealthWeights(stub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(weights)
+}
+
+//******************** readAssetHealth ********************/
+
+func (t *SimpleChaincode) readAssetHealth(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var state AssetState
+	var usage AssetUsage
+
+	stateIn, err := t.validateInput(args)
+	if err != nil {
+		return nil, err
+	}
+	assetID := *stateIn.AssetID
+	assetBytes, err := stub.GetState(assetID)
+	if err != nil || len(assetBytes) == 0 {
+		return nil, errors.New("Unable to get asset state from ledger")
+	}
+	err = json.Unmarshal(assetBytes, &state)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal state data obtained from ledger")
+	}
+	usageBytes, err := stub.GetState(compositeKey(USAGEKEYPREFIX, assetID))
+	if err == nil && len(usageBytes) > 0 {
+		err = json.Unmarshal(usageBytes, &usage)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal usage data obtained from ledger")
+		}
+	}
+	weights, err := t.getHealthWeights(stub)
+	if err != nil {
+		return nil, err
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	since := now.AddDate(0, 0, -HEALTHWINDOWDAYS)
+
+	// recent alarm work orders, or the active alarms when those opened earlier, and anomalies
+	alarmOrders := 0
+	orders, err := t.getWorkOrders(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range orders {
+		opened, err := parseTime(order.Opened)
+		if err == nil && order.Source == WOSOURCEALARM && !opened.Before(since) {
+			alarmOrders++
+		}
+	}
+	anomalies, err := t.getAnomaliesSince(stub, assetID, since)
+	if err != nil {
+		return nil, err
+	}
+	recent := math.Max(float64(alarmOrders), float64(len(state.Alarms))) + float64(len(anomalies))
+
+	health := AssetHealth{AssetID: assetID, Components: map[string]HealthComponent{}, Computed: formatTime(now)}
+	add := func(name string, weight float64, penalty float64) {
+		health.Components[name] = HealthComponent{Weight: weight, Penalty: math.Max(0, math.Min(1, penalty))}
+	}
+	limits, err := t.getAssetLimits(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+	// the penalty is scaled by the maximum, which gives no range when it is not positive
+	if state.Temperature != nil && limits.MaxTemperature != nil && *limits.MaxTemperature > 0 {
+		maxTemperature := *limits.MaxTemperature
+		add(HEALTHTEMPERATURE, weights.Temperature, (*state.Temperature-0.8*maxTemperature)/(0.2*maxTemperature))
+	}
+	if state.Speed != nil {
+		add(HEALTHSPEED, weights.Speed, flagPenalty(state.Anomalies, FIELDSPEED))
+	}
+	if state.Power != nil {
+		add(HEALTHPOWER, weights.Power, flagPenalty(state.Anomalies, FIELDPOWER))
+	}
+	if state.System != nil && state.System.CPU != nil {
+		add(HEALTHCPU, weights.CPU, (*state.System.CPU-50)/50)
+	}
+	if state.System != nil && state.System.Memory != nil {
+		add(HEALTHMEMORY, weights.Memory, (*state.System.Memory-50)/50)
+	}
+	if usage.AssetID != "" {
+		add(HEALTHUSAGE, weights.Usage, float64(usage.DoorCycles)/DOORCYCLELIFE)
+	}
+	// three alarms or anomalies in the window count as worst
+	add(HEALTHALARMS, weights.Alarms, recent/3)
+
+	total, penalty := 0.0, 0.0
+	for _, component := range health.Components {
+		total += component.Weight
+		penalty += component.Weight * component.Penalty
+	}
+	health.Score = 100
+	if total > 0 {
+		health.Score = math.Round(1000*(1-penalty/total)) / 10
+	}
+	return json.Marshal(health)
+}
+
+/*********************************  internal: health ****************************/
+
+// flagPenalty - worst when the field is flagged
+func flagPenalty(flagged []string, field string) float64 {
+	if isOneOf(field, flagged...) {
+		return 1
+	}
+	return 0
+}
+
+// getHealthWeights - the weights stored on the ledger, or the defaults
+func (t *SimpleChaincode) getHealthWeights(stub shim.ChaincodeStubInterface) (HealthWeights, error) {
+	var weights HealthWeights
+	weightsBytes, err := stub.GetState(compositeKey(HEALTHWEIGHTSKEYPREFIX))
+	if err != nil || len(weightsBytes) == 0 {
+		return defaultHealthWeights, nil
+	}
+	err = json.Unmarshal(weightsBytes, &weights)
+	if err != nil {
+		return weights, errors.New("Unable to unmarshal health weights obtained from ledger")
+	}
+	return weights, nil
+}
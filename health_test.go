@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestAssetHealth(t *testing.T) {
+	var health AssetHealth
+	var weights HealthWeights
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","system":{"cpu":75,"memory":20}}`)
+	stub.mustQuery(t, "readAssetHealth", `{"assetID":"E1"}`, &health)
+	if health.Score != 95.8 || health.Components[HEALTHCPU].Penalty != 0.5 || health.Components[HEALTHTEMPERATURE] != (HealthComponent{}) {
+		t.Fatalf("unexpected health %+v", health)
+	}
+
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "setHealthWeights", `{"cpu":1,"alarms":1}`)
+	stub.mustQuery(t, "readHealthWeights", "", &weights)
+	if weights != (HealthWeights{CPU: 1, Alarms: 1}) {
+		t.Fatalf("unexpected weights %+v", weights)
+	}
+	stub.mustQuery(t, "readAssetHealth", `{"assetID":"E1"}`, &health)
+	if health.Score != 75 {
+		t.Fatalf("unexpected health %+v", health)
+	}
+}
+
+func TestAssetHealthWithoutTemperatureRange(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","temperature":70}`)
+	stub.as(ROLEADMIN, "")
+	for _, maxTemperature := range []string{"0", "-10"} {
+		var health AssetHealth
+		stub.mustInvoke(t, "setNameplate", `{"assetID":"E1","maxTemperature":`+maxTemperature+`}`)
+		stub.mustQuery(t, "readAssetHealth", `{"assetID":"E1"}`, &health)
+		if _, ok := health.Components[HEALTHTEMPERATURE]; ok || health.Score <= 0 || health.Score > 100 {
+			t.Fatalf("unexpected health for maximum %s: %+v", maxTemperature, health)
+		}
+	}
+}
+
+func TestHealthWeightsKeptApartFromAssets(t *testing.T) {
+	var weights HealthWeights
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"HealthWeightsKey"}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"HEALTHWEIGHTS"}`)
+	stub.mustQuery(t, "readHealthWeights", "", &weights)
+	if weights != defaultHealthWeights {
+		t.Fatalf("unexpected weights %+v", weights)
+	}
+}
+
+func TestAssetHealthRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFail(t, "setHealthWeights", `{"cpu":1}`, "not allowed")
+	stub.as(ROLEADMIN, "")
+	stub.mustFail(t, "setHealthWeights", `{"cpu":-1,"alarms":2}`, "cannot be negative")
+	stub.mustFail(t, "setHealthWeights", `{}`, "At least one health score weight must be positive")
+	stub.mustFail(t, "setHealthWeights", `[]`, "Unable to unmarshal input JSON data")
+	stub.mustFailQuery(t, "readAssetHealth", `{"assetID":"E1"}`, "Unable to get asset state")
+}
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// IMPORTBATCHSIZE - default number of rows per invocation produced by the import command
+const IMPORTBATCHSIZE int = 50
+
+// import file formats
+const (
+	IMPORTCSV    string = "csv"
+	IMPORTNDJSON string = "ndjson"
+)
+
+// Invocation - a chaincode function and its arguments, as passed in the ctorMsg of an invoke
+type Invocation struct {
+	Function string   `json:"function"`
+	Args     []string `json:"args"`
+}
+
+// importRow - one asset read by the import command. Row is the line in the file, for CSV
+// counting the header as row 1. Err is set when the row cannot be imported.
+type importRow struct {
+	Row     int
+	AssetID string
+	State   []byte
+	Err     error
+}
+
+// jsonSchema - the subset of JSON schema used by schemas, enough to validate asset states
+type jsonSchema struct {
+	Type       string                 `json:"type"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Items      *jsonSchema            `json:"items"`
+	Enum       []interface{}          `json:"enum"`
+	Required   []string               `json:"required"`
+}
+
+//******************** import command ********************/
+
+// runImport - offline onboarding of a fleet, run as
+//
+//	elevator-contract-simple import [-format csv|ndjson] [-batch n] [-update] [-mockstub] [file]
+//
+// Reads asset states from a CSV file with a header of field names, nested fields written as
+// system.cpu, or from an NDJSON file with one partial state per line, and validates each row
+// against the createAsset schema. Writes one invocation per line to stdout, a batchCreateAssets
+// per batch, which fails when an asset already exists. With -update rows are merged into existing
+// assets instead, through a createAsset per row when the batch size is 1 and a batchUpdateAssets
+// per batch otherwise. With -mockstub each row is first created on an in-memory stub, in a
+// transaction of its own, and rows the contract rejects are left out. Failed rows are reported on
+// stderr, the exit status is 1 when there were any.
+func runImport(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "", "input format, csv or ndjson. Defaults to the file extension")
+	batchSize := flags.Int("batch", IMPORTBATCHSIZE, "rows per invocation")
+	update := flags.Bool("update", false, "merge rows into assets that already exist instead of failing the batch")
+	mockStub := flags.Bool("mockstub", false, "create the assets on an in-memory stub and leave out rows it rejects")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *batchSize < 1 || *batchSize > BATCHMAXITEMS {
+		fmt.Fprintf(stderr, "batch must be between 1 and %d\n", BATCHMAXITEMS)
+		return 2
+	}
+	in := stdin
+	if flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "import expects at most one file")
+		return 2
+	} else if flags.NArg() == 1 && flags.Arg(0) != "-" {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		defer file.Close()
+		in = file
+		if *format == "" {
+			*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(flags.Arg(0))), ".")
+			if *format == "jsonl" {
+				*format = IMPORTNDJSON
+			}
+		}
+	}
+	schema, err := assetStateSchema()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	var rows []importRow
+	if *format == IMPORTCSV {
+		rows, err = readCSVRows(in, schema)
+	} else if *format == IMPORTNDJSON {
+		rows, err = readNDJSONRows(in, schema)
+	} else {
+		err = errors.New("Unknown import format, expecting csv or ndjson: " + *format)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	// an asset appearing twice would be merged into one by the contract
+	seen := map[string]int{}
+	for i := range rows {
+		if rows[i].Err != nil {
+			continue
+		}
+		if first, ok := seen[rows[i].AssetID]; ok {
+			rows[i].Err = fmt.Errorf("AssetID already imported in row %d", first)
+			continue
+		}
+		seen[rows[i].AssetID] = rows[i].Row
+	}
+	if *mockStub {
+		stub := &importStub{MockStub: shim.NewMockStub("elevator", new(SimpleChaincode)), now: time.Now().UTC()}
+		initJSON, _ := json.Marshal(ContractState{Version: MYVERSION})
+		_, err = mockTransact(stub.MockStub, "import", func() ([]byte, error) {
+			return new(SimpleChaincode).Init(stub, "init", []string{string(initJSON)})
+		})
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		for i := range rows {
+			if rows[i].Err == nil {
+				args := []string{string(rows[i].State)}
+				_, rows[i].Err = mockTransact(stub.MockStub, "import"+strconv.Itoa(rows[i].Row), func() ([]byte, error) {
+					return new(SimpleChaincode).Invoke(stub, "createAsset", args)
+				})
+			}
+		}
+	}
+
+	failed, imported, invocations := 0, 0, 0
+	var batch [][]byte
+	out := bufio.NewWriter(stdout)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		invocation := Invocation{Function: "batchCreateAssets", Args: []string{"[" + string(bytes.Join(batch, []byte(","))) + "]"}}
+		if *update && *batchSize == 1 {
+			invocation = Invocation{Function: "createAsset", Args: []string{string(batch[0])}}
+		} else if *update {
+			invocation.Function = "batchUpdateAssets"
+		}
+		invocationJSON, err := json.Marshal(invocation)
+		if err != nil {
+			return err
+		}
+		invocations++
+		batch = nil
+		_, err = fmt.Fprintf(out, "%s\n", invocationJSON)
+		return err
+	}
+	for _, row := range rows {
+		if row.Err != nil {
+			failed++
+			fmt.Fprintf(stderr, "row %d %s: %v\n", row.Row, row.AssetID, row.Err)
+			continue
+		}
+		imported++
+		batch = append(batch, row.State)
+		if len(batch) == *batchSize {
+			if err = flush(); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = flush()
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	fmt.Fprintf(stderr, "%d rows imported in %d invocations, %d rows failed\n", imported, invocations, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+/*********************************  internal: import ****************************/
+
+// importStub - the in-memory stub of -mockstub. The mock stub has no transaction timestamp, so
+// every row is created at the time the import started.
+type importStub struct {
+	*shim.MockStub
+	now time.Time
+}
+
+func (s *importStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return &timestamp.Timestamp{Seconds: s.now.Unix(), Nanos: int32(s.now.Nanosecond())}, nil
+}
+
+// mockTransact - runs fn as a transaction on an in-memory stub and undoes its writes when it fails,
+// as a peer discards the writes of a failed transaction
+func mockTransact(stub *shim.MockStub, txID string, fn func() ([]byte, error)) ([]byte, error) {
+	before := map[string][]byte{}
+	for key, value := range stub.State {
+		before[key] = value
+	}
+	stub.MockTransactionStart(txID)
+	defer stub.MockTransactionEnd(txID)
+	result, err := fn()
+	if err != nil {
+		var written []string
+		for key := range stub.State {
+			written = append(written, key)
+		}
+		for _, key := range written {
+			if _, found := before[key]; !found {
+				stub.DelState(key)
+			}
+		}
+		for key, value := range before {
+			if string(stub.State[key]) != string(value) {
+				stub.PutState(key, value)
+			}
+		}
+	}
+	return result, err
+}
+
+// readCSVRows - rows of a CSV file whose header names the asset state fields
+func readCSVRows(in io.Reader, schema *jsonSchema) ([]importRow, error) {
+	var rows []importRow
+	reader := csv.NewReader(in)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("Unable to read CSV header: " + fmt.Sprint(err))
+	}
+	fields := make([][]string, len(header))
+	types := make([]string, len(header))
+	for i, column := range header {
+		fields[i] = strings.Split(strings.TrimSpace(column), ".")
+		field := schema
+		for _, name := range fields[i] {
+			if field.Properties[name] == nil {
+				return nil, errors.New("Column is not an asset state field: " + column)
+			}
+			field = field.Properties[name]
+		}
+		types[i] = field.Type
+	}
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			rows = append(rows, importRow{Row: row, Err: err})
+			if _, ok := err.(*csv.ParseError); ok {
+				continue
+			}
+			return rows, nil
+		}
+		state := map[string]interface{}{}
+		for i, cell := range record {
+			cell = strings.TrimSpace(cell)
+			if cell == "" {
+				continue
+			}
+			// nested fields such as system.cpu go in nested objects
+			object := state
+			for _, name := range fields[i][:len(fields[i])-1] {
+				if _, ok := object[name].(map[string]interface{}); !ok {
+					object[name] = map[string]interface{}{}
+				}
+				object = object[name].(map[string]interface{})
+			}
+			var value interface{} = cell
+			if types[i] == "number" || types[i] == "integer" {
+				value = json.Number(cell)
+			} else if b, err := strconv.ParseBool(cell); types[i] == "boolean" && err == nil {
+				value = b
+			}
+			object[fields[i][len(fields[i])-1]] = value
+		}
+		rows = append(rows, newImportRow(row, state, schema))
+	}
+}
+
+// readNDJSONRows - rows of a file with a JSON object per line, blank lines are skipped
+func readNDJSONRows(in io.Reader, schema *jsonSchema) ([]importRow, error) {
+	var rows []importRow
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for row := 1; scanner.Scan(); row++ {
+		var state interface{}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		decoder := json.NewDecoder(strings.NewReader(line))
+		decoder.UseNumber()
+		err := decoder.Decode(&state)
+		if err != nil {
+			rows = append(rows, importRow{Row: row, Err: errors.New("Unable to unmarshal input JSON data")})
+			continue
+		}
+		rows = append(rows, newImportRow(row, state, schema))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("Unable to read NDJSON input: " + fmt.Sprint(err))
+	}
+	return rows, nil
+}
+
+// newImportRow - validates a decoded state against the schema and the contract's input checks
+func newImportRow(row int, state interface{}, schema *jsonSchema) importRow {
+	result := importRow{Row: row}
+	if object, ok := state.(map[string]interface{}); ok {
+		if assetID, ok := object["assetID"].(string); ok {
+			result.AssetID = assetID
+		}
+	}
+	result.Err = validateSchema(state, schema, "")
+	if result.Err != nil {
+		return result
+	}
+	result.State, result.Err = json.Marshal(state)
+	if result.Err != nil {
+		return result
+	}
+	stateIn, err := new(SimpleChaincode).validateInput([]string{string(result.State)})
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.AssetID = *stateIn.AssetID
+	return result
+}
+
+// validateSchema - checks types, enums, required and unknown properties of a decoded JSON value,
+// numbers are expected as json.Number
+func validateSchema(value interface{}, schema *jsonSchema, path string) error {
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return errors.New(strings.TrimSpace("Expecting a JSON object " + path))
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				return errors.New(strings.TrimPrefix(path+"."+name, ".") + " is required")
+			}
+		}
+		for name, property := range object {
+			if schema.Properties[name] == nil {
+				return errors.New(strings.TrimPrefix(path+"."+name, ".") + " is not an asset state field")
+			}
+			err := validateSchema(property, schema.Properties[name], strings.TrimPrefix(path+"."+name, "."))
+			if err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return errors.New(path + " must be an array")
+		}
+		for i, item := range array {
+			if schema.Items != nil {
+				err := validateSchema(item, schema.Items, path+"["+strconv.Itoa(i)+"]")
+				if err != nil {
+					return err
+				}
+			}
+		}
+	case "number", "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return errors.New(path + " must be of type " + schema.Type)
+		}
+		_, err := number.Float64()
+		if schema.Type == "integer" {
+			_, err = number.Int64()
+		}
+		if err != nil {
+			return errors.New(path + " must be of type " + schema.Type + ": " + number.String())
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return errors.New(path + " must be a boolean")
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return errors.New(path + " must be a string")
+		}
+	}
+	if len(schema.Enum) > 0 {
+		for _, allowed := range schema.Enum {
+			if allowed == value {
+				return nil
+			}
+		}
+		return errors.New(path + " is not one of the allowed values: " + fmt.Sprint(value))
+	}
+	return nil
+}
+
+// assetStateSchema - schema of the createAsset argument
+func assetStateSchema() (*jsonSchema, error) {
+	var api struct {
+		API map[string]struct {
+			Properties struct {
+				Args jsonSchema `json:"args"`
+			} `json:"properties"`
+		} `json:"API"`
+	}
+	err := json.Unmarshal([]byte(schemas), &api)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal schemas: " + fmt.Sprint(err))
+	}
+	args := api.API["createAsset"].Properties.Args
+	if args.Items == nil {
+		return nil, errors.New("Schemas do not describe createAsset")
+	}
+	return args.Items, nil
+}
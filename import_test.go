@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func runImportTest(args []string, input string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	status := runImport(args, strings.NewReader(input), &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestImportCSV(t *testing.T) {
+	var invocation Invocation
+	var states []AssetState
+	input := "assetID, building, system.cpu, direction\nE1,B1,20,up\nE2,B1,,stopped\nE3,B2,high,\nE1,B3,,\n"
+	status, stdout, stderr := runImportTest([]string{"-format", "csv"}, input)
+	if status != 1 {
+		t.Fatalf("expecting exit status 1, got %d: %s", status, stderr)
+	}
+	if !strings.Contains(stderr, "row 4 E3: system.cpu must be of type number") || !strings.Contains(stderr, "row 5 E1: AssetID already imported in row 2") ||
+		!strings.Contains(stderr, "2 rows imported in 1 invocations, 2 rows failed") {
+		t.Fatalf("unexpected report %s", stderr)
+	}
+	err := json.Unmarshal([]byte(stdout), &invocation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invocation.Function != "batchCreateAssets" || json.Unmarshal([]byte(invocation.Args[0]), &states) != nil || len(states) != 2 {
+		t.Fatalf("unexpected invocation %+v", invocation)
+	}
+	if *states[0].System.CPU != 20 || *states[0].Direction != "up" || states[1].System != nil {
+		t.Fatalf("unexpected states %s", invocation.Args[0])
+	}
+	// merging into existing assets has to be asked for
+	_, stdout, _ = runImportTest([]string{"-format", "csv", "-update"}, input)
+	if json.Unmarshal([]byte(stdout), &invocation) != nil || invocation.Function != "batchUpdateAssets" {
+		t.Fatalf("unexpected invocation %s", stdout)
+	}
+}
+
+func TestImportNDJSON(t *testing.T) {
+	input := `{"assetID":"E1","floor":1}` + "\n\n" + `{"assetID":"E2","direction":"up","doorStatus":"open"}` + "\n" + `{"assetID":"E3"}` + "\n"
+	// one single item batch per row
+	status, stdout, stderr := runImportTest([]string{"-format", "ndjson", "-batch", "1"}, input)
+	if status != 0 || strings.Count(stdout, `"function":"batchCreateAssets"`) != 3 {
+		t.Fatalf("unexpected import %d %s %s", status, stdout, stderr)
+	}
+	// one createAsset per row when updating
+	status, stdout, stderr = runImportTest([]string{"-format", "ndjson", "-batch", "1", "-update"}, input)
+	if status != 0 || strings.Count(stdout, `"function":"createAsset"`) != 3 {
+		t.Fatalf("unexpected import %d %s %s", status, stdout, stderr)
+	}
+	// the contract rejects moving with open doors
+	status, stdout, stderr = runImportTest([]string{"-format", "ndjson", "-batch", "1", "-mockstub"}, input)
+	if status != 1 || strings.Count(stdout, `"function":"batchCreateAssets"`) != 2 || !strings.Contains(stderr, "row 3 E2: Elevator cannot be moving while doors are open") {
+		t.Fatalf("unexpected import %d %s %s", status, stdout, stderr)
+	}
+}
+
+func TestMockTransact(t *testing.T) {
+	stub := shim.NewMockStub("elevator", new(SimpleChaincode))
+	stub.State["kept"] = []byte("before")
+	_, err := mockTransact(stub, "tx1", func() ([]byte, error) {
+		stub.PutState("kept", []byte("after"))
+		stub.PutState("added", []byte("after"))
+		return nil, errors.New("failed")
+	})
+	if err == nil || string(stub.State["kept"]) != "before" || stub.State["added"] != nil {
+		t.Fatalf("expecting the writes to be undone, got %v %q", err, stub.State)
+	}
+}
+
+func TestImportRejected(t *testing.T) {
+	status, _, stderr := runImportTest([]string{"-format", "xml"}, "")
+	if status != 2 || !strings.Contains(stderr, "Unknown import format") {
+		t.Fatalf("unexpected result %d %s", status, stderr)
+	}
+	status, _, stderr = runImportTest([]string{"-format", "csv", "-batch", "101"}, "")
+	if status != 2 || !strings.Contains(stderr, "batch must be between 1 and 100") {
+		t.Fatalf("unexpected result %d %s", status, stderr)
+	}
+	status, _, stderr = runImportTest([]string{"-format", "csv"}, "assetID,colour\n")
+	if status != 2 || !strings.Contains(stderr, "Column is not an asset state field: colour") {
+		t.Fatalf("unexpected result %d %s", status, stderr)
+	}
+	status, _, stderr = runImportTest([]string{"-format", "ndjson"}, `{"assetID":"E1","colour":"red"}`+"\n"+`{"floor":1}`+"\nnot json\n")
+	if status != 1 || !strings.Contains(stderr, "row 1 E1: colour is not an asset state field") || !strings.Contains(stderr, "row 2 : assetID is required") ||
+		!strings.Contains(stderr, "row 3 : Unable to unmarshal input JSON data") {
+		t.Fatalf("unexpected result %d %s", status, stderr)
+	}
+}
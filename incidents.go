@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// INCIDENTKEYPREFIX - object type for incident records, keyed by assetID and incidentID
+const INCIDENTKEYPREFIX string = "INCIDENT"
+
+// INCIDENTBUILDINGKEYPREFIX - object type for the building entries of incidents, keyed by building,
+// start time, assetID and incidentID. The value is the key of the incident.
+const INCIDENTBUILDINGKEYPREFIX string = "INCIDENTBUILDING"
+
+// INCIDENTEVENT - name of the event emitted when an incident is reported or updated
+const INCIDENTEVENT string = "incident"
+
+// incident types
+const (
+	INCIDENTENTRAPMENT    string = "entrapment"
+	INCIDENTALARMBUTTON   string = "alarmButton"
+	INCIDENTEMERGENCYSTOP string = "emergencyStop"
+	INCIDENTFIRERECALL    string = "fireServiceRecall"
+)
+
+// incident severities
+const (
+	SEVERITYLOW      string = "low"
+	SEVERITYMEDIUM   string = "medium"
+	SEVERITYHIGH     string = "high"
+	SEVERITYCRITICAL string = "critical"
+)
+
+// Incident - a reportable event on an asset such as an entrapment
+type Incident struct {
+	IncidentID  string `json:"incidentID"`
+	AssetID     string `json:"assetID"`
+	Building    string `json:"building,omitempty"` // building of the asset when the incident was reported
+	Type        string `json:"type"`
+	Severity    string `json:"severity"`
+	Description string `json:"description,omitempty"`
+	Start       string `json:"start"`
+	End         string `json:"end,omitempty"` // empty while the incident is ongoing
+	Responder   string `json:"responder,omitempty"`
+	Resolution  string `json:"resolution,omitempty"`
+	Reported    string `json:"reported"` // transaction time of the first report
+	Updated     string `json:"updated"`
+}
+
+//******************** reportIncident ********************/
+
+// reportIncident - records a new incident, or updates the severity, description, end,
+// responder and resolution of a reported one. Type, start and asset cannot change.
+func (t *SimpleChaincode) reportIncident(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var event Incident
+	var incident Incident
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with mandatory assetID")
+	}
+	err := json.Unmarshal([]byte(args[0]), &event)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	event.AssetID = strings.TrimSpace(event.AssetID)
+	event.IncidentID = strings.TrimSpace(event.IncidentID)
+	if event.AssetID == "" || strings.Contains(event.AssetID+event.IncidentID, KEYSEPARATOR) {
+		return nil, errors.New("Asset id is mandatory in the input JSON data")
+	}
+	if event.Type != "" && !isOneOf(event.Type, INCIDENTENTRAPMENT, INCIDENTALARMBUTTON, INCIDENTEMERGENCYSTOP, INCIDENTFIRERECALL) {
+		return nil, errors.New("Invalid incident type: " + event.Type)
+	}
+	if event.Severity != "" && !isOneOf(event.Severity, SEVERITYLOW, SEVERITYMEDIUM, SEVERITYHIGH, SEVERITYCRITICAL) {
+		return nil, errors.New("Invalid incident severity: " + event.Severity)
+	}
+	tm, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	now := formatTime(tm)
+	if event.IncidentID == "" {
+		event.IncidentID = stub.GetTxID()
+	}
+	key := compositeKey(INCIDENTKEYPREFIX, event.AssetID, event.IncidentID)
+	incidentBytes, err := stub.GetState(key)
+	if err == nil && len(incidentBytes) > 0 {
+		// update of a reported incident
+		err = json.Unmarshal(incidentBytes, &incident)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal incident data obtained from ledger")
+		}
+		if event.Type != "" && event.Type != incident.Type {
+			return nil, errors.New("Type of a reported incident cannot change")
+		}
+		if event.Start != "" {
+			newStart, err := parseTime(event.Start)
+			oldStart, _ := parseTime(incident.Start)
+			if err != nil || !newStart.Equal(oldStart) {
+				return nil, errors.New("Start of a reported incident cannot change")
+			}
+		}
+		if event.Severity != "" {
+			incident.Severity = event.Severity
+		}
+		if event.Description != "" {
+			incident.Description = event.Description
+		}
+		if event.End != "" {
+			incident.End = event.End
+		}
+		if event.Responder != "" {
+			incident.Responder = event.Responder
+		}
+		if event.Resolution != "" {
+			incident.Resolution = event.Resolution
+		}
+	} else {
+		var state AssetState
+		assetBytes, err := stub.GetState(event.AssetID)
+		if err != nil || len(assetBytes) == 0 {
+			return nil, errors.New("Asset does not exist: " + event.AssetID)
+		}
+		err = json.Unmarshal(assetBytes, &state)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal state data obtained from ledger")
+		}
+		if event.Type == "" || event.Severity == "" {
+			return nil, errors.New("Type and severity are mandatory when reporting an incident")
+		}
+		incident = event
+		if incident.Start == "" {
+			incident.Start = now
+		}
+		if state.Building != nil {
+			incident.Building = *state.Building
+		}
+		incident.Reported = now
+	}
+	start, err := parseTime(incident.Start)
+	if err != nil {
+		return nil, errors.New("Invalid incident start: " + incident.Start)
+	}
+	if incident.End != "" {
+		end, err := parseTime(incident.End)
+		if err != nil {
+			return nil, errors.New("Invalid incident end: " + incident.End)
+		}
+		if end.Before(start) {
+			return nil, errors.New("Incident end cannot be before its start")
+		}
+		incident.End = formatTime(end)
+	}
+	incident.Start = formatTime(start)
+	incident.Updated = now
+
+	incidentJSON, err := json.Marshal(incident)
+	if err != nil {
+		return nil, errors.New("Marshal failed for incident" + fmt.Sprint(err))
+	}
+	err = stub.PutState(key, incidentJSON)
+	if err != nil {
+		return nil, errors.New("PUT ledger state failed for incident: " + fmt.Sprint(err))
+	}
+	if incident.Building != "" {
+		err = stub.PutState(compositeKey(INCIDENTBUILDINGKEYPREFIX, incident.Building, timeKey(start), incident.AssetID, incident.IncidentID), []byte(key))
+		if err != nil {
+			return nil, errors.New("PUT ledger state failed for incident building entry: " + fmt.Sprint(err))
+		}
+	}
+	err = stub.SetEvent(INCIDENTEVENT, incidentJSON)
+	if err != nil {
+		return nil, errors.New("Unable to set incident event: " + fmt.Sprint(err))
+	}
+	return nil, nil
+}
+
+//******************** readIncidents ********************/
+
+// readIncidents - lists the incidents of an asset, of a building, or of all assets when
+// neither is passed, that started in [from, to). Both ends of the range are optional.
+func (t *SimpleChaincode) readIncidents(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var query struct {
+		AssetID  string `json:"assetID"`
+		Building string `json:"building"`
+		From     string `json:"from"`
+		To       string `json:"to"`
+	}
+	var incidents = []Incident{}
+	var from, to time.Time
+	var startKey, endKey string
+
+	if len(args) > 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting an optional JSON string with assetID or building")
+	}
+	if len(args) == 1 {
+		err := json.Unmarshal([]byte(args[0]), &query)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal input JSON data")
+		}
+	}
+	query.AssetID = strings.TrimSpace(query.AssetID)
+	query.Building = strings.TrimSpace(query.Building)
+	if query.AssetID != "" && query.Building != "" {
+		return nil, errors.New("Expecting either an assetID or a building")
+	}
+	if strings.Contains(query.AssetID+query.Building, KEYSEPARATOR) {
+		return nil, errors.New("Input JSON data contains an invalid character")
+	}
+	if query.From != "" {
+		tm, err := parseTime(query.From)
+		if err != nil {
+			return nil, errors.New("Invalid from: " + query.From)
+		}
+		from = tm
+	}
+	if query.To != "" {
+		tm, err := parseTime(query.To)
+		if err != nil {
+			return nil, errors.New("Invalid to: " + query.To)
+		}
+		to = tm
+	}
+
+	if query.Building != "" {
+		// building entries are ordered by start time
+		startKey, endKey = compositeRange(INCIDENTBUILDINGKEYPREFIX, query.Building)
+		if !from.IsZero() {
+			startKey = compositeKey(INCIDENTBUILDINGKEYPREFIX, query.Building, timeKey(from))
+		}
+	} else if query.AssetID != "" {
+		startKey, endKey = compositeRange(INCIDENTKEYPREFIX, query.AssetID)
+	} else {
+		startKey, endKey = compositeRange(INCIDENTKEYPREFIX)
+	}
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return nil, errors.New("Unable to read incidents from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		var incident Incident
+		_, valueBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read incidents from ledger: " + fmt.Sprint(err))
+		}
+		incidentBytes := valueBytes
+		if query.Building != "" {
+			incidentBytes, err = stub.GetState(string(valueBytes))
+			if err != nil || len(incidentBytes) == 0 {
+				continue
+			}
+		}
+		err = json.Unmarshal(incidentBytes, &incident)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal incident data obtained from ledger")
+		}
+		start, err := parseTime(incident.Start)
+		if err != nil || (!from.IsZero() && start.Before(from)) || (!to.IsZero() && !start.Before(to)) {
+			continue
+		}
+		incidents = append(incidents, incident)
+	}
+	incidentsJSON, err := json.Marshal(incidents)
+	if err != nil {
+		return nil, errors.New("Marshal failed for incidents" + fmt.Sprint(err))
+	}
+	return incidentsJSON, nil
+}
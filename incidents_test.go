@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+func TestIncidents(t *testing.T) {
+	var incidents []Incident
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","building":"B1"}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E2"}`)
+	stub.mustInvoke(t, "reportIncident", `{"assetID":"E1","incidentID":"I1","type":"entrapment","severity":"high","start":"2016-09-01T11:30:00Z"}`)
+	if stub.event != INCIDENTEVENT {
+		t.Fatalf("expecting an incident event, got %q", stub.event)
+	}
+	stub.mustInvoke(t, "reportIncident", `{"assetID":"E1","incidentID":"I1","end":"2016-09-01T12:00:00Z","responder":"Sam","resolution":"Passengers released"}`)
+	stub.mustInvoke(t, "reportIncident", `{"assetID":"E2","type":"emergencyStop","severity":"low"}`)
+
+	stub.mustQuery(t, "readIncidents", `{"building":"B1","from":"2016-09-01"}`, &incidents)
+	if len(incidents) != 1 {
+		t.Fatalf("expecting one incident in the building, got %+v", incidents)
+	}
+	incident := incidents[0]
+	if incident.Building != "B1" || incident.Severity != SEVERITYHIGH || incident.End != "2016-09-01T12:00:00Z" ||
+		incident.Responder != "Sam" || incident.Reported != "2016-09-01T12:00:00Z" {
+		t.Fatalf("unexpected incident %+v", incident)
+	}
+	stub.mustQuery(t, "readIncidents", "", &incidents)
+	if len(incidents) != 2 || incidents[1].IncidentID == "" || incidents[1].Start != "2016-09-01T12:00:00Z" {
+		t.Fatalf("unexpected incidents %+v", incidents)
+	}
+	stub.mustQuery(t, "readIncidents", `{"assetID":"E1","to":"2016-09-01T11:00:00Z"}`, &incidents)
+	if len(incidents) != 0 {
+		t.Fatalf("expecting no incident before the start, got %+v", incidents)
+	}
+}
+
+func TestIncidentsRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFail(t, "reportIncident", `{"assetID":"E1","type":"entrapment","severity":"high"}`, "Asset does not exist")
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+	stub.mustFail(t, "reportIncident", `{"type":"entrapment","severity":"high"}`, "Asset id is mandatory")
+	stub.mustFail(t, "reportIncident", `{"assetID":"E1","incidentID":"I\u00001","type":"entrapment","severity":"high"}`, "Asset id is mandatory")
+	stub.mustFail(t, "reportIncident", `{"assetID":"E1","type":"flood","severity":"high"}`, "Invalid incident type")
+	stub.mustFail(t, "reportIncident", `{"assetID":"E1","type":"entrapment","severity":"dire"}`, "Invalid incident severity")
+	stub.mustFail(t, "reportIncident", `{"assetID":"E1","type":"entrapment"}`, "Type and severity are mandatory")
+	stub.mustFail(t, "reportIncident", `{"assetID":"E1","type":"entrapment","severity":"high","start":"noon"}`, "Invalid incident start")
+	stub.mustInvoke(t, "reportIncident", `{"assetID":"E1","incidentID":"I1","type":"entrapment","severity":"high"}`)
+	stub.mustFail(t, "reportIncident", `{"assetID":"E1","incidentID":"I1","type":"alarmButton"}`, "Type of a reported incident cannot change")
+	stub.mustFail(t, "reportIncident", `{"assetID":"E1","incidentID":"I1","start":"2016-08-01"}`, "Start of a reported incident cannot change")
+	stub.mustFail(t, "reportIncident", `{"assetID":"E1","incidentID":"I1","end":"2016-08-01"}`, "cannot be before its start")
+	stub.mustFailQuery(t, "readIncidents", `{"assetID":"E1","building":"B1"}`, "Expecting either an assetID or a building")
+	stub.mustFailQuery(t, "readIncidents", `{"building":"B\u00001"}`, "invalid character")
+	stub.mustFailQuery(t, "readIncidents", `{"from":"yesterday"}`, "Invalid from")
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// BUILDINGKEYPREFIX - object type for building membership entries, keyed by building and assetID
+const BUILDINGKEYPREFIX string = "BUILDING"
+
+// ALARMINDEXKEYPREFIX - object type for active alarm entries, keyed by alarm and assetID
+const ALARMINDEXKEYPREFIX string = "ALARMINDEX"
+
+// CERTEXPIRYKEYPREFIX - object type for certificate expiry entries, keyed by expiry time and assetID.
+// Certificates without an expiry are keyed by the zero time and sort first.
+const CERTEXPIRYKEYPREFIX string = "CERTEXPIRY"
+
+// secondary index names. Entries are composite keys of the index object type, the indexed value
+// and the assetID, with the assetID as value, and change in the transaction that changes the asset.
+const (
+	INDEXBUILDING          string = "building"
+	INDEXALARM             string = "alarm"
+	INDEXCERTIFICATEEXPIRY string = "certificateExpiry"
+)
+
+// assetIndexes - object type of each secondary index
+var assetIndexes = map[string]string{
+	INDEXBUILDING:          BUILDINGKEYPREFIX,
+	INDEXALARM:             ALARMINDEXKEYPREFIX,
+	INDEXCERTIFICATEEXPIRY: CERTEXPIRYKEYPREFIX,
+	INDEXMODEL:             MODELINDEXKEYPREFIX,
+}
+
+// IndexRebuild - entries written by rebuildIndexes
+type IndexRebuild struct {
+	Assets  int            `json:"assets"`  // registered assets
+	Entries map[string]int `json:"entries"` // per index
+	Removed int            `json:"removed"` // entries deleted before rebuilding, stale ones included
+}
+
+//******************** readAssetsByIndex ********************/
+
+// readAssetsByIndex - the IDs of the assets in a building, of a model, in an alarm or in any alarm
+// when no value is passed, or with a certificate expiring on or before a date
+func (t *SimpleChaincode) readAssetsByIndex(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var query struct {
+		Index string `json:"index"`
+		Value string `json:"value"`
+	}
+	var assetIDs = []string{}
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with index and value")
+	}
+	err := json.Unmarshal([]byte(args[0]), &query)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	prefix, ok := assetIndexes[query.Index]
+	if !ok {
+		return nil, errors.New("Unknown index: " + query.Index)
+	}
+	if strings.Contains(query.Value, KEYSEPARATOR) {
+		return nil, errors.New("Value contains an invalid character")
+	}
+	startKey, endKey := compositeRange(prefix)
+	if query.Index == INDEXCERTIFICATEEXPIRY {
+		until, err := parseTime(query.Value)
+		if err != nil {
+			return nil, errors.New("Invalid certificate expiry: " + query.Value)
+		}
+		_, endKey = compositeRange(prefix, timeKey(until))
+	} else if query.Value != "" {
+		startKey, endKey = compositeRange(prefix, query.Value)
+	} else if query.Index != INDEXALARM {
+		return nil, errors.New("Value is mandatory for index " + query.Index)
+	}
+	found, err := t.getIndexEntries(stub, startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	// an asset in several alarms is listed once
+	seen := map[string]bool{}
+	for _, assetID := range found {
+		if !seen[assetID] {
+			seen[assetID] = true
+			assetIDs = append(assetIDs, assetID)
+		}
+	}
+	return json.Marshal(assetIDs)
+}
+
+//******************** rebuildIndexes ********************/
+
+// rebuildIndexes - admin recovery from index drift. Removes every entry of the secondary indexes
+// and writes them again for the assets in the registry, dropping registry entries of assets that
+// are gone.
+func (t *SimpleChaincode) rebuildIndexes(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	err := t.requireRole(stub, ROLEADMIN)
+	if err != nil {
+		return nil, err
+	}
+	result := IndexRebuild{Entries: map[string]int{}}
+	for _, prefix := range assetIndexes {
+		startKey, endKey := compositeRange(prefix)
+		keys, err := t.getIndexKeys(stub, startKey, endKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			err = stub.DelState(key)
+			if err != nil {
+				return nil, errors.New("DELSTATE failed for index entry! : " + fmt.Sprint(err))
+			}
+			result.Removed++
+		}
+	}
+
+	startKey, endKey := compositeRange(ASSETKEYPREFIX)
+	assetIDs, err := t.getAssetIDs(stub, startKey, endKey, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, assetID := range assetIDs {
+		state, ok := t.getRegisteredAsset(stub, assetID)
+		if !ok {
+			err = stub.DelState(compositeKey(ASSETKEYPREFIX, assetID))
+			if err != nil {
+				return nil, errors.New("DELSTATE failed for asset registry entry! : " + fmt.Sprint(err))
+			}
+			result.Removed++
+			continue
+		}
+		result.Assets++
+		values, err := t.assetIndexValues(stub, state)
+		if err != nil {
+			return nil, err
+		}
+		for index, indexValues := range values {
+			err = t.updateIndexEntries(stub, assetIndexes[index], assetID, nil, indexValues)
+			if err != nil {
+				return nil, err
+			}
+			result.Entries[index] += len(indexValues)
+		}
+	}
+	return json.Marshal(result)
+}
+
+/*********************************  internal: secondary indexes ****************************/
+
+// updateAssetIndexes - moves the index entries of an asset from the values of its old state to
+// those of its new state. oldState is nil on create and newState is nil when the asset is deleted.
+func (t *SimpleChaincode) updateAssetIndexes(stub shim.ChaincodeStubInterface, assetID string, oldState *AssetState, newState *AssetState) error {
+	oldValues := stateIndexValues(oldState)
+	newValues := stateIndexValues(newState)
+	for index, prefix := range assetIndexes {
+		if index == INDEXCERTIFICATEEXPIRY || index == INDEXMODEL {
+			// kept with the certificate by recordInspection and with the nameplate by setNameplate
+			continue
+		}
+		err := t.updateIndexEntries(stub, prefix, assetID, oldValues[index], newValues[index])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateCertificateIndex - moves the expiry entry of an asset when its certificate changes,
+// newCert is nil when the asset is deleted
+func (t *SimpleChaincode) updateCertificateIndex(stub shim.ChaincodeStubInterface, assetID string, oldCert *Certificate, newCert *Certificate) error {
+	return t.updateIndexEntries(stub, CERTEXPIRYKEYPREFIX, assetID, certificateIndexValues(oldCert), certificateIndexValues(newCert))
+}
+
+// stateIndexValues - the values a state is indexed under, by index
+func stateIndexValues(state *AssetState) map[string][]string {
+	values := map[string][]string{}
+	if state == nil {
+		return values
+	}
+	if state.Building != nil && *state.Building != "" {
+		values[INDEXBUILDING] = []string{*state.Building}
+	}
+	values[INDEXALARM] = state.Alarms
+	return values
+}
+
+// assetIndexValues - the values an asset is indexed under in every index, from its state,
+// certificate and nameplate
+func (t *SimpleChaincode) assetIndexValues(stub shim.ChaincodeStubInterface, state AssetState) (map[string][]string, error) {
+	var cert Certificate
+	assetID := *state.AssetID
+	values := stateIndexValues(&state)
+	certBytes, err := stub.GetState(compositeKey(CERTIFICATEKEYPREFIX, assetID))
+	if err == nil && len(certBytes) > 0 && json.Unmarshal(certBytes, &cert) == nil {
+		values[INDEXCERTIFICATEEXPIRY] = certificateIndexValues(&cert)
+	}
+	nameplate, err := t.getNameplate(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+	values[INDEXMODEL] = nameplateIndexValues(nameplate)
+	return values, nil
+}
+
+// certificateIndexValues - the expiry a certificate is indexed under
+func certificateIndexValues(cert *Certificate) []string {
+	if cert == nil {
+		return nil
+	}
+	expiry, err := parseTime(cert.CertificateExpiry)
+	if err != nil {
+		expiry = time.Time{}
+	}
+	return []string{timeKey(expiry)}
+}
+
+// updateIndexEntries - removes the entries of values no longer present and adds those of new values
+func (t *SimpleChaincode) updateIndexEntries(stub shim.ChaincodeStubInterface, prefix string, assetID string, oldValues []string, newValues []string) error {
+	for _, value := range oldValues {
+		if isOneOf(value, newValues...) {
+			continue
+		}
+		err := stub.DelState(compositeKey(prefix, value, assetID))
+		if err != nil {
+			return errors.New("DELSTATE failed for index entry! : " + fmt.Sprint(err))
+		}
+	}
+	for _, value := range newValues {
+		if isOneOf(value, oldValues...) {
+			continue
+		}
+		err := stub.PutState(compositeKey(prefix, value, assetID), []byte(assetID))
+		if err != nil {
+			return errors.New("PUT ledger state failed for index entry: " + fmt.Sprint(err))
+		}
+	}
+	return nil
+}
+
+// getBuildingAssets - the IDs of all assets in a building
+func (t *SimpleChaincode) getBuildingAssets(stub shim.ChaincodeStubInterface, building string) ([]string, error) {
+	if strings.TrimSpace(building) == "" || strings.Contains(building, KEYSEPARATOR) {
+		return nil, errors.New("Invalid building: " + building)
+	}
+	startKey, endKey := compositeRange(BUILDINGKEYPREFIX, building)
+	return t.getIndexEntries(stub, startKey, endKey)
+}
+
+// getIndexEntries - the assetIDs of the index entries between startKey and endKey
+func (t *SimpleChaincode) getIndexEntries(stub shim.ChaincodeStubInterface, startKey string, endKey string) ([]string, error) {
+	var assetIDs []string
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return nil, errors.New("Unable to read index entries from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		_, assetIDBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read index entries from ledger: " + fmt.Sprint(err))
+		}
+		assetIDs = append(assetIDs, string(assetIDBytes))
+	}
+	return assetIDs, nil
+}
+
+// getIndexKeys - the ledger keys between startKey and endKey
+func (t *SimpleChaincode) getIndexKeys(stub shim.ChaincodeStubInterface, startKey string, endKey string) ([]string, error) {
+	var keys []string
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return nil, errors.New("Unable to read index entries from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		key, _, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read index entries from ledger: " + fmt.Sprint(err))
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
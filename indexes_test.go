@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestAssetIndexes(t *testing.T) {
+	var assetIDs []string
+	var rebuild IndexRebuild
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","building":"B1","temperature":70}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E2","building":"B1","temperature":120}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E3","building":"B2"}`)
+	stub.as(ROLEINSPECTOR, "").attributes[IDENTITYATTRIBUTE] = "kim"
+	stub.mustInvoke(t, "recordInspection", `{"assetID":"E3","date":"2016-08-01","result":"pass","certificateExpiry":"2016-10-01"}`)
+	stub.as("", "")
+
+	stub.mustQuery(t, "readAssetsByIndex", `{"index":"building","value":"B1"}`, &assetIDs)
+	if fmt.Sprint(assetIDs) != "[E1 E2]" {
+		t.Fatalf("unexpected building entries %v", assetIDs)
+	}
+	// entries move with the asset
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E2","building":"B2","temperature":70}`)
+	stub.mustQuery(t, "readAssetsByIndex", `{"index":"building","value":"B2"}`, &assetIDs)
+	if fmt.Sprint(assetIDs) != "[E2 E3]" {
+		t.Fatalf("unexpected building entries %v", assetIDs)
+	}
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","temperature":120}`)
+	stub.mustQuery(t, "readAssetsByIndex", `{"index":"alarm"}`, &assetIDs)
+	if fmt.Sprint(assetIDs) != "[E1]" {
+		t.Fatalf("unexpected alarm entries %v", assetIDs)
+	}
+	stub.mustQuery(t, "readAssetsByIndex", `{"index":"certificateExpiry","value":"2016-10-01"}`, &assetIDs)
+	if fmt.Sprint(assetIDs) != "[E3]" {
+		t.Fatalf("unexpected certificate entries %v", assetIDs)
+	}
+	stub.mustInvoke(t, "deleteAsset", `{"assetID":"E3"}`)
+	stub.mustQuery(t, "readAssetsByIndex", `{"index":"building","value":"B2"}`, &assetIDs)
+	if fmt.Sprint(assetIDs) != "[E2]" {
+		t.Fatalf("unexpected building entries after delete %v", assetIDs)
+	}
+
+	// a stale entry is removed by a rebuild
+	stub.transact(func() ([]byte, error) {
+		return nil, stub.PutState(compositeKey(BUILDINGKEYPREFIX, "B9", "E9"), []byte("E9"))
+	})
+	stub.as(ROLEADMIN, "")
+	err := json.Unmarshal(stub.mustInvoke(t, "rebuildIndexes", ""), &rebuild)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebuild.Assets != 2 || rebuild.Entries[INDEXBUILDING] != 2 || rebuild.Entries[INDEXALARM] != 1 || rebuild.Removed != 4 {
+		t.Fatalf("unexpected rebuild %+v", rebuild)
+	}
+	stub.mustQuery(t, "readAssetsByIndex", `{"index":"building","value":"B9"}`, &assetIDs)
+	if len(assetIDs) != 0 {
+		t.Fatalf("stale entry survived the rebuild %v", assetIDs)
+	}
+}
+
+func TestAssetIndexesRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFailQuery(t, "readAssetsByIndex", `{"index":"colour","value":"red"}`, "Unknown index")
+	stub.mustFailQuery(t, "readAssetsByIndex", `{"index":"building"}`, "Value is mandatory for index building")
+	stub.mustFailQuery(t, "readAssetsByIndex", `{"index":"building","value":"B\u00001"}`, "Value contains an invalid character")
+	stub.mustFailQuery(t, "readAssetsByIndex", `{"index":"certificateExpiry","value":"soon"}`, "Invalid certificate expiry")
+	stub.mustFail(t, "rebuildIndexes", "", "not allowed")
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// INSPECTIONKEYPREFIX - object type for inspection records, keyed by assetID and inspectionID
+const INSPECTIONKEYPREFIX string = "INSPECTION"
+
+// CERTIFICATEKEYPREFIX - object type for the current safety certificate of an asset, keyed by assetID
+const CERTIFICATEKEYPREFIX string = "CERTIFICATE"
+
+// CERTIFICATEWARNINGDAYS - certificates expiring within this many days are reported as expiring
+const CERTIFICATEWARNINGDAYS int = 30
+
+// inspection results
+const (
+	INSPECTIONPASS            string = "pass"
+	INSPECTIONPASSWITHDEFECTS string = "passWithDefects"
+	INSPECTIONFAIL            string = "fail"
+)
+
+// certificate status values
+const (
+	CERTIFICATEVALID    string = "valid"
+	CERTIFICATEEXPIRING string = "expiring"
+	CERTIFICATEEXPIRED  string = "expired"
+)
+
+// Inspection - a periodic safety inspection of an asset
+type Inspection struct {
+	InspectionID      string   `json:"inspectionID"`
+	AssetID           string   `json:"assetID"`
+	Inspector         string   `json:"inspector"`                   // enrollment identity of the caller that recorded it
+	Date              string   `json:"date"`                        // date the inspection took place
+	Result            string   `json:"result"`                      // pass, passWithDefects or fail
+	Defects           []string `json:"defects,omitempty"`           // defects found
+	CertificateExpiry string   `json:"certificateExpiry,omitempty"` // expiry of the certificate issued, not set when failed
+	Recorded          string   `json:"recorded"`                    // transaction time the inspection was recorded
+}
+
+// Certificate - the current safety certificate of an asset, renewed by passed inspections
+type Certificate struct {
+	AssetID           string `json:"assetID"`
+	InspectionID      string `json:"inspectionID"`      // inspection that issued the certificate
+	Inspector         string `json:"inspector"`         // inspector that issued the certificate
+	CertificateExpiry string `json:"certificateExpiry"` // date the certificate expires
+	LastInspection    string `json:"lastInspection"`    // date of the most recent inspection, passed or not
+	LastResult        string `json:"lastResult"`        // result of the most recent inspection
+}
+
+// CertificationStatus - certificate of an asset evaluated at the transaction time
+type CertificationStatus struct {
+	Certificate
+	Status        string `json:"status"`        // valid, expiring or expired
+	DaysRemaining int    `json:"daysRemaining"` // negative once expired
+}
+
+//******************** recordInspection ********************/
+
+// recordInspection - inspector or admin only, the inspector recorded is the caller's enrollment identity
+func (t *SimpleChaincode) recordInspection(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var inspection Inspection
+	var cert Certificate
+
+	err := t.requireRole(stub, ROLEINSPECTOR, ROLEADMIN)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with mandatory assetID")
+	}
+	err = json.Unmarshal([]byte(args[0]), &inspection)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	inspection.AssetID = strings.TrimSpace(inspection.AssetID)
+	if inspection.AssetID == "" || strings.Contains(inspection.AssetID, KEYSEPARATOR) {
+		return nil, errors.New("Asset id is mandatory in the input JSON data")
+	}
+	// the inspector is who signed the transaction, never what the input claims
+	inspection.Inspector = callerIdentity(stub)
+	if inspection.Inspector == "" {
+		return nil, errors.New("Caller certificate carries no " + IDENTITYATTRIBUTE + " attribute to record as inspector")
+	}
+	assetBytes, err := stub.GetState(inspection.AssetID)
+	if err != nil || len(assetBytes) == 0 {
+		return nil, errors.New("Asset does not exist: " + inspection.AssetID)
+	}
+	if !isOneOf(inspection.Result, INSPECTIONPASS, INSPECTIONPASSWITHDEFECTS, INSPECTIONFAIL) {
+		return nil, errors.New("Invalid inspection result: " + inspection.Result)
+	}
+	inspected, err := parseTime(inspection.Date)
+	if err != nil {
+		return nil, errors.New("Invalid inspection date: " + inspection.Date)
+	}
+	if inspection.Result == INSPECTIONFAIL {
+		// a failed inspection does not issue a certificate
+		inspection.CertificateExpiry = ""
+	} else {
+		expiry, err := parseTime(inspection.CertificateExpiry)
+		if err != nil {
+			return nil, errors.New("Invalid certificate expiry: " + inspection.CertificateExpiry)
+		}
+		if !expiry.After(inspected) {
+			return nil, errors.New("Certificate expiry must be after the inspection date")
+		}
+	}
+	inspection.InspectionID = strings.TrimSpace(inspection.InspectionID)
+	if strings.Contains(inspection.InspectionID, KEYSEPARATOR) {
+		return nil, errors.New("InspectionID contains an invalid character")
+	}
+	if inspection.InspectionID == "" {
+		inspection.InspectionID = stub.GetTxID()
+	}
+	inspectionKey := compositeKey(INSPECTIONKEYPREFIX, inspection.AssetID, inspection.InspectionID)
+	existing, err := stub.GetState(inspectionKey)
+	if err == nil && len(existing) > 0 {
+		return nil, errors.New("Inspection already recorded: " + inspection.InspectionID)
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	inspection.Recorded = formatTime(now)
+
+	inspectionJSON, err := json.Marshal(inspection)
+	if err != nil {
+		return nil, errors.New("Marshal failed for inspection" + fmt.Sprint(err))
+	}
+	err = stub.PutState(inspectionKey, inspectionJSON)
+	if err != nil {
+		return nil, errors.New("PUT ledger state failed for inspection: " + fmt.Sprint(err))
+	}
+
+	// Update the current certificate, inspections may be recorded out of order
+	var oldCert *Certificate
+	certKey := compositeKey(CERTIFICATEKEYPREFIX, inspection.AssetID)
+	certBytes, err := stub.GetState(certKey)
+	if err == nil && len(certBytes) > 0 {
+		err = json.Unmarshal(certBytes, &cert)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal certificate data obtained from ledger")
+		}
+		priorCert := cert
+		oldCert = &priorCert
+	}
+	cert.AssetID = inspection.AssetID
+	if last, err := parseTime(cert.LastInspection); err != nil || !inspected.Before(last) {
+		cert.LastInspection = inspection.Date
+		cert.LastResult = inspection.Result
+	}
+	if inspection.CertificateExpiry != "" {
+		current, err := parseTime(cert.CertificateExpiry)
+		expiry, _ := parseTime(inspection.CertificateExpiry)
+		if err != nil || expiry.After(current) {
+			cert.InspectionID = inspection.InspectionID
+			cert.Inspector = inspection.Inspector
+			cert.CertificateExpiry = inspection.CertificateExpiry
+		}
+	}
+	certJSON, err := json.Marshal(cert)
+	if err != nil {
+		return nil, errors.New("Marshal failed for certificate" + fmt.Sprint(err))
+	}
+	err = stub.PutState(certKey, certJSON)
+	if err != nil {
+		return nil, errors.New("PUT ledger state failed for certificate: " + fmt.Sprint(err))
+	}
+	err = t.updateCertificateIndex(stub, inspection.AssetID, oldCert, &cert)
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+//******************** readInspections ********************/
+
+func (t *SimpleChaincode) readInspections(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var inspections = []Inspection{}
+
+	stateIn, err := t.validateInput(args)
+	if err != nil {
+		return nil, err
+	}
+	startKey, endKey := compositeRange(INSPECTIONKEYPREFIX, *stateIn.AssetID)
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return nil, errors.New("Unable to read inspections from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		var inspection Inspection
+		_, inspectionBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read inspections from ledger: " + fmt.Sprint(err))
+		}
+		err = json.Unmarshal(inspectionBytes, &inspection)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal inspection data obtained from ledger")
+		}
+		inspections = append(inspections, inspection)
+	}
+	inspectionsJSON, err := json.Marshal(inspections)
+	if err != nil {
+		return nil, errors.New("Marshal failed for inspections" + fmt.Sprint(err))
+	}
+	return inspectionsJSON, nil
+}
+
+//******************** readCertificationStatus ********************/
+
+func (t *SimpleChaincode) readCertificationStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var cert Certificate
+
+	stateIn, err := t.validateInput(args)
+	if err != nil {
+		return nil, err
+	}
+	certBytes, err := stub.GetState(compositeKey(CERTIFICATEKEYPREFIX, *stateIn.AssetID))
+	if err != nil || len(certBytes) == 0 {
+		return nil, errors.New("No inspection recorded for asset: " + *stateIn.AssetID)
+	}
+	err = json.Unmarshal(certBytes, &cert)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal certificate data obtained from ledger")
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	statusJSON, err := json.Marshal(certificationStatus(cert, now, CERTIFICATEWARNINGDAYS))
+	if err != nil {
+		return nil, errors.New("Marshal failed for certification status" + fmt.Sprint(err))
+	}
+	return statusJSON, nil
+}
+
+//******************** readExpiringCertificates ********************/
+
+// readExpiringCertificates - lists every asset whose certificate has expired or expires within
+// withinDays days, CERTIFICATEWARNINGDAYS by default, soonest first. Takes an optional JSON argument.
+func (t *SimpleChaincode) readExpiringCertificates(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var filter struct {
+		WithinDays *int `json:"withinDays"`
+	}
+	var expiring = []CertificationStatus{}
+
+	if len(args) > 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting an optional JSON string with withinDays")
+	}
+	if len(args) == 1 {
+		err := json.Unmarshal([]byte(args[0]), &filter)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal input JSON data")
+		}
+	}
+	withinDays := CERTIFICATEWARNINGDAYS
+	if filter.WithinDays != nil {
+		if *filter.WithinDays < 0 {
+			return nil, errors.New("withinDays cannot be negative")
+		}
+		withinDays = *filter.WithinDays
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	// certificates expiring by the end of the window, in expiry order
+	startKey, _ := compositeRange(CERTEXPIRYKEYPREFIX)
+	_, endKey := compositeRange(CERTEXPIRYKEYPREFIX, timeKey(now.AddDate(0, 0, withinDays)))
+	assetIDs, err := t.getIndexEntries(stub, startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, assetID := range assetIDs {
+		var cert Certificate
+		certBytes, err := stub.GetState(compositeKey(CERTIFICATEKEYPREFIX, assetID))
+		if err != nil || len(certBytes) == 0 {
+			continue
+		}
+		err = json.Unmarshal(certBytes, &cert)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal certificate data obtained from ledger")
+		}
+		status := certificationStatus(cert, now, withinDays)
+		if status.Status != CERTIFICATEVALID {
+			expiring = append(expiring, status)
+		}
+	}
+	expiringJSON, err := json.Marshal(expiring)
+	if err != nil {
+		return nil, errors.New("Marshal failed for certification status" + fmt.Sprint(err))
+	}
+	return expiringJSON, nil
+}
+
+/*********************************  internal: certificationStatus ****************************/
+
+// certificationStatus - evaluates a certificate at the given time. Assets that never passed
+// an inspection have no expiry and are reported as expired.
+func certificationStatus(cert Certificate, now time.Time, withinDays int) CertificationStatus {
+	status := CertificationStatus{Certificate: cert, Status: CERTIFICATEEXPIRED}
+	expiry, err := parseTime(cert.CertificateExpiry)
+	if err != nil {
+		return status
+	}
+	remaining := expiry.Sub(now)
+	status.DaysRemaining = int(remaining.Hours() / 24)
+	if remaining <= 0 {
+		return status
+	}
+	status.Status = CERTIFICATEVALID
+	if remaining <= time.Duration(withinDays)*24*time.Hour {
+		status.Status = CERTIFICATEEXPIRING
+	}
+	return status
+}
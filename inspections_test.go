@@ -0,0 +1,52 @@
+package main
+
+import "testing"
+
+func TestInspections(t *testing.T) {
+	var inspections []Inspection
+	var status CertificationStatus
+	var expiring []CertificationStatus
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E2"}`)
+	stub.as(ROLEINSPECTOR, "").attributes[IDENTITYATTRIBUTE] = "kim"
+	stub.mustInvoke(t, "recordInspection", `{"assetID":"E1","inspectionID":"I1","date":"2016-08-01","result":"pass","certificateExpiry":"2017-08-01"}`)
+	stub.mustInvoke(t, "recordInspection", `{"assetID":"E1","inspectionID":"I2","date":"2016-08-20","result":"fail","defects":["brake wear"]}`)
+	// the inspector comes from the certificate, whatever the input says
+	stub.attributes[IDENTITYATTRIBUTE] = "lee"
+	stub.mustInvoke(t, "recordInspection", `{"assetID":"E2","inspector":"kim","date":"2015-09-15","result":"passWithDefects","certificateExpiry":"2016-09-15"}`)
+
+	stub.mustQuery(t, "readInspections", `{"assetID":"E1"}`, &inspections)
+	if len(inspections) != 2 || inspections[1].CertificateExpiry != "" || inspections[0].Inspector != "kim" {
+		t.Fatalf("unexpected inspections %+v", inspections)
+	}
+	// the failed inspection is the last one but leaves the certificate of the pass
+	stub.mustQuery(t, "readCertificationStatus", `{"assetID":"E1"}`, &status)
+	if status.Status != CERTIFICATEVALID || status.InspectionID != "I1" || status.LastResult != INSPECTIONFAIL {
+		t.Fatalf("unexpected certification status %+v", status)
+	}
+	stub.mustQuery(t, "readExpiringCertificates", "", &expiring)
+	if len(expiring) != 1 || expiring[0].AssetID != "E2" || expiring[0].Status != CERTIFICATEEXPIRING || expiring[0].Inspector != "lee" {
+		t.Fatalf("unexpected expiring certificates %+v", expiring)
+	}
+}
+
+func TestInspectionsRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+	stub.as("operator", "").attributes[IDENTITYATTRIBUTE] = "kim"
+	stub.mustFail(t, "recordInspection", `{"assetID":"E1","date":"2016-08-01","result":"fail"}`, "not allowed")
+	stub.as(ROLEINSPECTOR, "")
+	stub.mustFail(t, "recordInspection", `{"assetID":"E2","date":"2016-08-01","result":"fail"}`, "Asset does not exist")
+	delete(stub.attributes, IDENTITYATTRIBUTE)
+	stub.mustFail(t, "recordInspection", `{"assetID":"E1","inspector":"kim","date":"2016-08-01","result":"fail"}`, "no enrollmentId attribute")
+	stub.attributes[IDENTITYATTRIBUTE] = "kim"
+	stub.mustFail(t, "recordInspection", `{"assetID":"E1","date":"2016-08-01","result":"maybe"}`, "Invalid inspection result")
+	stub.mustFail(t, "recordInspection", `{"assetID":"E1","date":"August","result":"fail"}`, "Invalid inspection date")
+	stub.mustFail(t, "recordInspection", `{"assetID":"E1","date":"2016-08-01","result":"pass","certificateExpiry":"2016-07-01"}`, "must be after")
+	stub.mustFail(t, "recordInspection", `{"assetID":"E1","inspectionID":"I\u00001","date":"2016-08-01","result":"fail"}`, "invalid character")
+	stub.mustInvoke(t, "recordInspection", `{"assetID":"E1","inspectionID":"I1","date":"2016-08-01","result":"fail"}`)
+	stub.mustFail(t, "recordInspection", `{"assetID":"E1","inspectionID":"I1","date":"2016-08-01","result":"fail"}`, "already recorded")
+	stub.mustFailQuery(t, "readCertificationStatus", `{"assetID":"E2"}`, "No inspection recorded")
+	stub.mustFailQuery(t, "readExpiringCertificates", `{"withinDays":-1}`, "cannot be negative")
+}
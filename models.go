@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// MODELKEYPREFIX - object type for the model catalogue, keyed by model
+const MODELKEYPREFIX string = "MODEL"
+
+// sources of an asset limit
+const (
+	LIMITASSET   string = "asset"   // the asset's nameplate
+	LIMITMODEL   string = "model"   // the catalogue entry of the model on the nameplate
+	LIMITDEFAULT string = "default" // the contract default
+)
+
+// Model - manufacturer specifications of an elevator model, the defaults of every asset whose
+// nameplate names the model
+type Model struct {
+	Model                   string   `json:"model"`
+	Manufacturer            string   `json:"manufacturer,omitempty"`
+	RatedLoad               *float64 `json:"ratedLoad,omitempty"`               // in Lb
+	RatedSpeed              *float64 `json:"ratedSpeed,omitempty"`              // in feet/minute
+	MinTemperature          *float64 `json:"minTemperature,omitempty"`          // lowest operating temperature in Fahrenheit
+	MaxTemperature          *float64 `json:"maxTemperature,omitempty"`          // highest operating temperature in Fahrenheit
+	MaintenanceIntervalDays *int     `json:"maintenanceIntervalDays,omitempty"` // days between maintenance visits
+	Updated                 string   `json:"updated"`
+}
+
+// AssetLimits - limits in effect for an asset, from its nameplate, its model or the contract
+// defaults in that order. Sources names where each limit came from.
+type AssetLimits struct {
+	AssetID                 string            `json:"assetID"`
+	Model                   string            `json:"model,omitempty"`
+	RatedLoad               *float64          `json:"ratedLoad,omitempty"`
+	RatedSpeed              *float64          `json:"ratedSpeed,omitempty"`
+	MinTemperature          *float64          `json:"minTemperature,omitempty"`
+	MaxTemperature          *float64          `json:"maxTemperature,omitempty"`
+	MaintenanceIntervalDays *int              `json:"maintenanceIntervalDays,omitempty"`
+	NextMaintenance         string            `json:"nextMaintenance,omitempty"` // last closed work order or commissioning plus the interval
+	Sources                 map[string]string `json:"sources"`
+}
+
+//******************** setModel ********************/
+
+// setModel - admin only, adds or replaces a catalogue model and re-evaluates the alarms of its assets
+func (t *SimpleChaincode) setModel(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var model Model
+
+	err := t.requireRole(stub, ROLEADMIN)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with mandatory model")
+	}
+	err = json.Unmarshal([]byte(args[0]), &model)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	model.Model = strings.TrimSpace(model.Model)
+	if model.Model == "" || strings.Contains(model.Model, KEYSEPARATOR) {
+		return nil, errors.New("Model is mandatory in the input JSON data")
+	}
+	err = validateLimits(model.RatedLoad, model.RatedSpeed, model.MinTemperature, model.MaxTemperature, model.MaintenanceIntervalDays)
+	if err != nil {
+		return nil, err
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	model.Updated = formatTime(now)
+	modelJSON, err := json.Marshal(model)
+	if err != nil {
+		return nil, errors.New("Marshal failed for model" + fmt.Sprint(err))
+	}
+	err = stub.PutState(compositeKey(MODELKEYPREFIX, model.Model), modelJSON)
+	if err != nil {
+		return nil, errors.New("PUT ledger state failed for model: " + fmt.Sprint(err))
+	}
+	startKey, endKey := compositeRange(MODELINDEXKEYPREFIX, model.Model)
+	assetIDs, err := t.getIndexEntries(stub, startKey, endKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, assetID := range assetIDs {
+		err = t.refreshAlarms(stub, assetID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+//******************** readModels ********************/
+
+// readModels - the catalogue, or a single model when one is passed
+func (t *SimpleChaincode) readModels(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var query struct {
+		Model string `json:"model"`
+	}
+	var models = []Model{}
+
+	if len(args) > 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting an optional JSON string with model")
+	}
+	if len(args) == 1 {
+		err := json.Unmarshal([]byte(args[0]), &query)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal input JSON data")
+		}
+	}
+	if query.Model != "" {
+		model, err := t.getModel(stub, query.Model)
+		if err != nil {
+			return nil, err
+		}
+		if model == nil {
+			return nil, errors.New("Model does not exist: " + query.Model)
+		}
+		return json.Marshal(model)
+	}
+	startKey, endKey := compositeRange(MODELKEYPREFIX)
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return nil, errors.New("Unable to read models from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		var model Model
+		_, modelBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read models from ledger: " + fmt.Sprint(err))
+		}
+		err = json.Unmarshal(modelBytes, &model)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal model data obtained from ledger")
+		}
+		models = append(models, model)
+	}
+	return json.Marshal(models)
+}
+
+//******************** readAssetLimits ********************/
+
+// readAssetLimits - the limits in effect for an asset and where each comes from
+func (t *SimpleChaincode) readAssetLimits(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	stateIn, err := t.validateInput(args)
+	if err != nil {
+		return nil, err
+	}
+	assetID := *stateIn.AssetID
+	assetBytes, err := stub.GetState(assetID)
+	if err != nil || len(assetBytes) == 0 {
+		return nil, errors.New("Asset does not exist: " + assetID)
+	}
+	limits, err := t.getAssetLimits(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if limits.MaintenanceIntervalDays != nil {
+		nameplate, err := t.getNameplate(stub, assetID)
+		if err != nil {
+			return nil, err
+		}
+		var last time.Time
+		if nameplate != nil {
+			last, _ = parseTime(nameplate.CommissioningDate)
+		}
+		orders, err := t.getWorkOrders(stub, assetID)
+		if err != nil {
+			return nil, err
+		}
+		for _, order := range orders {
+			closed, err := parseTime(order.Closed)
+			if err == nil && order.Status == WOCLOSED && closed.After(last) {
+				last = closed
+			}
+		}
+		if !last.IsZero() {
+			limits.NextMaintenance = formatTime(last.AddDate(0, 0, *limits.MaintenanceIntervalDays))
+		}
+	}
+	return json.Marshal(limits)
+}
+
+/*********************************  internal: limits ****************************/
+
+// getAssetLimits - the limits of an asset, each from its nameplate, else its model, else the default
+func (t *SimpleChaincode) getAssetLimits(stub shim.ChaincodeStubInterface, assetID string) (AssetLimits, error) {
+	limits := AssetLimits{AssetID: assetID, Sources: map[string]string{}}
+	nameplate, err := t.getNameplate(stub, assetID)
+	if err != nil {
+		return limits, err
+	}
+	var model *Model
+	if nameplate != nil && nameplate.Model != "" {
+		limits.Model = nameplate.Model
+		model, err = t.getModel(stub, nameplate.Model)
+		if err != nil {
+			return limits, err
+		}
+	}
+	if nameplate == nil {
+		nameplate = &Nameplate{}
+	}
+	if model == nil {
+		model = &Model{}
+	}
+	contract, err := t.getContractState(stub)
+	if err != nil {
+		return limits, err
+	}
+	maxTemperature := defaultMaxTemperature(contract.Units)
+	limit := func(name string, asset *float64, fromModel *float64, fallback *float64) *float64 {
+		if asset != nil {
+			limits.Sources[name] = LIMITASSET
+			return asset
+		} else if fromModel != nil {
+			limits.Sources[name] = LIMITMODEL
+			return fromModel
+		} else if fallback != nil {
+			limits.Sources[name] = LIMITDEFAULT
+		}
+		return fallback
+	}
+	limits.RatedLoad = limit("ratedLoad", nameplate.RatedLoad, model.RatedLoad, nil)
+	limits.RatedSpeed = limit("ratedSpeed", nameplate.RatedSpeed, model.RatedSpeed, nil)
+	limits.MinTemperature = limit("minTemperature", nameplate.MinTemperature, model.MinTemperature, nil)
+	limits.MaxTemperature = limit("maxTemperature", nameplate.MaxTemperature, model.MaxTemperature, &maxTemperature)
+	if nameplate.MaintenanceIntervalDays != nil {
+		limits.MaintenanceIntervalDays = nameplate.MaintenanceIntervalDays
+		limits.Sources["maintenanceIntervalDays"] = LIMITASSET
+	} else if model.MaintenanceIntervalDays != nil {
+		limits.MaintenanceIntervalDays = model.MaintenanceIntervalDays
+		limits.Sources["maintenanceIntervalDays"] = LIMITMODEL
+	}
+	return limits, nil
+}
+
+// refreshAlarms - re-evaluates the alarms of an asset after its limits changed. A changed alarm
+// is written as an update carrying no fields, so it opens work orders and records history and
+// provenance the way any other update does.
+func (t *SimpleChaincode) refreshAlarms(stub shim.ChaincodeStubInterface, assetID string) error {
+	var state AssetState
+	assetBytes, err := stub.GetState(assetID)
+	if err != nil || len(assetBytes) == 0 {
+		return nil
+	}
+	err = json.Unmarshal(assetBytes, &state)
+	if err != nil {
+		return errors.New("Unable to unmarshal state data obtained from ledger")
+	}
+	limits, err := t.getAssetLimits(stub, assetID)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(t.evaluateAlarms(state, limits), state.Alarms) {
+		return nil
+	}
+	_, _, err = t.putAssetState(stub, AssetState{AssetID: &assetID})
+	return err
+}
+
+// validateLimits - ratings and intervals must be positive and the temperature range ordered
+func validateLimits(ratedLoad *float64, ratedSpeed *float64, minTemperature *float64, maxTemperature *float64, maintenanceIntervalDays *int) error {
+	if (ratedLoad != nil && *ratedLoad <= 0) || (ratedSpeed != nil && *ratedSpeed <= 0) {
+		return errors.New("Rated load and speed must be positive")
+	}
+	if minTemperature != nil && maxTemperature != nil && *minTemperature >= *maxTemperature {
+		return errors.New("Minimum temperature must be below the maximum temperature")
+	}
+	if maintenanceIntervalDays != nil && *maintenanceIntervalDays <= 0 {
+		return errors.New("Maintenance interval must be positive")
+	}
+	return nil
+}
+
+// getModel - a catalogue model, nil when it is not in the catalogue
+func (t *SimpleChaincode) getModel(stub shim.ChaincodeStubInterface, name string) (*Model, error) {
+	var model Model
+	modelBytes, err := stub.GetState(compositeKey(MODELKEYPREFIX, name))
+	if err != nil || len(modelBytes) == 0 {
+		return nil, nil
+	}
+	err = json.Unmarshal(modelBytes, &model)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal model data obtained from ledger")
+	}
+	return &model, nil
+}
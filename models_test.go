@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestModelCatalogue(t *testing.T) {
+	var models []Model
+	var limits, defaults AssetLimits
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","speed":450}`)
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "setNameplate", `{"assetID":"E1","model":"Gen2","maxTemperature":110,"commissioningDate":"2016-06-01"}`)
+	stub.mustInvoke(t, "setModel", `{"model":"Gen2","manufacturer":"Otis","ratedSpeed":400,"maxTemperature":95,"maintenanceIntervalDays":90}`)
+	stub.mustInvoke(t, "setModel", `{"model":"Gen3","ratedSpeed":500}`)
+
+	// a new model rating applies to the assets of the model right away
+	if view := stub.readAsset(t, "E1"); fmt.Sprint(view.Alarms) != "[overspeed]" {
+		t.Fatalf("unexpected alarms %v", view.Alarms)
+	}
+	stub.mustQuery(t, "readModels", "", &models)
+	if len(models) != 2 || models[0].Model != "Gen2" || models[1].Model != "Gen3" {
+		t.Fatalf("unexpected catalogue %+v", models)
+	}
+	// the nameplate overrides the model, which overrides the contract default
+	stub.mustQuery(t, "readAssetLimits", `{"assetID":"E1"}`, &limits)
+	if *limits.RatedSpeed != 400 || *limits.MaxTemperature != 110 || limits.Sources["ratedSpeed"] != LIMITMODEL ||
+		limits.Sources["maxTemperature"] != LIMITASSET || limits.NextMaintenance != "2016-08-30T00:00:00Z" {
+		t.Fatalf("unexpected limits %+v", limits)
+	}
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E2"}`)
+	stub.mustQuery(t, "readAssetLimits", `{"assetID":"E2"}`, &defaults)
+	if defaults.Sources["maxTemperature"] != LIMITDEFAULT || *defaults.MaxTemperature != MAXTEMPERATURE || defaults.RatedSpeed != nil || defaults.NextMaintenance != "" {
+		t.Fatalf("unexpected default limits %+v", defaults)
+	}
+}
+
+func TestModelAlarmsWithoutWorkOrders(t *testing.T) {
+	var orders []WorkOrder
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","speed":450}`)
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "updateContractConfig", `{"features":{"alarmWorkOrders":false}}`)
+	stub.mustInvoke(t, "setNameplate", `{"assetID":"E1","model":"Gen2"}`)
+	stub.mustInvoke(t, "setModel", `{"model":"Gen2","ratedSpeed":400}`)
+	if view := stub.readAsset(t, "E1"); fmt.Sprint(view.Alarms) != "[overspeed]" {
+		t.Fatalf("unexpected alarms %v", view.Alarms)
+	}
+	stub.mustQuery(t, "readWorkOrders", `{"assetID":"E1"}`, &orders)
+	if len(orders) != 0 {
+		t.Fatalf("work order opened with the feature disabled %+v", orders)
+	}
+
+	// enabled again, an alarm entered through the catalogue opens a work order
+	stub.mustInvoke(t, "setModel", `{"model":"Gen2","ratedSpeed":500}`)
+	stub.mustInvoke(t, "updateContractConfig", `{"features":{"alarmWorkOrders":true}}`)
+	stub.mustInvoke(t, "setModel", `{"model":"Gen2","ratedSpeed":400}`)
+	var opened []WorkOrder
+	stub.mustQuery(t, "readWorkOrders", `{"assetID":"E1"}`, &opened)
+	if len(opened) != 1 {
+		t.Fatalf("expecting one work order, got %+v", opened)
+	}
+}
+
+func TestModelCatalogueRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFail(t, "setModel", `{"model":"Gen2"}`, "not allowed")
+	stub.as(ROLEADMIN, "")
+	stub.mustFail(t, "setModel", `{"manufacturer":"Otis"}`, "Model is mandatory")
+	stub.mustFail(t, "setModel", `{"model":"Gen\u00002"}`, "Model is mandatory")
+	stub.mustFail(t, "setModel", `{"model":"Gen2","ratedSpeed":-1}`, "Rated load and speed must be positive")
+	stub.mustFail(t, "setModel", `{"model":"Gen2","minTemperature":90,"maxTemperature":80}`, "Minimum temperature must be below")
+	stub.mustFail(t, "setModel", `{"model":"Gen2","maintenanceIntervalDays":0}`, "Maintenance interval must be positive")
+	stub.mustFailQuery(t, "readModels", `{"model":"Gen2"}`, "Model does not exist")
+	stub.mustFailQuery(t, "readAssetLimits", `{"assetID":"E1"}`, "Asset does not exist")
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// NAMEPLATEKEYPREFIX - object type for the static nameplate of an asset, keyed by assetID
+const NAMEPLATEKEYPREFIX string = "NAMEPLATE"
+
+// MODELINDEXKEYPREFIX - object type for model entries, keyed by model and assetID
+const MODELINDEXKEYPREFIX string = "MODELINDEX"
+
+// INDEXMODEL - secondary index of assets by the model on their nameplate
+const INDEXMODEL string = "model"
+
+// Nameplate - static data of an asset, set by admins apart from the changing state. Ratings and
+// limits set here override those of the model in the catalogue.
+type Nameplate struct {
+	AssetID           string   `json:"assetID"`
+	Manufacturer      string   `json:"manufacturer,omitempty"`
+	Model             string   `json:"model,omitempty"`
+	SerialNumber      string   `json:"serialNumber,omitempty"`
+	RatedLoad         *float64 `json:"ratedLoad,omitempty"`         // in Lb like weight, more raises the overload alarm
+	RatedSpeed        *float64 `json:"ratedSpeed,omitempty"`        // in feet/minute like speed, faster raises the overspeed alarm
+	FloorsServed      *int     `json:"floorsServed,omitempty"`      // number of floors served
+	InstallationDate  string   `json:"installationDate,omitempty"`  // date the elevator was installed
+	CommissioningDate string   `json:"commissioningDate,omitempty"` // date the elevator entered service
+	Updated           string   `json:"updated"`                     // transaction time of the last change
+
+	// overrides of the limits of the model, see getAssetLimits
+	MinTemperature          *float64 `json:"minTemperature,omitempty"`
+	MaxTemperature          *float64 `json:"maxTemperature,omitempty"`
+	MaintenanceIntervalDays *int     `json:"maintenanceIntervalDays,omitempty"`
+}
+
+//******************** setNameplate ********************/
+
+// setNameplate - admin only, replaces the nameplate of an existing asset and re-evaluates its alarms
+func (t *SimpleChaincode) setNameplate(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var nameplate Nameplate
+
+	err := t.requireRole(stub, ROLEADMIN)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with mandatory assetID")
+	}
+	err = json.Unmarshal([]byte(args[0]), &nameplate)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	nameplate.AssetID = strings.TrimSpace(nameplate.AssetID)
+	nameplate.Model = strings.TrimSpace(nameplate.Model)
+	if nameplate.AssetID == "" || strings.Contains(nameplate.AssetID, KEYSEPARATOR) {
+		return nil, errors.New("Asset id is mandatory in the input JSON data")
+	}
+	if strings.Contains(nameplate.Model, KEYSEPARATOR) {
+		return nil, errors.New("Model contains an invalid character")
+	}
+	assetBytes, err := stub.GetState(nameplate.AssetID)
+	if err != nil || len(assetBytes) == 0 {
+		return nil, errors.New("Asset does not exist: " + nameplate.AssetID)
+	}
+	err = validateLimits(nameplate.RatedLoad, nameplate.RatedSpeed, nameplate.MinTemperature, nameplate.MaxTemperature, nameplate.MaintenanceIntervalDays)
+	if err != nil {
+		return nil, err
+	}
+	if nameplate.FloorsServed != nil && *nameplate.FloorsServed < 2 {
+		return nil, errors.New("An elevator serves at least 2 floors")
+	}
+	if nameplate.InstallationDate != "" {
+		if _, err := parseTime(nameplate.InstallationDate); err != nil {
+			return nil, errors.New("Invalid installation date: " + nameplate.InstallationDate)
+		}
+	}
+	if nameplate.CommissioningDate != "" {
+		commissioned, err := parseTime(nameplate.CommissioningDate)
+		if err != nil {
+			return nil, errors.New("Invalid commissioning date: " + nameplate.CommissioningDate)
+		}
+		if installed, err := parseTime(nameplate.InstallationDate); err == nil && commissioned.Before(installed) {
+			return nil, errors.New("Commissioning date cannot be before the installation date")
+		}
+	}
+	oldNameplate, err := t.getNameplate(stub, nameplate.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	nameplate.Updated = formatTime(now)
+
+	nameplateJSON, err := json.Marshal(nameplate)
+	if err != nil {
+		return nil, errors.New("Marshal failed for nameplate" + fmt.Sprint(err))
+	}
+	err = stub.PutState(compositeKey(NAMEPLATEKEYPREFIX, nameplate.AssetID), nameplateJSON)
+	if err != nil {
+		return nil, errors.New("PUT ledger state failed for nameplate: " + fmt.Sprint(err))
+	}
+	err = t.updateIndexEntries(stub, MODELINDEXKEYPREFIX, nameplate.AssetID, nameplateIndexValues(oldNameplate), nameplateIndexValues(&nameplate))
+	if err != nil {
+		return nil, err
+	}
+
+	// new ratings apply to the current state right away
+	err = t.refreshAlarms(stub, nameplate.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+//******************** readNameplate ********************/
+
+func (t *SimpleChaincode) readNameplate(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	stateIn, err := t.validateInput(args)
+	if err != nil {
+		return nil, err
+	}
+	nameplateBytes, err := stub.GetState(compositeKey(NAMEPLATEKEYPREFIX, *stateIn.AssetID))
+	if err != nil || len(nameplateBytes) == 0 {
+		return nil, errors.New("No nameplate set for asset: " + *stateIn.AssetID)
+	}
+	return nameplateBytes, nil
+}
+
+/*********************************  internal: nameplate ****************************/
+
+// getNameplate - the nameplate of an asset, nil when none was set
+func (t *SimpleChaincode) getNameplate(stub shim.ChaincodeStubInterface, assetID string) (*Nameplate, error) {
+	var nameplate Nameplate
+	nameplateBytes, err := stub.GetState(compositeKey(NAMEPLATEKEYPREFIX, assetID))
+	if err != nil || len(nameplateBytes) == 0 {
+		return nil, nil
+	}
+	err = json.Unmarshal(nameplateBytes, &nameplate)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal nameplate data obtained from ledger")
+	}
+	return &nameplate, nil
+}
+
+// nameplateIndexValues - the model a nameplate is indexed under
+func nameplateIndexValues(nameplate *Nameplate) []string {
+	if nameplate == nil || nameplate.Model == "" {
+		return nil
+	}
+	return []string{nameplate.Model}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNameplate(t *testing.T) {
+	var nameplate Nameplate
+	var assetIDs []string
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","weight":900}`)
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "setNameplate", `{"assetID":"E1","manufacturer":"Otis","model":"Gen2","ratedLoad":800,"floorsServed":12,"installationDate":"2010-05-01","commissioningDate":"2010-06-01"}`)
+
+	stub.mustQuery(t, "readNameplate", `{"assetID":"E1"}`, &nameplate)
+	if nameplate.Manufacturer != "Otis" || *nameplate.FloorsServed != 12 || nameplate.Updated != "2016-09-01T12:00:00Z" {
+		t.Fatalf("unexpected nameplate %+v", nameplate)
+	}
+	// the new rating applies to the current state right away
+	if view := stub.readAsset(t, "E1"); fmt.Sprint(view.Alarms) != "[overload]" {
+		t.Fatalf("unexpected alarms %v", view.Alarms)
+	}
+	stub.mustQuery(t, "readAssetsByIndex", `{"index":"model","value":"Gen2"}`, &assetIDs)
+	if fmt.Sprint(assetIDs) != "[E1]" {
+		t.Fatalf("unexpected model entries %v", assetIDs)
+	}
+	// a replaced nameplate moves the model entry and clears the alarm
+	stub.mustInvoke(t, "setNameplate", `{"assetID":"E1","model":"Gen3","ratedLoad":1000}`)
+	stub.mustQuery(t, "readAssetsByIndex", `{"index":"model","value":"Gen2"}`, &assetIDs)
+	if len(assetIDs) != 0 || len(stub.readAsset(t, "E1").Alarms) != 0 {
+		t.Fatalf("unexpected model entries %v or alarms", assetIDs)
+	}
+}
+
+func TestNameplateRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFail(t, "setNameplate", `{"assetID":"E1","model":"Gen2"}`, "not allowed")
+	stub.as(ROLEADMIN, "")
+	stub.mustFail(t, "setNameplate", `{"assetID":"E1","model":"Gen2"}`, "Asset does not exist")
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+	stub.mustFail(t, "setNameplate", `{"model":"Gen2"}`, "Asset id is mandatory")
+	stub.mustFail(t, "setNameplate", `{"assetID":"E1","model":"Gen\u00002"}`, "Model contains an invalid character")
+	stub.mustFail(t, "setNameplate", `{"assetID":"E1","ratedLoad":0}`, "Rated load and speed must be positive")
+	stub.mustFail(t, "setNameplate", `{"assetID":"E1","floorsServed":1}`, "An elevator serves at least 2 floors")
+	stub.mustFail(t, "setNameplate", `{"assetID":"E1","installationDate":"May"}`, "Invalid installation date")
+	stub.mustFail(t, "setNameplate", `{"assetID":"E1","installationDate":"2010-05-01","commissioningDate":"2010-04-01"}`, "Commissioning date cannot be before the installation date")
+	stub.mustFailQuery(t, "readNameplate", `{"assetID":"E1"}`, "No nameplate set for asset")
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// PROVENANCEKEYPREFIX - object type for the field provenance of an asset, keyed by assetID
+const PROVENANCEKEYPREFIX string = "PROVENANCE"
+
+// IDENTITYATTRIBUTE - certificate attribute holding the enrollment identity of the caller
+const IDENTITYATTRIBUTE string = "enrollmentId"
+
+// FieldProvenance - the write that set the current value of a state field
+type FieldProvenance struct {
+	Writer    string `json:"writer,omitempty"`   // enrollment identity of the caller, when its certificate carries one
+	TxID      string `json:"txID"`               // transaction that wrote the value
+	DeviceID  string `json:"deviceID,omitempty"` // device the reading came from, as passed with the update
+	Timestamp string `json:"timestamp"`          // transaction time of the write
+}
+
+// AssetProvenance - provenance of every field of an asset's state set by a caller, keyed by
+// field name. Fields the contract computes, alarms and anomalies, have no entry.
+type AssetProvenance struct {
+	AssetID string                     `json:"assetID"`
+	Fields  map[string]FieldProvenance `json:"fields"`
+}
+
+//******************** readAssetProvenance ********************/
+
+// readAssetProvenance - who and what last wrote each field of an asset, optionally for the
+// listed fields only
+func (t *SimpleChaincode) readAssetProvenance(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var query struct {
+		Fields []string `json:"fields"`
+	}
+
+	stateIn, err := t.validateInput(args)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal([]byte(args[0]), &query)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	assetID := *stateIn.AssetID
+	assetBytes, err := stub.GetState(assetID)
+	if err != nil || len(assetBytes) == 0 {
+		return nil, errors.New("Asset does not exist: " + assetID)
+	}
+	provenance, err := t.getProvenance(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if len(query.Fields) > 0 {
+		selected := map[string]FieldProvenance{}
+		for _, field := range query.Fields {
+			if entry, ok := provenance.Fields[field]; ok {
+				selected[field] = entry
+			}
+		}
+		provenance.Fields = selected
+	}
+	return json.Marshal(provenance)
+}
+
+/*********************************  internal: provenance ****************************/
+
+// updateProvenance - records the caller, transaction and device as the provenance of every
+// field the incoming state sets
+func (t *SimpleChaincode) updateProvenance(stub shim.ChaincodeStubInterface, stateIn AssetState) error {
+	assetID := *stateIn.AssetID
+	provenance, err := t.getProvenance(stub, assetID)
+	if err != nil {
+		return err
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return err
+	}
+	entry := FieldProvenance{
+		Writer:    callerIdentity(stub),
+		TxID:      stub.GetTxID(),
+		Timestamp: formatTime(now),
+	}
+	if stateIn.DeviceID != nil {
+		entry.DeviceID = *stateIn.DeviceID
+	}
+	for _, field := range setFields(stateIn) {
+		provenance.Fields[field] = entry
+	}
+	provenanceJSON, err := json.Marshal(provenance)
+	if err != nil {
+		return errors.New("Marshal failed for provenance" + fmt.Sprint(err))
+	}
+	err = stub.PutState(compositeKey(PROVENANCEKEYPREFIX, assetID), provenanceJSON)
+	if err != nil {
+		return errors.New("PUT ledger state failed for provenance: " + fmt.Sprint(err))
+	}
+	return nil
+}
+
+// getProvenance - the field provenance of an asset, empty when none was recorded
+func (t *SimpleChaincode) getProvenance(stub shim.ChaincodeStubInterface, assetID string) (AssetProvenance, error) {
+	provenance := AssetProvenance{AssetID: assetID, Fields: map[string]FieldProvenance{}}
+	provenanceBytes, err := stub.GetState(compositeKey(PROVENANCEKEYPREFIX, assetID))
+	if err != nil || len(provenanceBytes) == 0 {
+		return provenance, nil
+	}
+	err = json.Unmarshal(provenanceBytes, &provenance)
+	if err != nil {
+		return provenance, errors.New("Unable to unmarshal provenance data obtained from ledger")
+	}
+	return provenance, nil
+}
+
+// setFields - JSON names of the state fields an incoming state sets, leaving out the assetID,
+// the deviceID and the fields computed by the contract
+func setFields(stateIn AssetState) []string {
+	var fields []string
+	value := reflect.ValueOf(stateIn)
+	for i := 0; i < value.NumField(); i++ {
+		name := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+		if value.Field(i).IsNil() || isOneOf(name, "assetID", "deviceID", "alarms", "anomalies") {
+			continue
+		}
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// callerIdentity - enrollment identity from the caller's certificate, empty when it carries none
+func callerIdentity(stub shim.ChaincodeStubInterface) string {
+	identity, err := stub.ReadCertAttribute(IDENTITYATTRIBUTE)
+	if err != nil {
+		return ""
+	}
+	return string(identity)
+}
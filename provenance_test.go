@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestAssetProvenance(t *testing.T) {
+	var provenance, selected AssetProvenance
+	stub := newTestStub(t)
+	stub.attributes[IDENTITYATTRIBUTE] = "alice"
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","temperature":120,"floor":1,"deviceID":"sensor-1"}`)
+	stub.attributes[IDENTITYATTRIBUTE] = "bob"
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","floor":2}`)
+
+	stub.mustQuery(t, "readAssetProvenance", `{"assetID":"E1"}`, &provenance)
+	temperature, floor := provenance.Fields["temperature"], provenance.Fields["floor"]
+	if temperature.Writer != "alice" || temperature.DeviceID != "sensor-1" || temperature.TxID != "tx2" {
+		t.Fatalf("unexpected temperature provenance %+v", temperature)
+	}
+	if floor.Writer != "bob" || floor.DeviceID != "" || floor.TxID != "tx3" || floor.Timestamp != "2016-09-01T12:00:00Z" {
+		t.Fatalf("unexpected floor provenance %+v", floor)
+	}
+	// computed fields have no entry and the device is not stored with the state
+	if _, found := provenance.Fields["alarms"]; found || len(provenance.Fields) != 2 {
+		t.Fatalf("unexpected provenance %+v", provenance)
+	}
+	if view := stub.readAsset(t, "E1"); view.DeviceID != nil || len(view.Alarms) == 0 {
+		t.Fatalf("unexpected state %+v", view.AssetState)
+	}
+	stub.mustQuery(t, "readAssetProvenance", `{"assetID":"E1","fields":["floor","speed"]}`, &selected)
+	if len(selected.Fields) != 1 || selected.Fields["floor"].Writer != "bob" {
+		t.Fatalf("unexpected selected provenance %+v", selected)
+	}
+}
+
+func TestAssetProvenanceRejected(t *testing.T) {
+	var provenance AssetProvenance
+	stub := newTestStub(t)
+	stub.mustFailQuery(t, "readAssetProvenance", `{"fields":["floor"]}`, "Asset id is mandatory")
+	stub.mustFailQuery(t, "readAssetProvenance", `{"assetID":"E1"}`, "Asset does not exist")
+	stub.mustFailQuery(t, "readAssetProvenance", `{"assetID":"`+CONTRACTSTATEKEY+`"}`, "AssetID is reserved")
+	stub.mustFailQuery(t, "readAssetProvenance", `{"assetID":"E\u00001"}`, "invalid character")
+	// nothing is recorded while the feature is off
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "updateContractConfig", `{"features":{"provenance":false}}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","floor":1}`)
+	stub.mustQuery(t, "readAssetProvenance", `{"assetID":"E1"}`, &provenance)
+	if len(provenance.Fields) != 0 {
+		t.Fatalf("unexpected provenance %+v", provenance)
+	}
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// QUERYPAGESIZE - assets returned by queryAssets when no limit is passed
+const QUERYPAGESIZE int = 25
+
+// QUERYMAXLIMIT - most assets queryAssets returns at once
+const QUERYMAXLIMIT int = 1000
+
+// sort orders
+const (
+	SORTASC  string = "asc"
+	SORTDESC string = "desc"
+)
+
+// AssetQuery - a CouchDB style query over asset states. Field names are dotted paths such as
+// system.cpu. Sort entries are a field name, ascending, or an object of field name and order.
+type AssetQuery struct {
+	Selector map[string]interface{} `json:"selector"`
+	Sort     []interface{}          `json:"sort,omitempty"`
+	Fields   []string               `json:"fields,omitempty"`
+	Skip     int                    `json:"skip,omitempty"`
+	Limit    int                    `json:"limit,omitempty"`
+}
+
+// AssetQueryResult - a page of the assets matching a query
+type AssetQueryResult struct {
+	Assets  []map[string]interface{} `json:"assets"`
+	Matched int                      `json:"matched"` // assets matching the selector, across all pages
+	Skip    int                      `json:"skip"`
+	Limit   int                      `json:"limit"`
+}
+
+// sortField - a parsed sort entry
+type sortField struct {
+	Field string
+	Desc  bool
+}
+
+//******************** queryAssets ********************/
+
+// queryAssets - assets whose state matches a selector, sorted, projected and paged. Selectors use
+// the CouchDB syntax with $eq, $ne, $gt, $gte, $lt, $lte, $in, $nin, $exists, $regex, $elemMatch,
+// $and, $or, $nor and $not. The peers of this fabric release have no rich query API, so the
+// selector is evaluated in the contract over every registered asset, whatever the state database.
+func (t *SimpleChaincode) queryAssets(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var query AssetQuery
+	var matched []map[string]interface{}
+	var sortFields []sortField
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with a selector")
+	}
+	err := json.Unmarshal([]byte(args[0]), &query)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	if query.Selector == nil {
+		return nil, errors.New("Selector is mandatory in the input JSON data")
+	}
+	if query.Limit == 0 {
+		query.Limit = QUERYPAGESIZE
+	}
+	if query.Limit < 0 || query.Limit > QUERYMAXLIMIT {
+		return nil, fmt.Errorf("Limit must be between 1 and %d", QUERYMAXLIMIT)
+	}
+	if query.Skip < 0 {
+		return nil, errors.New("Skip cannot be negative")
+	}
+	for _, entry := range query.Sort {
+		field, err := parseSortField(entry)
+		if err != nil {
+			return nil, err
+		}
+		sortFields = append(sortFields, field)
+	}
+	// check the operators once, matching does not report errors for a particular asset
+	_, err = matchSelector(nil, query.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	startKey, endKey := compositeRange(ASSETKEYPREFIX)
+	assetIDs, err := t.getAssetIDs(stub, startKey, endKey, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, assetID := range assetIDs {
+		var state map[string]interface{}
+		assetBytes, err := stub.GetState(assetID)
+		if err != nil || len(assetBytes) == 0 {
+			continue
+		}
+		err = json.Unmarshal(assetBytes, &state)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal state data obtained from ledger")
+		}
+		if ok, _ := matchSelector(state, query.Selector); ok {
+			matched = append(matched, state)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		for _, field := range sortFields {
+			a, _ := fieldValue(matched[i], field.Field)
+			b, _ := fieldValue(matched[j], field.Field)
+			c := compareValues(a, b)
+			if c != 0 {
+				return (c < 0) != field.Desc
+			}
+		}
+		return false
+	})
+
+	result := AssetQueryResult{Assets: []map[string]interface{}{}, Matched: len(matched), Skip: query.Skip, Limit: query.Limit}
+	for i := query.Skip; i < len(matched) && i < query.Skip+query.Limit; i++ {
+		result.Assets = append(result.Assets, projectFields(matched[i], query.Fields))
+	}
+	return json.Marshal(result)
+}
+
+/*********************************  internal: selectors ****************************/
+
+// matchSelector - true when the document matches every condition of the selector. All conditions
+// are evaluated so that an unknown operator is reported, also for a nil document.
+func matchSelector(doc map[string]interface{}, selector map[string]interface{}) (bool, error) {
+	result := true
+	for key, condition := range selector {
+		var ok bool
+		var err error
+		switch key {
+		case "$and", "$or", "$nor":
+			conditions, isArray := condition.([]interface{})
+			if !isArray {
+				return false, errors.New(key + " expects an array of selectors")
+			}
+			matches := 0
+			for _, c := range conditions {
+				sub, isObject := c.(map[string]interface{})
+				if !isObject {
+					return false, errors.New(key + " expects an array of selectors")
+				}
+				m, err := matchSelector(doc, sub)
+				if err != nil {
+					return false, err
+				}
+				if m {
+					matches++
+				}
+			}
+			ok = (key == "$and" && matches == len(conditions)) || (key == "$or" && matches > 0) || (key == "$nor" && matches == 0)
+		case "$not":
+			sub, isObject := condition.(map[string]interface{})
+			if !isObject {
+				return false, errors.New("$not expects a selector")
+			}
+			ok, err = matchSelector(doc, sub)
+			ok = !ok
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false, errors.New("Unknown selector operator: " + key)
+			}
+			value, exists := fieldValue(doc, key)
+			ok, err = matchCondition(value, exists, condition)
+		}
+		if err != nil {
+			return false, err
+		}
+		result = result && ok
+	}
+	return result, nil
+}
+
+// matchCondition - matches a field value against a value, which means $eq, or an object of operators
+func matchCondition(value interface{}, exists bool, condition interface{}) (bool, error) {
+	operators, isObject := condition.(map[string]interface{})
+	if !isObject || len(operators) == 0 {
+		return exists && reflect.DeepEqual(value, condition), nil
+	}
+	for op := range operators {
+		if !strings.HasPrefix(op, "$") {
+			// a nested object is compared as a whole
+			return exists && reflect.DeepEqual(value, condition), nil
+		}
+	}
+	result := true
+	for op, operand := range operators {
+		ok := false
+		switch op {
+		case "$eq":
+			ok = exists && reflect.DeepEqual(value, operand)
+		case "$ne":
+			ok = exists && !reflect.DeepEqual(value, operand)
+		case "$gt", "$gte", "$lt", "$lte":
+			c, comparable := compareScalars(value, operand)
+			ok = exists && comparable && ((op == "$gt" && c > 0) || (op == "$gte" && c >= 0) || (op == "$lt" && c < 0) || (op == "$lte" && c <= 0))
+		case "$in", "$nin":
+			list, isArray := operand.([]interface{})
+			if !isArray {
+				return false, errors.New(op + " expects an array")
+			}
+			found := false
+			for _, item := range list {
+				found = found || reflect.DeepEqual(value, item)
+			}
+			ok = exists && found == (op == "$in")
+		case "$exists":
+			want, isBool := operand.(bool)
+			if !isBool {
+				return false, errors.New("$exists expects a boolean")
+			}
+			ok = exists == want
+		case "$regex":
+			pattern, isString := operand.(string)
+			if !isString {
+				return false, errors.New("$regex expects a string")
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return false, errors.New("Invalid $regex: " + pattern)
+			}
+			s, isString := value.(string)
+			ok = exists && isString && re.MatchString(s)
+		case "$elemMatch":
+			items, _ := value.([]interface{})
+			// an empty match validates the operand when there are no items
+			m, err := matchCondition(nil, false, operand)
+			if err != nil {
+				return false, err
+			}
+			ok = false
+			for _, item := range items {
+				m, _ = matchCondition(item, true, operand)
+				ok = ok || m
+			}
+		case "$not":
+			m, err := matchCondition(value, exists, operand)
+			if err != nil {
+				return false, err
+			}
+			ok = !m
+		default:
+			return false, errors.New("Unknown selector operator: " + op)
+		}
+		result = result && ok
+	}
+	return result, nil
+}
+
+// fieldValue - the value at a dotted path of a decoded document
+func fieldValue(doc map[string]interface{}, path string) (interface{}, bool) {
+	var value interface{} = doc
+	for _, name := range strings.Split(path, ".") {
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			return nil, false
+		}
+		value, isObject = object[name]
+		if !isObject {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// compareScalars - orders two numbers or two strings, comparable is false for other values
+func compareScalars(a interface{}, b interface{}) (int, bool) {
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			if x < y {
+				return -1, true
+			} else if x > y {
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	}
+	return 0, false
+}
+
+// compareValues - sort order of field values, numbers before strings before anything else,
+// missing values last
+func compareValues(a interface{}, b interface{}) int {
+	rank := func(v interface{}) int {
+		switch v.(type) {
+		case float64:
+			return 0
+		case string:
+			return 1
+		case nil:
+			return 3
+		}
+		return 2
+	}
+	if rank(a) != rank(b) {
+		return rank(a) - rank(b)
+	}
+	c, _ := compareScalars(a, b)
+	return c
+}
+
+// parseSortField - a sort entry, either "field" or {"field": "asc|desc"}
+func parseSortField(entry interface{}) (sortField, error) {
+	if field, ok := entry.(string); ok {
+		return sortField{Field: field}, nil
+	}
+	if object, ok := entry.(map[string]interface{}); ok && len(object) == 1 {
+		for field, order := range object {
+			if order != SORTASC && order != SORTDESC {
+				return sortField{}, errors.New("Sort order must be asc or desc: " + field)
+			}
+			return sortField{Field: field, Desc: order == SORTDESC}, nil
+		}
+	}
+	return sortField{}, errors.New("Invalid sort entry: " + fmt.Sprint(entry))
+}
+
+// projectFields - the listed dotted fields of a document, the whole document when none are listed
+func projectFields(doc map[string]interface{}, fields []string) map[string]interface{} {
+	if len(fields) == 0 {
+		return doc
+	}
+	projected := map[string]interface{}{}
+	for _, field := range fields {
+		value, exists := fieldValue(doc, field)
+		if !exists {
+			continue
+		}
+		names := strings.Split(field, ".")
+		object := projected
+		for _, name := range names[:len(names)-1] {
+			if _, ok := object[name].(map[string]interface{}); !ok {
+				object[name] = map[string]interface{}{}
+			}
+			object = object[name].(map[string]interface{})
+		}
+		object[names[len(names)-1]] = value
+	}
+	return projected
+}
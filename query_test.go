@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestQueryAssets(t *testing.T) {
+	var result AssetQueryResult
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","building":"B1","temperature":70,"system":{"cpu":20}}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E2","building":"B1","temperature":90,"system":{"cpu":60}}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E3","building":"B2","temperature":80}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E4","building":"B1"}`)
+
+	stub.mustQuery(t, "queryAssets", `{"selector":{"building":"B1","$or":[{"temperature":{"$gte":80}},{"system.cpu":{"$lt":50}}]},"sort":[{"temperature":"desc"}],"fields":["assetID","system.cpu"]}`, &result)
+	if result.Matched != 2 || len(result.Assets) != 2 || result.Assets[0]["assetID"] != "E2" || result.Assets[1]["assetID"] != "E1" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if len(result.Assets[0]) != 2 || result.Assets[0]["system"].(map[string]interface{})["cpu"] != 60.0 {
+		t.Fatalf("unexpected projection %+v", result.Assets[0])
+	}
+	// missing values sort last, pages count the matches across pages
+	stub.mustQuery(t, "queryAssets", `{"selector":{"assetID":{"$regex":"^E"}},"sort":["temperature"],"skip":2,"limit":2}`, &result)
+	if result.Matched != 4 || len(result.Assets) != 2 || result.Assets[0]["assetID"] != "E2" || result.Assets[1]["assetID"] != "E4" {
+		t.Fatalf("unexpected page %+v", result)
+	}
+	stub.mustQuery(t, "queryAssets", `{"selector":{"temperature":{"$exists":false}}}`, &result)
+	if result.Matched != 1 || result.Limit != QUERYPAGESIZE || result.Assets[0]["assetID"] != "E4" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestQueryAssetsRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFailQuery(t, "queryAssets", `{}`, "Selector is mandatory")
+	stub.mustFailQuery(t, "queryAssets", `{"selector":{},"limit":1001}`, "Limit must be between 1 and 1000")
+	stub.mustFailQuery(t, "queryAssets", `{"selector":{},"skip":-1}`, "Skip cannot be negative")
+	stub.mustFailQuery(t, "queryAssets", `{"selector":{},"sort":[{"floor":"up"}]}`, "Sort order must be asc or desc")
+	stub.mustFailQuery(t, "queryAssets", `{"selector":{},"sort":[1]}`, "Invalid sort entry")
+	stub.mustFailQuery(t, "queryAssets", `{"selector":{"floor":{"$near":1}}}`, "Unknown selector operator")
+	stub.mustFailQuery(t, "queryAssets", `{"selector":{"$or":{"floor":1}}}`, "expects an array of selectors")
+	stub.mustFailQuery(t, "queryAssets", `{"selector":{"assetID":{"$regex":"("}}}`, "Invalid $regex")
+}
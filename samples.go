@@ -4,6 +4,9 @@ var samples = `
 {
 	"event": {
 		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"building": "1 Main Street",
+		"owner": "Main Street Properties",
+		"provider": "Acme Elevator Service",
 		"weight": 1200.43,
 		"system": {
 			"cpu": 24,
@@ -11,14 +14,40 @@ var samples = `
 		},
 		"temperature": 72.3,
 		"speed": 1791,
-		"power": 10.23
+		"power": 10.23,
+		"energyMeter": 48213.6,
+		"floor": 3,
+		"direction": "up",
+		"doorStatus": "closed",
+		"operatingMode": "normal"
 	},
 	"initEvent": {
 		"nickname": "ELEVATOR",
-		"version": "The ID of a managed asset. The resource focal point for a smart contract."
+		"version": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"anomalyZScore": 3,
+		"units": {
+			"weight": "lb",
+			"temperature": "F",
+			"speed": "fpm"
+		},
+		"retention": {
+			"historyDays": 365,
+			"telemetryDays": 90,
+			"anomalyDays": 180
+		},
+		"validationMode": "lenient",
+		"features": {
+			"anomalyDetection": true,
+			"telemetry": true,
+			"alarmWorkOrders": true,
+			"provenance": true
+		}
 	},
 	"state": {
 		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"building": "1 Main Street",
+		"owner": "Main Street Properties",
+		"provider": "Acme Elevator Service",
 		"weight": 1200.43,
 		"system": {
 			"cpu": 24,
@@ -26,6 +55,495 @@ var samples = `
 		},
 		"temperature": 72.3,
 		"speed": 1791,
-		"power": 10.23
+		"power": 10.23,
+		"energyMeter": 48213.6,
+		"floor": 3,
+		"direction": "up",
+		"doorStatus": "closed",
+		"operatingMode": "normal",
+		"alarms": [],
+		"anomalies": []
+	},
+	"usage": {
+		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"totalTrips": 1520,
+		"floorsTravelled": 6110,
+		"doorCycles": 1534,
+		"runningHours": 41.7,
+		"lastUpdated": "2016-09-21T14:05:00Z"
+	},
+	"workOrder": {
+		"workOrderID": "WO-1001",
+		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"status": "inProgress",
+		"source": "alarm",
+		"description": "Opened automatically, asset entered temperature alarm",
+		"technician": "J. Smith",
+		"provider": "Acme Elevator Service",
+		"opened": "2016-09-21T14:05:00Z",
+		"assigned": "2016-09-21T14:20:00Z",
+		"started": "2016-09-21T15:02:00Z",
+		"updated": "2016-09-21T15:02:00Z",
+		"notes": [
+			{
+				"timestamp": "2016-09-21T15:02:00Z",
+				"status": "inProgress",
+				"text": "Machine room ventilation fan replaced"
+			}
+		]
+	},
+	"inspection": {
+		"inspectionID": "INSP-2016-0412",
+		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"inspector": "inspector2291",
+		"date": "2016-09-21",
+		"result": "passWithDefects",
+		"defects": [
+			"Pit light not working"
+		],
+		"certificateExpiry": "2017-09-21",
+		"recorded": "2016-09-21T16:30:00Z"
+	},
+	"certificationStatus": {
+		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"inspectionID": "INSP-2016-0412",
+		"inspector": "inspector2291",
+		"certificateExpiry": "2017-09-21",
+		"lastInspection": "2016-09-21",
+		"lastResult": "passWithDefects",
+		"status": "valid",
+		"daysRemaining": 365
+	},
+	"sla": {
+		"building": "1 Main Street",
+		"owner": "Main Street Properties",
+		"provider": "Acme Elevator Service",
+		"responseTimeHours": 4,
+		"maxDowntimeHoursPerMonth": 8,
+		"responsePenalty": 250,
+		"downtimePenaltyPerHour": 100,
+		"currency": "USD",
+		"updated": "2016-09-01T09:00:00Z"
+	},
+	"slaReport": {
+		"building": "1 Main Street",
+		"from": "2016-09-01T00:00:00Z",
+		"to": "2016-10-01T00:00:00Z",
+		"compliant": false,
+		"breaches": [
+			{
+				"type": "downtime",
+				"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+				"month": "2016-09",
+				"limitHours": 8,
+				"actualHours": 11.5,
+				"penalty": 350
+			}
+		],
+		"totalPenalty": 350
+	},
+	"telemetryBucket": {
+		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"granularity": "hour",
+		"start": "2016-09-21T14:00:00Z",
+		"fields": {
+			"temperature": {
+				"min": 71.8,
+				"max": 74.1,
+				"avg": 72.9,
+				"count": 12,
+				"sum": 874.8
+			},
+			"speed": {
+				"min": 0,
+				"max": 1791,
+				"avg": 902.5,
+				"count": 12,
+				"sum": 10830
+			}
+		}
+	},
+	"energyReport": {
+		"building": "1 Main Street",
+		"period": "month",
+		"tariff": 0.14,
+		"currency": "USD",
+		"periods": [
+			{
+				"start": "2016-09-01T00:00:00Z",
+				"end": "2016-10-01T00:00:00Z",
+				"kwh": 1843.2,
+				"cost": 258.05
+			}
+		],
+		"totalKwh": 1843.2,
+		"totalCost": 258.05
+	},
+	"anomaly": {
+		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"field": "temperature",
+		"timestamp": "2016-09-21T14:05:00Z",
+		"value": 91.4,
+		"mean": 72.6,
+		"stdDev": 1.9,
+		"zScore": 9.89
+	},
+	"assetHealth": {
+		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"score": 87.5,
+		"components": {
+			"alarms": {
+				"weight": 30,
+				"penalty": 0.33
+			},
+			"cpu": {
+				"weight": 5,
+				"penalty": 0
+			},
+			"memory": {
+				"weight": 5,
+				"penalty": 0.12
+			},
+			"power": {
+				"weight": 10,
+				"penalty": 0
+			},
+			"speed": {
+				"weight": 10,
+				"penalty": 0
+			},
+			"temperature": {
+				"weight": 20,
+				"penalty": 0
+			},
+			"usage": {
+				"weight": 20,
+				"penalty": 0.15
+			}
+		},
+		"computed": "2016-09-21T14:05:00Z"
+	},
+	"incident": {
+		"incidentID": "INC-0031",
+		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"building": "1 Main Street",
+		"type": "entrapment",
+		"severity": "high",
+		"description": "Two passengers trapped between floors 4 and 5",
+		"start": "2016-09-21T08:12:00Z",
+		"end": "2016-09-21T08:47:00Z",
+		"responder": "Acme Elevator Service, technician J. Smith",
+		"resolution": "Car moved to floor 5 in inspection mode, doors released",
+		"reported": "2016-09-21T08:13:02Z",
+		"updated": "2016-09-21T08:49:40Z"
+	},
+	"availabilityReport": {
+		"uptimePercent": 99.2,
+		"uptimeHours": 714.24,
+		"downtimeHours": 5.76,
+		"failures": 2,
+		"mtbfHours": 357.12,
+		"mttrHours": 2.88,
+		"building": "1 Main Street",
+		"from": "2016-09-01T00:00:00Z",
+		"to": "2016-10-01T00:00:00Z",
+		"assets": [
+			{
+				"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+				"uptimePercent": 99.2,
+				"uptimeHours": 714.24,
+				"downtimeHours": 5.76,
+				"failures": 2,
+				"mtbfHours": 357.12,
+				"mttrHours": 2.88
+			}
+		]
+	},
+	"batchResults": [
+		{
+			"index": 0,
+			"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+			"status": "updated"
+		},
+		{
+			"index": 1,
+			"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+			"status": "created"
+		}
+	],
+	"snapshotHeader": {
+		"contractVersion": "1.0",
+		"timestamp": "2016-10-01T00:00:00Z",
+		"history": true,
+		"assets": 100,
+		"next": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"nextHistory": "20161001T000000.000000000Z/b7c8d9e0-tx"
+	},
+	"snapshotRecord": {
+		"record": "history",
+		"timestamp": "2016-09-30T12:00:00Z",
+		"txID": "The ID of a transaction",
+		"state": {
+			"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+			"floor": 3
+		}
+	},
+	"assetQueryResult": {
+		"assets": [
+			{
+				"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+				"temperature": 95,
+				"system": {
+					"cpu": 85
+				}
+			}
+		],
+		"matched": 1,
+		"skip": 0,
+		"limit": 25
+	},
+	"indexRebuild": {
+		"assets": 250,
+		"entries": {
+			"alarm": 3,
+			"building": 250,
+			"certificateExpiry": 248
+		},
+		"removed": 505
+	},
+	"nameplate": {
+		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"manufacturer": "Otis",
+		"model": "Gen2",
+		"serialNumber": "SN-123456",
+		"ratedLoad": 2500,
+		"ratedSpeed": 350,
+		"floorsServed": 12,
+		"installationDate": "2015-03-01",
+		"commissioningDate": "2015-04-15",
+		"updated": "2016-09-01T00:00:00Z",
+		"maxTemperature": 95,
+		"maintenanceIntervalDays": 60
+	},
+	"model": {
+		"model": "Gen2",
+		"manufacturer": "Otis",
+		"ratedLoad": 2500,
+		"ratedSpeed": 350,
+		"minTemperature": 32,
+		"maxTemperature": 104,
+		"maintenanceIntervalDays": 90,
+		"updated": "2016-09-01T00:00:00Z"
+	},
+	"assetLimits": {
+		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"model": "Gen2",
+		"ratedLoad": 2500,
+		"ratedSpeed": 350,
+		"minTemperature": 32,
+		"maxTemperature": 95,
+		"maintenanceIntervalDays": 60,
+		"nextMaintenance": "2016-10-31T00:00:00Z",
+		"sources": {
+			"maintenanceIntervalDays": "asset",
+			"maxTemperature": "asset",
+			"minTemperature": "model",
+			"ratedLoad": "asset",
+			"ratedSpeed": "asset"
+		}
+	},
+	"derived": {
+		"loadFactor": 0.48,
+		"thermalMargin": 22.7,
+		"energyPerTrip": 0.035
+	},
+	"assetProvenance": {
+		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"fields": {
+			"temperature": {
+				"writer": "gateway7",
+				"txID": "c3b2f8a0-5e1d-4f0a-9d8e-2a4b6c8d0e1f",
+				"deviceID": "sensor-0042",
+				"timestamp": "2016-09-01T00:00:00Z"
+			},
+			"weight": {
+				"writer": "gateway7",
+				"txID": "c3b2f8a0-5e1d-4f0a-9d8e-2a4b6c8d0e1f",
+				"deviceID": "loadcell-0007",
+				"timestamp": "2016-09-01T00:00:00Z"
+			}
+		}
+	},
+	"confidentialFields": {
+		"tenantTerms": "Full service maintenance, 4 hour response, renewed yearly",
+		"accessCodes": [
+			"4711",
+			"0815"
+		],
+		"inspectorNotes": "Machine room door lock worn, recheck at next visit"
+	},
+	"transfer": {
+		"transferID": "The ID of a transaction",
+		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"from": "Acme Elevator Service",
+		"to": "Summit Lift Co",
+		"proposedBy": "Main Street Properties",
+		"status": "accepted",
+		"checklist": [
+			{
+				"item": "Machine room keys handed over",
+				"done": true
+			},
+			{
+				"item": "Maintenance log reviewed",
+				"done": true,
+				"note": "Two open defects noted"
+			}
+		],
+		"proposed": "2016-09-20T09:00:00Z",
+		"closed": "2016-09-30T17:00:00Z",
+		"snapshot": {
+			"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+			"building": "1 Main Street",
+			"owner": "Main Street Properties",
+			"provider": "Acme Elevator Service",
+			"weight": 1200.43,
+			"system": {
+				"cpu": 24,
+				"memory": 56
+			},
+			"temperature": 72.3,
+			"speed": 1791,
+			"power": 10.23,
+			"energyMeter": 48213.6,
+			"floor": 3,
+			"direction": "up",
+			"doorStatus": "closed",
+			"operatingMode": "normal",
+			"alarms": [],
+			"anomalies": []
+		},
+		"workOrders": [
+			"WO-1001"
+		]
+	},
+	"approvalPolicy": {
+		"operation": "deleteAsset",
+		"approvals": 2,
+		"organizations": [
+			"Main Street Properties",
+			"Acme Elevator Service",
+			"State Elevator Board"
+		],
+		"expiryHours": 48,
+		"updated": "2016-09-01T00:00:00Z"
+	},
+	"proposal": {
+		"proposalID": "The ID of a transaction",
+		"operation": "deleteAsset",
+		"args": {
+			"assetID": "The ID of a managed asset. The resource focal point for a smart contract."
+		},
+		"note": "Elevator decommissioned",
+		"proposedBy": "Main Street Properties",
+		"required": 2,
+		"approvals": [
+			{
+				"organization": "Main Street Properties",
+				"timestamp": "2016-09-20T09:00:00Z"
+			},
+			{
+				"organization": "Acme Elevator Service",
+				"timestamp": "2016-09-20T15:30:00Z"
+			}
+		],
+		"status": "executed",
+		"created": "2016-09-20T09:00:00Z",
+		"expires": "2016-09-22T09:00:00Z",
+		"executed": "2016-09-21T08:00:00Z"
+	},
+	"contractState": {
+		"nickname": "ELEVATOR",
+		"version": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"anomalyZScore": 3,
+		"units": {
+			"weight": "lb",
+			"temperature": "F",
+			"speed": "fpm"
+		},
+		"retention": {
+			"historyDays": 365,
+			"telemetryDays": 90,
+			"anomalyDays": 180
+		},
+		"validationMode": "lenient",
+		"features": {
+			"anomalyDetection": true,
+			"telemetry": true,
+			"alarmWorkOrders": true,
+			"provenance": true
+		},
+		"updated": "2016-09-01T12:00:00Z",
+		"lastMigration": {
+			"fromVersion": "1.0",
+			"toVersion": "1.1",
+			"txID": "b7c8d9e0-tx",
+			"timestamp": "2016-09-01T12:00:00Z"
+		},
+		"assets": 42,
+		"activeAlarms": 3
+	},
+	"diagnostics": {
+		"version": "1.1",
+		"consistent": false,
+		"assets": 42,
+		"checks": [
+			{
+				"name": "contract",
+				"checked": 1,
+				"problems": 0
+			},
+			{
+				"name": "registry",
+				"checked": 42,
+				"problems": 0
+			},
+			{
+				"name": "alarm",
+				"checked": 3,
+				"problems": 1,
+				"details": [
+					"Stale entry ALARMINDEX/temperature/E-17"
+				]
+			},
+			{
+				"name": "building",
+				"checked": 42,
+				"problems": 0
+			},
+			{
+				"name": "certificateExpiry",
+				"checked": 40,
+				"problems": 0
+			},
+			{
+				"name": "model",
+				"checked": 38,
+				"problems": 0
+			},
+			{
+				"name": "usage",
+				"checked": 42,
+				"problems": 0
+			}
+		]
+	},
+	"confidentialRecord": {
+		"assetID": "The ID of a managed asset. The resource focal point for a smart contract.",
+		"keyID": "tenant-terms-2016",
+		"nonce": "3q2+7wAAAAAAAAAA",
+		"ciphertext": "q83vEjRWeJASNFZ4kKvN7xI0VniQq83vEjRWeJA=",
+		"organization": "Acme Elevator Service",
+		"updated": "2016-09-01T00:00:00Z"
 	}
 }`
@@ -2,60 +2,59 @@ package main
 
 var schemas = `{
 	"API": {
-		"createAsset": {
-			"description": "Create an asset. One argument, a JSON encoded event. AssetID is required with zero or more writable properties. Establishes an initial asset state.",
+		"acceptTransfer": {
+			"description": "The new provider accepts a pending transfer, confirming every checklist item as done. The asset's provider, its open work orders and its own SLA terms move to the new provider, as does the SLA of its building once the old provider maintains no other asset there. The state handed over is kept in the transfer. The caller's organisation is read from its certificate attribute organization.",
 			"properties": {
 				"args": {
 					"description": "args are JSON encoded strings",
 					"items": {
-						"description": "A set of fields that constitute the writable fields in an asset's state. AssetID is mandatory along with at least one writable field. In this contract pattern, a partial state is used as an event.",
 						"properties": {
 							"assetID": {
 								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
 								"type": "string"
 							},
-							"weight": {
-								"description": "Weight of the Asset in Lb",
-								"type": "number"
+							"transferID": {
+								"type": "string"
 							},
-							"system": {
-								"description": "Properties of micro computer installed in the elevator",
-								"properties": {
-									"cpu": {
-										"type": "number"
+							"checklist": {
+								"description": "Checklist items confirmed, matched by item",
+								"items": {
+									"properties": {
+										"item": {
+											"type": "string"
+										},
+										"done": {
+											"type": "boolean"
+										},
+										"note": {
+											"type": "string"
+										}
 									},
-									"memory": {
-										"type": "number"
-									}
+									"required": [
+										"item"
+									],
+									"type": "object"
 								},
-								"type": "object"
+								"type": "array"
 							},
-							"temperature": {
-								"description": "Temperature of the asset in Fahrenheit.",
-								"type": "number"
-							},
-							"speed": {
-								"description": "Speed of the asset in feet/minute.",
-								"type": "number"
-							},
-							"power": {
-								"description": "Power consumption by the asset in KwH.",
-								"type": "number"
+							"note": {
+								"type": "string"
 							}
 						},
+						"type": "object",
 						"required": [
-							"assetID"
-						],
-						"type": "object"
+							"assetID",
+							"transferID"
+						]
 					},
 					"maxItems": 1,
 					"minItems": 1,
 					"type": "array"
 				},
 				"function": {
-					"description": "createAsset function",
+					"description": "acceptTransfer function",
 					"enum": [
-						"createAsset"
+						"acceptTransfer"
 					],
 					"type": "string"
 				},
@@ -63,29 +62,30 @@ var schemas = `{
 			},
 			"type": "object"
 		},
-		"deleteAsset": {
-			"description": "Delete an asset. Argument is a JSON encoded string containing only an assetID.",
+		"approveOperation": {
+			"description": "Add the approval of the caller's organization to a pending proposal before it expires. The caller's organization is read from its certificate attribute organization.",
 			"properties": {
 				"args": {
 					"description": "args are JSON encoded strings",
 					"items": {
-						"description": "An object containing only an assetID for use as an argument to read or delete.",
 						"properties": {
-							"assetID": {
-								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"proposalID": {
 								"type": "string"
 							}
 						},
-						"type": "object"
+						"type": "object",
+						"required": [
+							"proposalID"
+						]
 					},
 					"maxItems": 1,
 					"minItems": 1,
 					"type": "array"
 				},
 				"function": {
-					"description": "deleteAsset function",
+					"description": "approveOperation function",
 					"enum": [
-						"deleteAsset"
+						"approveOperation"
 					],
 					"type": "string"
 				},
@@ -93,26 +93,33 @@ var schemas = `{
 			},
 			"type": "object"
 		},
-		"init": {
-			"description": "Initializes the contract when started, either by deployment or by peer restart.",
+		"assignTechnician": {
+			"description": "Assign a technician to an open or assigned work order.",
 			"properties": {
 				"args": {
 					"description": "args are JSON encoded strings",
 					"items": {
-						"description": "event sent to init on deployment",
 						"properties": {
-							"nickname": {
-								"default": "ELEVATOR",
-								"description": "The nickname of the current contract",
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
 								"type": "string"
 							},
-							"version": {
-								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"workOrderID": {
+								"description": "The ID of a work order, unique per asset. Defaults to the transaction ID when opening.",
+								"type": "string"
+							},
+							"technician": {
+								"type": "string"
+							},
+							"note": {
+								"description": "Optional note appended to the work order.",
 								"type": "string"
 							}
 						},
 						"required": [
-							"version"
+							"assetID",
+							"workOrderID",
+							"technician"
 						],
 						"type": "object"
 					},
@@ -121,136 +128,399 @@ var schemas = `{
 					"type": "array"
 				},
 				"function": {
-					"description": "init function",
+					"description": "assignTechnician function",
 					"enum": [
-						"init"
+						"assignTechnician"
 					],
 					"type": "string"
 				},
-				"method": "deploy"
+				"method": "invoke"
 			},
 			"type": "object"
 		},
-		"readAsset": {
-			"description": "Returns the state an asset. Argument is a JSON encoded string. AssetID is the only accepted property.",
+		"batchUpdateAssets": {
+			"description": "Create or update up to 100 assets in one transaction, with the same validation and merge as createAsset and updateAsset. Items apply in order. Either all items apply or none does.",
 			"properties": {
 				"args": {
 					"description": "args are JSON encoded strings",
 					"items": {
-						"description": "An object containing only an assetID for use as an argument to read or delete.",
-						"properties": {
-							"assetID": {
-								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
-								"type": "string"
-							}
+						"description": "Array of partial asset states, each with a mandatory assetID",
+						"items": {
+							"description": "A set of fields that constitute the writable fields in an asset's state. AssetID is mandatory along with at least one writable field. In this contract pattern, a partial state is used as an event.",
+							"properties": {
+								"assetID": {
+									"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+									"type": "string"
+								},
+								"building": {
+									"description": "The building the elevator is installed in. SLA terms and reports may be set per building.",
+									"type": "string"
+								},
+								"owner": {
+									"description": "Organisation owning the elevator. Only callers with the role attribute admin set or change it.",
+									"type": "string"
+								},
+								"provider": {
+									"description": "Organisation maintaining the elevator. Only callers with the role attribute admin set it, once set it only changes through proposeTransfer and acceptTransfer. Work orders are opened for the current provider.",
+									"type": "string"
+								},
+								"weight": {
+									"description": "Weight of the Asset in Lb",
+									"type": "number"
+								},
+								"system": {
+									"description": "Properties of micro computer installed in the elevator",
+									"properties": {
+										"cpu": {
+											"type": "number"
+										},
+										"memory": {
+											"type": "number"
+										}
+									},
+									"type": "object"
+								},
+								"temperature": {
+									"description": "Temperature of the asset in Fahrenheit.",
+									"type": "number"
+								},
+								"speed": {
+									"description": "Speed of the asset in feet/minute.",
+									"type": "number"
+								},
+								"power": {
+									"description": "Power consumption by the asset in KwH.",
+									"type": "number"
+								},
+								"energyMeter": {
+									"description": "Cumulative energy meter reading in kWh. Energy per billing period is accounted from successive readings and a lower reading is treated as a meter reset.",
+									"type": "number"
+								},
+								"floor": {
+									"description": "Floor the car is currently at or passing. Negative values are below ground level.",
+									"type": "integer"
+								},
+								"direction": {
+									"description": "Travel direction of the car.",
+									"enum": [
+										"up",
+										"down",
+										"stopped"
+									],
+									"type": "string"
+								},
+								"doorStatus": {
+									"description": "State of the car doors. The car may only move with the doors closed.",
+									"enum": [
+										"open",
+										"closing",
+										"closed",
+										"obstructed"
+									],
+									"type": "string"
+								},
+								"operatingMode": {
+									"description": "Operating mode of the elevator. The car may not move when out of service.",
+									"enum": [
+										"normal",
+										"inspection",
+										"fireService",
+										"outOfService"
+									],
+									"type": "string"
+								},
+								"deviceID": {
+									"description": "The device the readings of this update came from. Recorded in the provenance of the fields the update sets, not stored in the state. Rejected in strict validation mode.",
+									"type": "string"
+								}
+							},
+							"required": [
+								"assetID"
+							],
+							"type": "object"
 						},
-						"type": "object"
+						"maxItems": 100,
+						"minItems": 1,
+						"type": "array"
 					},
 					"maxItems": 1,
 					"minItems": 1,
 					"type": "array"
 				},
 				"function": {
-					"description": "readAsset function",
+					"description": "batchUpdateAssets function",
 					"enum": [
-						"readAsset"
+						"batchUpdateAssets"
 					],
 					"type": "string"
 				},
-				"method": "query",
+				"method": "invoke",
 				"result": {
-					"description": "A set of fields that constitute the complete asset state.",
-					"properties": {
-						"assetID": {
-							"description": "The ID of a managed asset. The resource focal point for a smart contract.",
-							"type": "string"
-						},
-						"weight": {
-							"description": "Weight of the Asset in Lb",
-							"type": "number"
+					"description": "Outcome of each item, in the order passed. When an item fails the transaction fails and the error carries these results.",
+					"items": {
+						"properties": {
+							"index": {
+								"type": "integer"
+							},
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"status": {
+								"enum": [
+									"created",
+									"updated",
+									"failed",
+									"notApplied"
+								],
+								"type": "string"
+							},
+							"error": {
+								"description": "Why the item failed",
+								"type": "string"
+							}
 						},
-						"system": {
-							"description": "Properties of micro computer installed in the elevator",
+						"type": "object"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"batchCreateAssets": {
+			"description": "Create up to 100 assets that do not exist yet in one transaction, with the same validation as createAsset. An item whose asset already exists fails. Items apply in order. Either all items apply or none does.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"description": "Array of partial asset states, each with a mandatory assetID",
+						"items": {
+							"description": "A set of fields that constitute the writable fields in an asset's state. AssetID is mandatory along with at least one writable field. In this contract pattern, a partial state is used as an event.",
 							"properties": {
-								"cpu": {
+								"assetID": {
+									"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+									"type": "string"
+								},
+								"building": {
+									"description": "The building the elevator is installed in. SLA terms and reports may be set per building.",
+									"type": "string"
+								},
+								"owner": {
+									"description": "Organisation owning the elevator. Only callers with the role attribute admin set or change it.",
+									"type": "string"
+								},
+								"provider": {
+									"description": "Organisation maintaining the elevator. Only callers with the role attribute admin set it, once set it only changes through proposeTransfer and acceptTransfer. Work orders are opened for the current provider.",
+									"type": "string"
+								},
+								"weight": {
+									"description": "Weight of the Asset in Lb",
 									"type": "number"
 								},
-								"memory": {
+								"system": {
+									"description": "Properties of micro computer installed in the elevator",
+									"properties": {
+										"cpu": {
+											"type": "number"
+										},
+										"memory": {
+											"type": "number"
+										}
+									},
+									"type": "object"
+								},
+								"temperature": {
+									"description": "Temperature of the asset in Fahrenheit.",
+									"type": "number"
+								},
+								"speed": {
+									"description": "Speed of the asset in feet/minute.",
 									"type": "number"
+								},
+								"power": {
+									"description": "Power consumption by the asset in KwH.",
+									"type": "number"
+								},
+								"energyMeter": {
+									"description": "Cumulative energy meter reading in kWh. Energy per billing period is accounted from successive readings and a lower reading is treated as a meter reset.",
+									"type": "number"
+								},
+								"floor": {
+									"description": "Floor the car is currently at or passing. Negative values are below ground level.",
+									"type": "integer"
+								},
+								"direction": {
+									"description": "Travel direction of the car.",
+									"enum": [
+										"up",
+										"down",
+										"stopped"
+									],
+									"type": "string"
+								},
+								"doorStatus": {
+									"description": "State of the car doors. The car may only move with the doors closed.",
+									"enum": [
+										"open",
+										"closing",
+										"closed",
+										"obstructed"
+									],
+									"type": "string"
+								},
+								"operatingMode": {
+									"description": "Operating mode of the elevator. The car may not move when out of service.",
+									"enum": [
+										"normal",
+										"inspection",
+										"fireService",
+										"outOfService"
+									],
+									"type": "string"
+								},
+								"deviceID": {
+									"description": "The device the readings of this update came from. Recorded in the provenance of the fields the update sets, not stored in the state. Rejected in strict validation mode.",
+									"type": "string"
 								}
 							},
+							"required": [
+								"assetID"
+							],
 							"type": "object"
 						},
-						"temperature": {
-							"description": "Temperature of the asset in Fahrenheit.",
-							"type": "number"
-						},
-						"speed": {
-							"description": "Speed of the asset in feet/minute.",
-							"type": "number"
+						"maxItems": 100,
+						"minItems": 1,
+						"type": "array"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "batchCreateAssets function",
+					"enum": [
+						"batchCreateAssets"
+					],
+					"type": "string"
+				},
+				"method": "invoke",
+				"result": {
+					"description": "Outcome of each item, in the order passed. When an item fails the transaction fails and the error carries these results.",
+					"items": {
+						"properties": {
+							"index": {
+								"type": "integer"
+							},
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"status": {
+								"enum": [
+									"created",
+									"updated",
+									"failed",
+									"notApplied"
+								],
+								"type": "string"
+							},
+							"error": {
+								"description": "Why the item failed",
+								"type": "string"
+							}
 						},
-						"power": {
-							"description": "Power consumption by the asset in KwH.",
-							"type": "number"
-						}
+						"type": "object"
 					},
-					"type": "object"
+					"type": "array"
 				}
 			},
 			"type": "object"
 		},
-		"readAssetSamples": {
-			"description": "Returns a string generated from the schema containing sample Objects as specified in generate.json in the scripts folder.",
+		"cancelTransfer": {
+			"description": "Withdraw a pending transfer, by the organisation that proposed it, or decline it, by the new provider. Callers with the role attribute admin may cancel any pending transfer. The caller's organisation is read from its certificate attribute organization.",
 			"properties": {
 				"args": {
-					"description": "accepts no arguments",
-					"items": {},
-					"maxItems": 0,
-					"minItems": 0,
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"transferID": {
+								"type": "string"
+							},
+							"note": {
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID",
+							"transferID"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
 					"type": "array"
 				},
 				"function": {
-					"description": "readAssetSamples function",
+					"description": "cancelTransfer function",
 					"enum": [
-						"readAssetSamples"
+						"cancelTransfer"
 					],
 					"type": "string"
 				},
-				"method": "query",
-				"result": {
-					"description": "JSON encoded object containing selected sample data",
-					"type": "string"
-				}
+				"method": "invoke"
 			},
 			"type": "object"
 		},
-		"readAssetSchemas": {
-			"description": "Returns a string generated from the schema containing APIs and Objects as specified in generate.json in the scripts folder.",
+		"closeWorkOrder": {
+			"description": "Close a work order with an optional resolution.",
 			"properties": {
 				"args": {
-					"description": "accepts no arguments",
-					"items": {},
-					"maxItems": 0,
-					"minItems": 0,
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"workOrderID": {
+								"description": "The ID of a work order, unique per asset. Defaults to the transaction ID when opening.",
+								"type": "string"
+							},
+							"resolution": {
+								"type": "string"
+							},
+							"note": {
+								"description": "Optional note appended to the work order.",
+								"type": "string"
+							}
+						},
+						"required": [
+							"assetID",
+							"workOrderID"
+						],
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
 					"type": "array"
 				},
 				"function": {
-					"description": "readAssetSchemas function",
+					"description": "closeWorkOrder function",
 					"enum": [
-						"readAssetSchemas"
+						"closeWorkOrder"
 					],
 					"type": "string"
 				},
-				"method": "query",
-				"result": {
-					"description": "JSON encoded object containing selected schemas",
-					"type": "string"
-				}
+				"method": "invoke"
 			},
 			"type": "object"
 		},
-		"updateAsset": {
-			"description": "Update the state of an asset. The one argument is a JSON encoded event. AssetID is required along with one or more writable properties. Establishes the next asset state. ",
+		"createAsset": {
+			"description": "Create an asset. One argument, a JSON encoded event. AssetID is required with zero or more writable properties. Establishes an initial asset state.",
 			"properties": {
 				"args": {
 					"description": "args are JSON encoded strings",
@@ -261,6 +531,18 @@ var schemas = `{
 								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
 								"type": "string"
 							},
+							"building": {
+								"description": "The building the elevator is installed in. SLA terms and reports may be set per building.",
+								"type": "string"
+							},
+							"owner": {
+								"description": "Organisation owning the elevator. Only callers with the role attribute admin set or change it.",
+								"type": "string"
+							},
+							"provider": {
+								"description": "Organisation maintaining the elevator. Only callers with the role attribute admin set it, once set it only changes through proposeTransfer and acceptTransfer. Work orders are opened for the current provider.",
+								"type": "string"
+							},
 							"weight": {
 								"description": "Weight of the Asset in Lb",
 								"type": "number"
@@ -288,133 +570,5687 @@ var schemas = `{
 							"power": {
 								"description": "Power consumption by the asset in KwH.",
 								"type": "number"
-							}
+							},
+							"energyMeter": {
+								"description": "Cumulative energy meter reading in kWh. Energy per billing period is accounted from successive readings and a lower reading is treated as a meter reset.",
+								"type": "number"
+							},
+							"floor": {
+								"description": "Floor the car is currently at or passing. Negative values are below ground level.",
+								"type": "integer"
+							},
+							"direction": {
+								"description": "Travel direction of the car.",
+								"enum": [
+									"up",
+									"down",
+									"stopped"
+								],
+								"type": "string"
+							},
+							"doorStatus": {
+								"description": "State of the car doors. The car may only move with the doors closed.",
+								"enum": [
+									"open",
+									"closing",
+									"closed",
+									"obstructed"
+								],
+								"type": "string"
+							},
+							"operatingMode": {
+								"description": "Operating mode of the elevator. The car may not move when out of service.",
+								"enum": [
+									"normal",
+									"inspection",
+									"fireService",
+									"outOfService"
+								],
+								"type": "string"
+							},
+							"deviceID": {
+								"description": "The device the readings of this update came from. Recorded in the provenance of the fields the update sets, not stored in the state. Rejected in strict validation mode.",
+								"type": "string"
+							}
+						},
+						"required": [
+							"assetID"
+						],
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "createAsset function",
+					"enum": [
+						"createAsset"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"deleteAsset": {
+			"description": "Delete an asset. Argument is a JSON encoded string containing only an assetID. When an approval policy is set for it, it only runs through proposeOperation, approveOperation and executeOperation.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"description": "An object containing only an assetID for use as an argument to read or delete.",
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "deleteAsset function",
+					"enum": [
+						"deleteAsset"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"diagnostics": {
+			"description": "Checks the stored contract state, the asset registry, the secondary indexes and the usage counters against the assets listed in the registry, to verify a peer after a restart or upgrade. An asset whose registry entry was lost shows as stale index entries and usage counters until its next update lists it again.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 0,
+					"type": "array"
+				},
+				"function": {
+					"description": "diagnostics function",
+					"enum": [
+						"diagnostics"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "Consistency of the stored contract state, asset registry, secondary indexes and usage counters with the assets listed in the registry. rebuildIndexes repairs the registry and indexes.",
+					"properties": {
+						"version": {
+							"description": "Version of the running chaincode",
+							"type": "string"
+						},
+						"consistent": {
+							"description": "No check found a problem",
+							"type": "boolean"
+						},
+						"assets": {
+							"description": "Assets listed in the registry",
+							"type": "integer"
+						},
+						"checks": {
+							"items": {
+								"properties": {
+									"name": {
+										"enum": [
+											"contract",
+											"registry",
+											"building",
+											"alarm",
+											"certificateExpiry",
+											"model",
+											"usage"
+										],
+										"type": "string"
+									},
+									"checked": {
+										"description": "Records and entries checked",
+										"type": "integer"
+									},
+									"problems": {
+										"description": "Inconsistencies found",
+										"type": "integer"
+									},
+									"details": {
+										"description": "The first 100 inconsistencies found",
+										"items": {
+											"type": "string"
+										},
+										"type": "array"
+									}
+								},
+								"type": "object"
+							},
+							"type": "array"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"executeOperation": {
+			"description": "Run a pending proposal once enough organizations approved it, the approvals required when proposed or by the current policy, whichever is higher. The operation applies its own checks, such as the caller role, as when called directly.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"proposalID": {
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"proposalID"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "executeOperation function",
+					"enum": [
+						"executeOperation"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"exportAssets": {
+			"description": "Returns a page of assets in assetID order as an NDJSON or CSV snapshot. With history each asset is followed by its past states, oldest first, and a page that fills up within the history of an asset ends there. The export command of the contract binary pages through a peer and writes a single snapshot.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"format": {
+								"default": "ndjson",
+								"enum": [
+									"ndjson",
+									"csv"
+								],
+								"type": "string"
+							},
+							"history": {
+								"default": false,
+								"type": "boolean"
+							},
+							"start": {
+								"description": "First assetID of the page, the next property of the previous page's header",
+								"type": "string"
+							},
+							"historyStart": {
+								"description": "History record of start the page resumes at, the nextHistory property of the previous page's header. Expects start and history.",
+								"type": "string"
+							},
+							"limit": {
+								"default": 100,
+								"description": "Records per page, asset and history records alike, at most 1000",
+								"type": "integer"
+							}
+						},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 0,
+					"type": "array"
+				},
+				"function": {
+					"description": "exportAssets function",
+					"enum": [
+						"exportAssets"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "NDJSON, a snapshotHeader line followed by snapshotRecord lines, or CSV",
+					"type": "string"
+				}
+			},
+			"type": "object"
+		},
+		"init": {
+			"description": "Initializes the contract when started, either by deployment or by peer restart. Configuration already stored is kept unless the argument sets it. Lists the assets on the ledger while the asset registry is empty, as after an upgrade from a version without it. When an approval policy is set for it, it only runs through proposeOperation, approveOperation and executeOperation.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"description": "event sent to init on deployment",
+						"properties": {
+							"nickname": {
+								"default": "ELEVATOR",
+								"description": "The nickname of the current contract",
+								"type": "string"
+							},
+							"version": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"anomalyZScore": {
+								"default": 3,
+								"description": "z-score above which a telemetry reading is flagged as an anomaly",
+								"type": "number"
+							},
+							"units": {
+								"description": "Units readings and limits are recorded in. Readings are not converted, the default maximum temperature follows the temperature unit.",
+								"properties": {
+									"weight": {
+										"default": "lb",
+										"enum": [
+											"lb",
+											"kg"
+										],
+										"type": "string"
+									},
+									"temperature": {
+										"default": "F",
+										"enum": [
+											"F",
+											"C"
+										],
+										"type": "string"
+									},
+									"speed": {
+										"default": "fpm",
+										"description": "feet per minute or meters per second",
+										"enum": [
+											"fpm",
+											"mps"
+										],
+										"type": "string"
+									}
+								},
+								"type": "object"
+							},
+							"retention": {
+								"description": "Days records are kept, older records are removed when the asset is next updated. When a window is left out asset history is kept 365 days and other records forever.",
+								"properties": {
+									"historyDays": {
+										"description": "asset history",
+										"minimum": 1,
+										"type": "integer",
+										"default": 365
+									},
+									"telemetryDays": {
+										"description": "hourly and daily telemetry buckets",
+										"minimum": 1,
+										"type": "integer"
+									},
+									"anomalyDays": {
+										"description": "anomaly records",
+										"minimum": 1,
+										"type": "integer"
+									}
+								},
+								"type": "object"
+							},
+							"validationMode": {
+								"default": "lenient",
+								"description": "strict rejects asset state fields the contract does not know, nested ones included, and the fields it computes: alarms, anomalies and deviceID. lenient ignores them",
+								"enum": [
+									"strict",
+									"lenient"
+								],
+								"type": "string"
+							},
+							"features": {
+								"description": "Features switched on or off, a feature is on unless set false",
+								"properties": {
+									"anomalyDetection": {
+										"type": "boolean"
+									},
+									"telemetry": {
+										"type": "boolean"
+									},
+									"alarmWorkOrders": {
+										"type": "boolean"
+									},
+									"provenance": {
+										"type": "boolean"
+									}
+								},
+								"additionalProperties": false,
+								"type": "object"
+							}
+						},
+						"required": [
+							"version"
+						],
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "init function",
+					"enum": [
+						"init"
+					],
+					"type": "string"
+				},
+				"method": "deploy"
+			},
+			"type": "object"
+		},
+		"openWorkOrder": {
+			"description": "Open a maintenance work order for an existing asset.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"workOrderID": {
+								"description": "The ID of a work order, unique per asset. Defaults to the transaction ID when opening.",
+								"type": "string"
+							},
+							"description": {
+								"type": "string"
+							},
+							"note": {
+								"description": "Optional note appended to the work order.",
+								"type": "string"
+							},
+							"outOfService": {
+								"description": "Takes the asset out of service until the work order closes. Counts as downtime in availability and SLA reports.",
+								"type": "boolean"
+							}
+						},
+						"required": [
+							"assetID"
+						],
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "openWorkOrder function",
+					"enum": [
+						"openWorkOrder"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"proposeOperation": {
+			"description": "Propose an operation under an approval policy, with the argument it runs with. The proposer's organization is the first approval. The caller's organization is read from its certificate attribute organization.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"operation": {
+								"enum": [
+									"deleteAsset",
+									"setModel",
+									"setNameplate",
+									"setApprovalPolicy",
+									"updateContractConfig",
+									"init"
+								],
+								"type": "string"
+							},
+							"args": {
+								"description": "The JSON argument of the operation, for init the contract state",
+								"type": "object"
+							},
+							"note": {
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"operation",
+							"args"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "proposeOperation function",
+					"enum": [
+						"proposeOperation"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"proposeTransfer": {
+			"description": "Propose a new maintenance provider for an asset, with an optional handover checklist. The caller's organisation must be the asset's owner or current provider, unless the caller has the role attribute admin. An asset has at most one pending transfer. The caller's organisation is read from its certificate attribute organization.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"to": {
+								"description": "Provider taking over",
+								"type": "string"
+							},
+							"checklist": {
+								"description": "Handover steps the new provider confirms",
+								"items": {
+									"properties": {
+										"item": {
+											"type": "string"
+										},
+										"done": {
+											"type": "boolean"
+										},
+										"note": {
+											"type": "string"
+										}
+									},
+									"required": [
+										"item"
+									],
+									"type": "object"
+								},
+								"type": "array"
+							},
+							"note": {
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID",
+							"to"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "proposeTransfer function",
+					"enum": [
+						"proposeTransfer"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"queryAssets": {
+			"description": "Find assets whose state matches a CouchDB style selector, for example {\"temperature\":{\"$gt\":90},\"system.cpu\":{\"$gte\":80}}. Supports $eq, $ne, $gt, $gte, $lt, $lte, $in, $nin, $exists, $regex, $elemMatch, $and, $or, $nor and $not. The selector is evaluated by the contract over all assets.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"selector": {
+								"description": "Conditions on dotted field names such as system.cpu. A plain value means $eq.",
+								"type": "object"
+							},
+							"sort": {
+								"description": "Field names sorted ascending, or objects of field name and asc or desc. Missing values sort last.",
+								"items": {
+									"oneOf": [
+										{
+											"type": "string"
+										},
+										{
+											"type": "object"
+										}
+									]
+								},
+								"type": "array"
+							},
+							"fields": {
+								"description": "Dotted field names to return, all fields when absent",
+								"items": {
+									"type": "string"
+								},
+								"type": "array"
+							},
+							"skip": {
+								"default": 0,
+								"type": "integer"
+							},
+							"limit": {
+								"default": 25,
+								"description": "At most 1000",
+								"type": "integer"
+							}
+						},
+						"type": "object",
+						"required": [
+							"selector"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "queryAssets function",
+					"enum": [
+						"queryAssets"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "A page of the assets matching a query",
+					"properties": {
+						"assets": {
+							"description": "Asset states, reduced to the requested fields",
+							"items": {
+								"type": "object"
+							},
+							"type": "array"
+						},
+						"matched": {
+							"description": "Assets matching the selector across all pages",
+							"type": "integer"
+						},
+						"skip": {
+							"type": "integer"
+						},
+						"limit": {
+							"type": "integer"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readApprovalPolicies": {
+			"description": "Returns the approval policies that are set",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 0,
+					"type": "array"
+				},
+				"function": {
+					"description": "readApprovalPolicies function",
+					"enum": [
+						"readApprovalPolicies"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"items": {
+						"description": "Approvals an operation needs before it runs",
+						"properties": {
+							"operation": {
+								"enum": [
+									"deleteAsset",
+									"setModel",
+									"setNameplate",
+									"setApprovalPolicy",
+									"init"
+								],
+								"type": "string"
+							},
+							"approvals": {
+								"description": "Distinct organizations that must approve, including the proposer. 0 removes the policy.",
+								"type": "integer"
+							},
+							"organizations": {
+								"description": "Organizations that may propose and approve, any when left out",
+								"items": {
+									"type": "string"
+								},
+								"type": "array"
+							},
+							"expiryHours": {
+								"description": "Hours a proposal stays open, 72 when left out",
+								"type": "number"
+							},
+							"updated": {
+								"format": "date-time",
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"readAsset": {
+			"description": "Returns the state an asset with the fields derived from it, and its encrypted confidential fields when the caller's organization holds their key or the caller is an admin. Argument is a JSON encoded string. AssetID is the only accepted property.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"description": "An object containing only an assetID for use as an argument to read or delete.",
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readAsset function",
+					"enum": [
+						"readAsset"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "A set of fields that constitute the complete asset state, and the derived fields apart in derived.",
+					"properties": {
+						"assetID": {
+							"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"type": "string"
+						},
+						"building": {
+							"description": "The building the elevator is installed in. SLA terms and reports may be set per building.",
+							"type": "string"
+						},
+						"owner": {
+							"description": "Organisation owning the elevator. Only callers with the role attribute admin set or change it.",
+							"type": "string"
+						},
+						"provider": {
+							"description": "Organisation maintaining the elevator. Only callers with the role attribute admin set it, once set it only changes through proposeTransfer and acceptTransfer. Work orders are opened for the current provider.",
+							"type": "string"
+						},
+						"weight": {
+							"description": "Weight of the Asset in Lb",
+							"type": "number"
+						},
+						"system": {
+							"description": "Properties of micro computer installed in the elevator",
+							"properties": {
+								"cpu": {
+									"type": "number"
+								},
+								"memory": {
+									"type": "number"
+								}
+							},
+							"type": "object"
+						},
+						"temperature": {
+							"description": "Temperature of the asset in Fahrenheit.",
+							"type": "number"
+						},
+						"speed": {
+							"description": "Speed of the asset in feet/minute.",
+							"type": "number"
+						},
+						"power": {
+							"description": "Power consumption by the asset in KwH.",
+							"type": "number"
+						},
+						"energyMeter": {
+							"description": "Cumulative energy meter reading in kWh. Energy per billing period is accounted from successive readings and a lower reading is treated as a meter reset.",
+							"type": "number"
+						},
+						"floor": {
+							"description": "Floor the car is currently at or passing. Negative values are below ground level.",
+							"type": "integer"
+						},
+						"direction": {
+							"description": "Travel direction of the car.",
+							"enum": [
+								"up",
+								"down",
+								"stopped"
+							],
+							"type": "string"
+						},
+						"doorStatus": {
+							"description": "State of the car doors. The car may only move with the doors closed.",
+							"enum": [
+								"open",
+								"closing",
+								"closed",
+								"obstructed"
+							],
+							"type": "string"
+						},
+						"operatingMode": {
+							"description": "Operating mode of the elevator. The car may not move when out of service.",
+							"enum": [
+								"normal",
+								"inspection",
+								"fireService",
+								"outOfService"
+							],
+							"type": "string"
+						},
+						"alarms": {
+							"description": "Active alarms, computed by the contract on every update against the asset's limits, see readAssetLimits. temperature is raised outside the operating range, overload and overspeed above the rated load and speed. An alarm entered with an update opens a work order.",
+							"items": {
+								"enum": [
+									"temperature",
+									"overload",
+									"overspeed"
+								],
+								"type": "string"
+							},
+							"type": "array"
+						},
+						"anomalies": {
+							"description": "Telemetry fields whose latest reading deviated from the asset's rolling baseline by more than the configured z-score, computed by the contract. A field stays flagged until it is read again.",
+							"items": {
+								"enum": [
+									"temperature",
+									"speed",
+									"power",
+									"energy",
+									"system.cpu",
+									"system.memory"
+								],
+								"type": "string"
+							},
+							"type": "array"
+						},
+						"derived": {
+							"description": "Values computed by the contract from the asset's state, limits, usage and energy records on every read, never stored. A field is left out when what it is computed from is unknown.",
+							"properties": {
+								"loadFactor": {
+									"description": "Weight divided by the rated load, above 1 when overloaded",
+									"type": "number"
+								},
+								"thermalMargin": {
+									"description": "Degrees Fahrenheit below the maximum temperature of the asset's limits, negative when above",
+									"type": "number"
+								},
+								"energyPerTrip": {
+									"description": "kWh metered per completed trip over the asset's lifetime",
+									"type": "number"
+								}
+							},
+							"type": "object"
+						},
+						"confidential": {
+							"description": "The encrypted confidential fields, left out unless the caller's organization holds their key or the caller is an admin",
+							"properties": {
+								"assetID": {
+									"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+									"type": "string"
+								},
+								"keyID": {
+									"description": "Names the key the fields were encrypted with, chosen by the client. Never the key itself.",
+									"type": "string"
+								},
+								"nonce": {
+									"description": "Base64 nonce of the cipher, when it uses one",
+									"type": "string"
+								},
+								"ciphertext": {
+									"description": "Base64 encrypted JSON of the confidential fields",
+									"type": "string"
+								},
+								"organization": {
+									"description": "Organisation holding the key, which may read the record and replace the ciphertext under the same key",
+									"type": "string"
+								},
+								"updated": {
+									"description": "Transaction time of the last change",
+									"format": "date-time",
+									"type": "string"
+								}
+							},
+							"type": "object"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readAssetAggregates": {
+			"description": "Returns the telemetry buckets of an asset starting in [from, to) at the requested granularity. The range ends at the transaction time when to is not passed.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"from": {
+								"description": "RFC3339 timestamp or a plain date such as 2016-09-01",
+								"type": "string"
+							},
+							"to": {
+								"description": "RFC3339 timestamp or a plain date such as 2016-10-01",
+								"type": "string"
+							},
+							"granularity": {
+								"default": "hour",
+								"enum": [
+									"hour",
+									"day"
+								],
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID",
+							"from"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readAssetAggregates function",
+					"enum": [
+						"readAssetAggregates"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"items": {
+						"description": "Aggregated readings of an asset over one hour or UTC day. Only readings passed in an event count, values merged from the previous state do not.",
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"granularity": {
+								"enum": [
+									"hour",
+									"day"
+								],
+								"type": "string"
+							},
+							"start": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"fields": {
+								"description": "Aggregates by JSON path of the field: temperature, speed, power, energy (kWh consumed since the previous energy meter reading), system.cpu and system.memory.",
+								"additionalProperties": {
+									"properties": {
+										"min": {
+											"type": "number"
+										},
+										"max": {
+											"type": "number"
+										},
+										"avg": {
+											"type": "number"
+										},
+										"count": {
+											"type": "integer"
+										},
+										"sum": {
+											"type": "number"
+										}
+									},
+									"type": "object"
+								},
+								"type": "object"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"readAssetAnomalies": {
+			"description": "Returns the anomalies recorded for an asset, oldest first.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readAssetAnomalies function",
+					"enum": [
+						"readAssetAnomalies"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"items": {
+						"description": "A reading that deviated from the rolling baseline of the asset. Also emitted in an anomaly event.",
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"field": {
+								"type": "string"
+							},
+							"timestamp": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"value": {
+								"type": "number"
+							},
+							"mean": {
+								"type": "number"
+							},
+							"stdDev": {
+								"type": "number"
+							},
+							"zScore": {
+								"type": "number"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"readAssetHealth": {
+			"description": "Returns the health score of an asset, computed from its latest readings, usage counters and recent alarms and anomalies.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readAssetHealth function",
+					"enum": [
+						"readAssetHealth"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "Health score of an asset from 0 to 100, 100 is best. Components without data are left out of the score.",
+					"properties": {
+						"assetID": {
+							"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"type": "string"
+						},
+						"score": {
+							"maximum": 100,
+							"minimum": 0,
+							"type": "number"
+						},
+						"components": {
+							"additionalProperties": {
+								"properties": {
+									"weight": {
+										"type": "number"
+									},
+									"penalty": {
+										"description": "0 is healthy, 1 is worst",
+										"maximum": 1,
+										"minimum": 0,
+										"type": "number"
+									}
+								},
+								"type": "object"
+							},
+							"type": "object"
+						},
+						"computed": {
+							"format": "date-time",
+							"type": "string"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readAssetLimits": {
+			"description": "Returns the limits in effect for an asset and where each comes from",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readAssetLimits function",
+					"enum": [
+						"readAssetLimits"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "Limits in effect for an asset, each taken from its nameplate, else its model, else the contract default",
+					"properties": {
+						"assetID": {
+							"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"type": "string"
+						},
+						"model": {
+							"type": "string"
+						},
+						"ratedLoad": {
+							"type": "number"
+						},
+						"ratedSpeed": {
+							"type": "number"
+						},
+						"minTemperature": {
+							"type": "number"
+						},
+						"maxTemperature": {
+							"type": "number"
+						},
+						"maintenanceIntervalDays": {
+							"type": "integer"
+						},
+						"nextMaintenance": {
+							"description": "Last closed work order, or the commissioning date when there is none, plus the maintenance interval",
+							"format": "date-time",
+							"type": "string"
+						},
+						"sources": {
+							"additionalProperties": {
+								"enum": [
+									"asset",
+									"model",
+									"default"
+								],
+								"type": "string"
+							},
+							"description": "Where each limit comes from",
+							"type": "object"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readAssetProvenance": {
+			"description": "Returns the writer identity, transaction, device and time of the last write of each field of an asset's state",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"fields": {
+								"description": "Field names to return, all when left out",
+								"items": {
+									"type": "string"
+								},
+								"type": "array"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readAssetProvenance function",
+					"enum": [
+						"readAssetProvenance"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "Who and what last wrote each field of an asset's state. Fields computed by the contract, alarms and anomalies, have no entry.",
+					"properties": {
+						"assetID": {
+							"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"type": "string"
+						},
+						"fields": {
+							"additionalProperties": {
+								"description": "The write that set the current value of a field",
+								"properties": {
+									"writer": {
+										"description": "Enrollment identity from the caller's certificate attribute enrollmentId, left out when it carries none",
+										"type": "string"
+									},
+									"txID": {
+										"description": "Transaction that wrote the value",
+										"type": "string"
+									},
+									"deviceID": {
+										"description": "Device the value came from, as passed with the update",
+										"type": "string"
+									},
+									"timestamp": {
+										"description": "Transaction time of the write",
+										"format": "date-time",
+										"type": "string"
+									}
+								},
+								"type": "object"
+							},
+							"description": "Provenance keyed by field name",
+							"type": "object"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readAssetSamples": {
+			"description": "Returns a string generated from the schema containing sample Objects as specified in generate.json in the scripts folder.",
+			"properties": {
+				"args": {
+					"description": "accepts no arguments",
+					"items": {},
+					"maxItems": 0,
+					"minItems": 0,
+					"type": "array"
+				},
+				"function": {
+					"description": "readAssetSamples function",
+					"enum": [
+						"readAssetSamples"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "JSON encoded object containing selected sample data",
+					"type": "string"
+				}
+			},
+			"type": "object"
+		},
+		"readAssetSchemas": {
+			"description": "Returns a string generated from the schema containing APIs and Objects as specified in generate.json in the scripts folder.",
+			"properties": {
+				"args": {
+					"description": "accepts no arguments",
+					"items": {},
+					"maxItems": 0,
+					"minItems": 0,
+					"type": "array"
+				},
+				"function": {
+					"description": "readAssetSchemas function",
+					"enum": [
+						"readAssetSchemas"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "JSON encoded object containing selected schemas",
+					"type": "string"
+				}
+			},
+			"type": "object"
+		},
+		"readAssetUsage": {
+			"description": "Returns the usage counters of an asset. Argument is a JSON encoded string. AssetID is the only accepted property.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"description": "An object containing only an assetID for use as an argument to read or delete.",
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readAssetUsage function",
+					"enum": [
+						"readAssetUsage"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "Usage counters derived from successive asset state updates.",
+					"properties": {
+						"assetID": {
+							"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"type": "string"
+						},
+						"totalTrips": {
+							"description": "Number of completed trips, counted each time a moving car stops.",
+							"type": "integer"
+						},
+						"floorsTravelled": {
+							"description": "Total number of floors travelled.",
+							"type": "integer"
+						},
+						"doorCycles": {
+							"description": "Number of completed door open / close cycles.",
+							"type": "integer"
+						},
+						"runningHours": {
+							"description": "Time the car spent moving, in hours.",
+							"type": "number"
+						},
+						"lastUpdated": {
+							"description": "Transaction time of the last state update, RFC3339.",
+							"format": "date-time",
+							"type": "string"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readAssetsByIndex": {
+			"description": "List the assetIDs in a building, of a model, in an alarm, in any alarm when no value is passed, or with a certificate that expired or expires on or before a date. Index entries are kept in the transaction that changes the asset.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"index": {
+								"enum": [
+									"building",
+									"model",
+									"alarm",
+									"certificateExpiry"
+								],
+								"type": "string"
+							},
+							"value": {
+								"description": "The building, the model, the alarm, or for certificateExpiry an RFC3339 timestamp or plain date",
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"index"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readAssetsByIndex function",
+					"enum": [
+						"readAssetsByIndex"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"items": {
+						"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+						"type": "string"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"readAvailabilityReport": {
+			"description": "Returns uptime percentage, MTBF and MTTR of an asset or a building over [from, to). The period ends at the transaction time, or earlier when to is passed.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"building": {
+								"type": "string"
+							},
+							"from": {
+								"description": "RFC3339 timestamp or a plain date such as 2016-09-01",
+								"type": "string"
+							},
+							"to": {
+								"description": "RFC3339 timestamp or a plain date such as 2016-10-01",
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"from"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readAvailabilityReport function",
+					"enum": [
+						"readAvailabilityReport"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "Availability KPIs of an asset, or of a building summed over its assets with each asset listed.",
+					"properties": {
+						"assetID": {
+							"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"type": "string"
+						},
+						"uptimePercent": {
+							"type": "number"
+						},
+						"uptimeHours": {
+							"type": "number"
+						},
+						"downtimeHours": {
+							"description": "Time out of service, through the outOfService operating mode or an outOfService work order. Overlapping intervals count once.",
+							"type": "number"
+						},
+						"failures": {
+							"description": "Out of service intervals starting in the period.",
+							"type": "integer"
+						},
+						"mtbfHours": {
+							"description": "Mean time between failures, set when there were failures.",
+							"type": "number"
+						},
+						"mttrHours": {
+							"description": "Mean time to repair, set when there were failures.",
+							"type": "number"
+						},
+						"building": {
+							"type": "string"
+						},
+						"from": {
+							"format": "date-time",
+							"type": "string"
+						},
+						"to": {
+							"format": "date-time",
+							"type": "string"
+						},
+						"assets": {
+							"items": {
+								"properties": {
+									"assetID": {
+										"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+										"type": "string"
+									},
+									"uptimePercent": {
+										"type": "number"
+									},
+									"uptimeHours": {
+										"type": "number"
+									},
+									"downtimeHours": {
+										"description": "Time out of service, through the outOfService operating mode or an outOfService work order. Overlapping intervals count once.",
+										"type": "number"
+									},
+									"failures": {
+										"description": "Out of service intervals starting in the period.",
+										"type": "integer"
+									},
+									"mtbfHours": {
+										"description": "Mean time between failures, set when there were failures.",
+										"type": "number"
+									},
+									"mttrHours": {
+										"description": "Mean time to repair, set when there were failures.",
+										"type": "number"
+									}
+								},
+								"type": "object"
+							},
+							"type": "array"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readCertificationStatus": {
+			"description": "Returns the certificate status of an asset.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readCertificationStatus function",
+					"enum": [
+						"readCertificationStatus"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "The current safety certificate of an asset evaluated at the transaction time.",
+					"properties": {
+						"assetID": {
+							"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"type": "string"
+						},
+						"inspectionID": {
+							"description": "Inspection that issued the certificate.",
+							"type": "string"
+						},
+						"inspector": {
+							"description": "Inspector that issued the certificate.",
+							"type": "string"
+						},
+						"certificateExpiry": {
+							"type": "string"
+						},
+						"lastInspection": {
+							"description": "Date of the most recent inspection, passed or not.",
+							"type": "string"
+						},
+						"lastResult": {
+							"enum": [
+								"pass",
+								"passWithDefects",
+								"fail"
+							],
+							"type": "string"
+						},
+						"status": {
+							"description": "Assets that never passed an inspection are reported as expired.",
+							"enum": [
+								"valid",
+								"expiring",
+								"expired"
+							],
+							"type": "string"
+						},
+						"daysRemaining": {
+							"description": "Days until the certificate expires, negative once expired.",
+							"type": "integer"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readContractState": {
+			"description": "Returns the contract version, configuration and last migration with the number of assets and active alarms",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 0,
+					"type": "array"
+				},
+				"function": {
+					"description": "readContractState function",
+					"enum": [
+						"readContractState"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "The contract version, the configuration set at init and changed with updateContractConfig, the last migration and the number of assets and active alarms",
+					"properties": {
+						"nickname": {
+							"default": "ELEVATOR",
+							"description": "The nickname of the current contract",
+							"type": "string"
+						},
+						"version": {
+							"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"type": "string"
+						},
+						"anomalyZScore": {
+							"default": 3,
+							"description": "z-score above which a telemetry reading is flagged as an anomaly",
+							"type": "number"
+						},
+						"units": {
+							"description": "Units readings and limits are recorded in. Readings are not converted, the default maximum temperature follows the temperature unit.",
+							"properties": {
+								"weight": {
+									"default": "lb",
+									"enum": [
+										"lb",
+										"kg"
+									],
+									"type": "string"
+								},
+								"temperature": {
+									"default": "F",
+									"enum": [
+										"F",
+										"C"
+									],
+									"type": "string"
+								},
+								"speed": {
+									"default": "fpm",
+									"description": "feet per minute or meters per second",
+									"enum": [
+										"fpm",
+										"mps"
+									],
+									"type": "string"
+								}
+							},
+							"type": "object"
+						},
+						"retention": {
+							"description": "Days records are kept, older records are removed when the asset is next updated. When a window is left out asset history is kept 365 days and other records forever.",
+							"properties": {
+								"historyDays": {
+									"description": "asset history",
+									"minimum": 1,
+									"type": "integer",
+									"default": 365
+								},
+								"telemetryDays": {
+									"description": "hourly and daily telemetry buckets",
+									"minimum": 1,
+									"type": "integer"
+								},
+								"anomalyDays": {
+									"description": "anomaly records",
+									"minimum": 1,
+									"type": "integer"
+								}
+							},
+							"type": "object"
+						},
+						"validationMode": {
+							"default": "lenient",
+							"description": "strict rejects asset state fields the contract does not know, nested ones included, and the fields it computes: alarms, anomalies and deviceID. lenient ignores them",
+							"enum": [
+								"strict",
+								"lenient"
+							],
+							"type": "string"
+						},
+						"features": {
+							"description": "Features switched on or off, a feature is on unless set false",
+							"properties": {
+								"anomalyDetection": {
+									"type": "boolean"
+								},
+								"telemetry": {
+									"type": "boolean"
+								},
+								"alarmWorkOrders": {
+									"type": "boolean"
+								},
+								"provenance": {
+									"type": "boolean"
+								}
+							},
+							"additionalProperties": false,
+							"type": "object"
+						},
+						"updated": {
+							"description": "Time the configuration was last changed",
+							"format": "date-time",
+							"type": "string"
+						},
+						"lastMigration": {
+							"description": "The last run of init, on deployment or upgrade",
+							"properties": {
+								"fromVersion": {
+									"description": "Version stored before, left out on first deployment",
+									"type": "string"
+								},
+								"toVersion": {
+									"type": "string"
+								},
+								"txID": {
+									"type": "string"
+								},
+								"timestamp": {
+									"format": "date-time",
+									"type": "string"
+								}
+							},
+							"type": "object"
+						},
+						"assets": {
+							"description": "Entries of the asset registry",
+							"type": "integer"
+						},
+						"activeAlarms": {
+							"description": "Alarms active on assets, an asset in two alarms counts twice",
+							"type": "integer"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readEnergyConsumption": {
+			"description": "Returns kWh consumed by an asset, or by every asset in a building, per day or calendar month in [from, to). Whole days are reported. Consumption over a gap between meter readings is spread over the gap in proportion to time.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"building": {
+								"type": "string"
+							},
+							"from": {
+								"description": "RFC3339 timestamp or a plain date such as 2016-09-01",
+								"type": "string"
+							},
+							"to": {
+								"description": "RFC3339 timestamp or a plain date such as 2016-10-01. Defaults to the transaction time.",
+								"type": "string"
+							},
+							"period": {
+								"default": "month",
+								"enum": [
+									"day",
+									"month"
+								],
+								"type": "string"
+							},
+							"tariff": {
+								"description": "Optional rate per kWh used to compute costs",
+								"type": "number"
+							},
+							"currency": {
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"from"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readEnergyConsumption function",
+					"enum": [
+						"readEnergyConsumption"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "Energy consumed by an asset or a building per billing period. Costs are set when a tariff was passed.",
+					"properties": {
+						"assetID": {
+							"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"type": "string"
+						},
+						"building": {
+							"type": "string"
+						},
+						"period": {
+							"enum": [
+								"day",
+								"month"
+							],
+							"type": "string"
+						},
+						"tariff": {
+							"description": "Rate per kWh",
+							"type": "number"
+						},
+						"currency": {
+							"type": "string"
+						},
+						"periods": {
+							"items": {
+								"properties": {
+									"start": {
+										"format": "date-time",
+										"type": "string"
+									},
+									"end": {
+										"format": "date-time",
+										"type": "string"
+									},
+									"kwh": {
+										"type": "number"
+									},
+									"cost": {
+										"type": "number"
+									}
+								},
+								"type": "object"
+							},
+							"type": "array"
+						},
+						"totalKwh": {
+							"type": "number"
+						},
+						"totalCost": {
+							"type": "number"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readExpiringCertificates": {
+			"description": "Returns the certificate status of every asset whose certificate has expired or expires within withinDays days.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"withinDays": {
+								"default": 30,
+								"type": "integer"
+							}
+						},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 0,
+					"type": "array"
+				},
+				"function": {
+					"description": "readExpiringCertificates function",
+					"enum": [
+						"readExpiringCertificates"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"items": {
+						"description": "The current safety certificate of an asset evaluated at the transaction time.",
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"inspectionID": {
+								"description": "Inspection that issued the certificate.",
+								"type": "string"
+							},
+							"inspector": {
+								"description": "Inspector that issued the certificate.",
+								"type": "string"
+							},
+							"certificateExpiry": {
+								"type": "string"
+							},
+							"lastInspection": {
+								"description": "Date of the most recent inspection, passed or not.",
+								"type": "string"
+							},
+							"lastResult": {
+								"enum": [
+									"pass",
+									"passWithDefects",
+									"fail"
+								],
+								"type": "string"
+							},
+							"status": {
+								"description": "Assets that never passed an inspection are reported as expired.",
+								"enum": [
+									"valid",
+									"expiring",
+									"expired"
+								],
+								"type": "string"
+							},
+							"daysRemaining": {
+								"description": "Days until the certificate expires, negative once expired.",
+								"type": "integer"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"readHealthWeights": {
+			"description": "Returns the weights of the health score components.",
+			"properties": {
+				"args": {
+					"description": "accepts no arguments",
+					"items": {},
+					"maxItems": 0,
+					"minItems": 0,
+					"type": "array"
+				},
+				"function": {
+					"description": "readHealthWeights function",
+					"enum": [
+						"readHealthWeights"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "Relative weights of the health score components. Defaults apply until weights are stored.",
+					"properties": {
+						"temperature": {
+							"description": "Temperature, worst at the alarm threshold. Default 20.",
+							"type": "number"
+						},
+						"speed": {
+							"description": "Speed reading flagged as anomalous. Default 10.",
+							"type": "number"
+						},
+						"power": {
+							"description": "Power reading flagged as anomalous. Default 10.",
+							"type": "number"
+						},
+						"cpu": {
+							"description": "Controller cpu load above 50%. Default 5.",
+							"type": "number"
+						},
+						"memory": {
+							"description": "Controller memory use above 50%. Default 5.",
+							"type": "number"
+						},
+						"usage": {
+							"description": "Door cycles against the rated door equipment life. Default 20.",
+							"type": "number"
+						},
+						"alarms": {
+							"description": "Active alarms, alarm work orders and anomalies in the last 30 days, worst at three. Default 30.",
+							"type": "number"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readIncidents": {
+			"description": "Returns the incidents of an asset, of a building, or of all assets, that started in [from, to). All properties are optional, assetID and building exclude each other.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"building": {
+								"type": "string"
+							},
+							"from": {
+								"description": "RFC3339 timestamp or a plain date such as 2016-09-01",
+								"type": "string"
+							},
+							"to": {
+								"description": "RFC3339 timestamp or a plain date such as 2016-10-01",
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 0,
+					"type": "array"
+				},
+				"function": {
+					"description": "readIncidents function",
+					"enum": [
+						"readIncidents"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"items": {
+						"description": "A reportable event on an asset. Reporting or updating an incident emits an incident event.",
+						"properties": {
+							"incidentID": {
+								"description": "The ID of the incident, unique per asset. Defaults to the transaction ID when reporting.",
+								"type": "string"
+							},
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"building": {
+								"description": "Building of the asset when the incident was reported.",
+								"type": "string"
+							},
+							"type": {
+								"enum": [
+									"entrapment",
+									"alarmButton",
+									"emergencyStop",
+									"fireServiceRecall"
+								],
+								"type": "string"
+							},
+							"severity": {
+								"enum": [
+									"low",
+									"medium",
+									"high",
+									"critical"
+								],
+								"type": "string"
+							},
+							"description": {
+								"type": "string"
+							},
+							"start": {
+								"description": "Start of the incident, defaults to the transaction time. RFC3339 timestamp.",
+								"type": "string"
+							},
+							"end": {
+								"description": "End of the incident, empty while it is ongoing. RFC3339 timestamp.",
+								"type": "string"
+							},
+							"responder": {
+								"type": "string"
+							},
+							"resolution": {
+								"type": "string"
+							},
+							"reported": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"updated": {
+								"format": "date-time",
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"readInspections": {
+			"description": "Returns the inspections recorded for an asset.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readInspections function",
+					"enum": [
+						"readInspections"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"items": {
+						"description": "A periodic safety inspection of an asset.",
+						"properties": {
+							"inspectionID": {
+								"description": "The ID of the inspection, unique per asset. Defaults to the transaction ID.",
+								"type": "string"
+							},
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"inspector": {
+								"description": "Enrollment identity of the caller that recorded the inspection.",
+								"type": "string"
+							},
+							"date": {
+								"description": "Date the inspection took place. RFC3339 timestamp or a plain date such as 2016-09-21",
+								"type": "string"
+							},
+							"result": {
+								"enum": [
+									"pass",
+									"passWithDefects",
+									"fail"
+								],
+								"type": "string"
+							},
+							"defects": {
+								"description": "Defects found during the inspection.",
+								"items": {
+									"type": "string"
+								},
+								"type": "array"
+							},
+							"certificateExpiry": {
+								"description": "Expiry of the certificate issued by a passed inspection, ignored when failed. RFC3339 timestamp or a plain date such as 2016-09-21",
+								"type": "string"
+							},
+							"recorded": {
+								"format": "date-time",
+								"type": "string",
+								"description": "Transaction time the inspection was recorded."
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"readModels": {
+			"description": "Returns the model catalogue, or a single model when one is passed",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"model": {
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 0,
+					"type": "array"
+				},
+				"function": {
+					"description": "readModels function",
+					"enum": [
+						"readModels"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"oneOf": [
+						{
+							"description": "Manufacturer specifications of an elevator model, the default limits of every asset whose nameplate names the model",
+							"properties": {
+								"model": {
+									"description": "Model name as it appears on asset nameplates",
+									"type": "string"
+								},
+								"manufacturer": {
+									"type": "string"
+								},
+								"ratedLoad": {
+									"description": "Rated load in Lb",
+									"type": "number"
+								},
+								"ratedSpeed": {
+									"description": "Rated speed in feet/minute",
+									"type": "number"
+								},
+								"minTemperature": {
+									"description": "Lowest operating temperature in Fahrenheit",
+									"type": "number"
+								},
+								"maxTemperature": {
+									"description": "Highest operating temperature in Fahrenheit",
+									"type": "number"
+								},
+								"maintenanceIntervalDays": {
+									"description": "Days between maintenance visits",
+									"type": "integer"
+								},
+								"updated": {
+									"format": "date-time",
+									"type": "string"
+								}
+							},
+							"type": "object"
+						},
+						{
+							"items": {
+								"description": "Manufacturer specifications of an elevator model, the default limits of every asset whose nameplate names the model",
+								"properties": {
+									"model": {
+										"description": "Model name as it appears on asset nameplates",
+										"type": "string"
+									},
+									"manufacturer": {
+										"type": "string"
+									},
+									"ratedLoad": {
+										"description": "Rated load in Lb",
+										"type": "number"
+									},
+									"ratedSpeed": {
+										"description": "Rated speed in feet/minute",
+										"type": "number"
+									},
+									"minTemperature": {
+										"description": "Lowest operating temperature in Fahrenheit",
+										"type": "number"
+									},
+									"maxTemperature": {
+										"description": "Highest operating temperature in Fahrenheit",
+										"type": "number"
+									},
+									"maintenanceIntervalDays": {
+										"description": "Days between maintenance visits",
+										"type": "integer"
+									},
+									"updated": {
+										"format": "date-time",
+										"type": "string"
+									}
+								},
+								"type": "object"
+							},
+							"type": "array"
+						}
+					]
+				}
+			},
+			"type": "object"
+		},
+		"readNameplate": {
+			"description": "Returns the nameplate of an asset",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readNameplate function",
+					"enum": [
+						"readNameplate"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "Static nameplate data of an asset, kept apart from its changing state",
+					"properties": {
+						"assetID": {
+							"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"type": "string"
+						},
+						"manufacturer": {
+							"type": "string"
+						},
+						"model": {
+							"description": "Assets can be listed by model with readAssetsByIndex",
+							"type": "string"
+						},
+						"serialNumber": {
+							"type": "string"
+						},
+						"ratedLoad": {
+							"description": "Rated load in Lb. A higher weight raises the overload alarm.",
+							"type": "number"
+						},
+						"ratedSpeed": {
+							"description": "Rated speed in feet/minute. A higher speed raises the overspeed alarm.",
+							"type": "number"
+						},
+						"floorsServed": {
+							"description": "Number of floors served, at least 2",
+							"type": "integer"
+						},
+						"installationDate": {
+							"description": "RFC3339 timestamp or a plain date such as 2016-09-01",
+							"type": "string"
+						},
+						"commissioningDate": {
+							"description": "Date the elevator entered service, not before the installation date",
+							"type": "string"
+						},
+						"updated": {
+							"format": "date-time",
+							"type": "string"
+						},
+						"minTemperature": {
+							"description": "Lowest operating temperature in Fahrenheit, overrides the model. A lower temperature raises the temperature alarm.",
+							"type": "number"
+						},
+						"maxTemperature": {
+							"description": "Highest operating temperature in Fahrenheit, overrides the model. A higher temperature raises the temperature alarm.",
+							"type": "number"
+						},
+						"maintenanceIntervalDays": {
+							"description": "Days between maintenance visits, overrides the model",
+							"type": "integer"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readProposals": {
+			"description": "Returns the proposed operations, optionally only those in a given status",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"status": {
+								"enum": [
+									"pending",
+									"executed",
+									"expired"
+								],
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 0,
+					"type": "array"
+				},
+				"function": {
+					"description": "readProposals function",
+					"enum": [
+						"readProposals"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"items": {
+						"description": "An operation waiting for approvals, executed once enough organizations approved it",
+						"properties": {
+							"proposalID": {
+								"description": "The transaction ID of proposeOperation",
+								"type": "string"
+							},
+							"operation": {
+								"enum": [
+									"deleteAsset",
+									"setModel",
+									"setNameplate",
+									"setApprovalPolicy",
+									"init"
+								],
+								"type": "string"
+							},
+							"args": {
+								"description": "The JSON argument the operation runs with",
+								"type": "object"
+							},
+							"note": {
+								"type": "string"
+							},
+							"proposedBy": {
+								"description": "Organization of the proposer, also its first approval",
+								"type": "string"
+							},
+							"required": {
+								"description": "Approvals the policy required when proposed",
+								"type": "integer"
+							},
+							"approvals": {
+								"items": {
+									"properties": {
+										"organization": {
+											"type": "string"
+										},
+										"timestamp": {
+											"format": "date-time",
+											"type": "string"
+										}
+									},
+									"type": "object"
+								},
+								"type": "array"
+							},
+							"status": {
+								"description": "A pending proposal past its expiry is reported as expired",
+								"enum": [
+									"pending",
+									"executed",
+									"expired"
+								],
+								"type": "string"
+							},
+							"created": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"expires": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"executed": {
+								"format": "date-time",
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"readSLA": {
+			"description": "Returns the SLA terms that apply to an asset, or those of a building. Argument contains either an assetID or a building.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"building": {
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readSLA function",
+					"enum": [
+						"readSLA"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "Service level agreement terms between an owner and a maintenance provider, for an asset or a building. Asset terms take precedence over building terms. Limits that are not set are not tracked.",
+					"properties": {
+						"assetID": {
+							"description": "Set for asset terms. Exactly one of assetID and building.",
+							"type": "string"
+						},
+						"building": {
+							"description": "Set for building terms. Exactly one of assetID and building.",
+							"type": "string"
+						},
+						"owner": {
+							"type": "string"
+						},
+						"provider": {
+							"description": "Maintenance provider",
+							"type": "string"
+						},
+						"responseTimeHours": {
+							"description": "Maximum time from opening a work order until work starts.",
+							"type": "number"
+						},
+						"maxDowntimeHoursPerMonth": {
+							"description": "Out of service allowance per asset and calendar month, prorated for partial months.",
+							"type": "number"
+						},
+						"responsePenalty": {
+							"description": "Penalty per late response.",
+							"type": "number"
+						},
+						"downtimePenaltyPerHour": {
+							"description": "Penalty per hour out of service above the allowance.",
+							"type": "number"
+						},
+						"currency": {
+							"type": "string"
+						},
+						"updated": {
+							"format": "date-time",
+							"type": "string"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readSLAReport": {
+			"description": "Returns SLA breaches and accrued penalties of an asset, or of every asset in a building, for the billing period [from, to). The period ends at the transaction time when to is not passed.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"building": {
+								"type": "string"
+							},
+							"from": {
+								"description": "RFC3339 timestamp or a plain date such as 2016-09-01",
+								"type": "string"
+							},
+							"to": {
+								"description": "RFC3339 timestamp or a plain date such as 2016-10-01",
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"from"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readSLAReport function",
+					"enum": [
+						"readSLAReport"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"description": "SLA breaches and accrued penalties for a billing period.",
+					"properties": {
+						"assetID": {
+							"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"type": "string"
+						},
+						"building": {
+							"type": "string"
+						},
+						"from": {
+							"format": "date-time",
+							"type": "string"
+						},
+						"to": {
+							"format": "date-time",
+							"type": "string"
+						},
+						"compliant": {
+							"type": "boolean"
+						},
+						"breaches": {
+							"items": {
+								"properties": {
+									"type": {
+										"enum": [
+											"responseTime",
+											"downtime"
+										],
+										"type": "string"
+									},
+									"assetID": {
+										"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+										"type": "string"
+									},
+									"workOrderID": {
+										"description": "Late work order, responseTime breaches only.",
+										"type": "string"
+									},
+									"provider": {
+										"description": "Provider responsible for the late work order, responseTime breaches only",
+										"type": "string"
+									},
+									"month": {
+										"description": "Calendar month as 2006-01, downtime breaches only.",
+										"type": "string"
+									},
+									"limitHours": {
+										"type": "number"
+									},
+									"actualHours": {
+										"type": "number"
+									},
+									"penalty": {
+										"type": "number"
+									}
+								},
+								"type": "object"
+							},
+							"type": "array"
+						},
+						"totalPenalty": {
+							"type": "number"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"readTransfers": {
+			"description": "Returns the maintenance provider transfers of an asset, pending and past. Transfers are kept when the asset is deleted.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readTransfers function",
+					"enum": [
+						"readTransfers"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"items": {
+						"description": "Handover of the maintenance of an asset from one provider to another",
+						"properties": {
+							"transferID": {
+								"description": "The transaction ID of proposeTransfer",
+								"type": "string"
+							},
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"from": {
+								"description": "Provider handing over, left out for an asset without provider",
+								"type": "string"
+							},
+							"to": {
+								"description": "Provider taking over",
+								"type": "string"
+							},
+							"proposedBy": {
+								"description": "Organisation of the caller that proposed the transfer",
+								"type": "string"
+							},
+							"status": {
+								"enum": [
+									"pending",
+									"accepted",
+									"cancelled"
+								],
+								"type": "string"
+							},
+							"checklist": {
+								"description": "Handover checklist, every item is done once accepted",
+								"items": {
+									"properties": {
+										"item": {
+											"type": "string"
+										},
+										"done": {
+											"type": "boolean"
+										},
+										"note": {
+											"type": "string"
+										}
+									},
+									"required": [
+										"item"
+									],
+									"type": "object"
+								},
+								"type": "array"
+							},
+							"note": {
+								"type": "string"
+							},
+							"proposed": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"closed": {
+								"description": "Transaction time of the acceptance or cancellation",
+								"format": "date-time",
+								"type": "string"
+							},
+							"snapshot": {
+								"description": "State of the asset when the transfer took effect",
+								"properties": {
+									"assetID": {
+										"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+										"type": "string"
+									},
+									"building": {
+										"description": "The building the elevator is installed in. SLA terms and reports may be set per building.",
+										"type": "string"
+									},
+									"owner": {
+										"description": "Organisation owning the elevator. Only callers with the role attribute admin set or change it.",
+										"type": "string"
+									},
+									"provider": {
+										"description": "Organisation maintaining the elevator. Only callers with the role attribute admin set it, once set it only changes through proposeTransfer and acceptTransfer. Work orders are opened for the current provider.",
+										"type": "string"
+									},
+									"weight": {
+										"description": "Weight of the Asset in Lb",
+										"type": "number"
+									},
+									"system": {
+										"description": "Properties of micro computer installed in the elevator",
+										"properties": {
+											"cpu": {
+												"type": "number"
+											},
+											"memory": {
+												"type": "number"
+											}
+										},
+										"type": "object"
+									},
+									"temperature": {
+										"description": "Temperature of the asset in Fahrenheit.",
+										"type": "number"
+									},
+									"speed": {
+										"description": "Speed of the asset in feet/minute.",
+										"type": "number"
+									},
+									"power": {
+										"description": "Power consumption by the asset in KwH.",
+										"type": "number"
+									},
+									"energyMeter": {
+										"description": "Cumulative energy meter reading in kWh. Energy per billing period is accounted from successive readings and a lower reading is treated as a meter reset.",
+										"type": "number"
+									},
+									"floor": {
+										"description": "Floor the car is currently at or passing. Negative values are below ground level.",
+										"type": "integer"
+									},
+									"direction": {
+										"description": "Travel direction of the car.",
+										"enum": [
+											"up",
+											"down",
+											"stopped"
+										],
+										"type": "string"
+									},
+									"doorStatus": {
+										"description": "State of the car doors. The car may only move with the doors closed.",
+										"enum": [
+											"open",
+											"closing",
+											"closed",
+											"obstructed"
+										],
+										"type": "string"
+									},
+									"operatingMode": {
+										"description": "Operating mode of the elevator. The car may not move when out of service.",
+										"enum": [
+											"normal",
+											"inspection",
+											"fireService",
+											"outOfService"
+										],
+										"type": "string"
+									},
+									"alarms": {
+										"description": "Active alarms, computed by the contract on every update against the asset's limits, see readAssetLimits. temperature is raised outside the operating range, overload and overspeed above the rated load and speed. An alarm entered with an update opens a work order.",
+										"items": {
+											"enum": [
+												"temperature",
+												"overload",
+												"overspeed"
+											],
+											"type": "string"
+										},
+										"type": "array"
+									},
+									"anomalies": {
+										"description": "Telemetry fields whose latest reading deviated from the asset's rolling baseline by more than the configured z-score, computed by the contract. A field stays flagged until it is read again.",
+										"items": {
+											"enum": [
+												"temperature",
+												"speed",
+												"power",
+												"energy",
+												"system.cpu",
+												"system.memory"
+											],
+											"type": "string"
+										},
+										"type": "array"
+									}
+								},
+								"type": "object"
+							},
+							"workOrders": {
+								"description": "Open work orders moved to the new provider",
+								"items": {
+									"type": "string"
+								},
+								"type": "array"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"readWorkOrders": {
+			"description": "Returns the work orders of an asset, optionally filtered by status. Work orders are kept when the asset is deleted.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"status": {
+								"enum": [
+									"open",
+									"assigned",
+									"inProgress",
+									"onHold",
+									"closed"
+								],
+								"type": "string"
+							}
+						},
+						"required": [
+							"assetID"
+						],
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "readWorkOrders function",
+					"enum": [
+						"readWorkOrders"
+					],
+					"type": "string"
+				},
+				"method": "query",
+				"result": {
+					"items": {
+						"description": "A maintenance work order linked to an asset. Status moves open -> assigned -> inProgress <-> onHold -> closed, open and assigned orders may be closed directly.",
+						"properties": {
+							"workOrderID": {
+								"description": "The ID of a work order, unique per asset. Defaults to the transaction ID when opening.",
+								"type": "string"
+							},
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"status": {
+								"enum": [
+									"open",
+									"assigned",
+									"inProgress",
+									"onHold",
+									"closed"
+								],
+								"type": "string"
+							},
+							"source": {
+								"description": "manual, or alarm when opened automatically by createAsset or updateAsset",
+								"enum": [
+									"manual",
+									"alarm"
+								],
+								"type": "string"
+							},
+							"description": {
+								"type": "string"
+							},
+							"technician": {
+								"type": "string"
+							},
+							"provider": {
+								"description": "Maintenance provider responsible for the work order, moved to the new provider when an asset is transferred",
+								"type": "string"
+							},
+							"resolution": {
+								"type": "string"
+							},
+							"opened": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"assigned": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"started": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"closed": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"updated": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"notes": {
+								"items": {
+									"properties": {
+										"timestamp": {
+											"format": "date-time",
+											"type": "string"
+										},
+										"status": {
+											"type": "string"
+										},
+										"text": {
+											"type": "string"
+										}
+									},
+									"type": "object"
+								},
+								"type": "array"
+							},
+							"outOfService": {
+								"description": "Takes the asset out of service until the work order closes. Counts as downtime in availability and SLA reports.",
+								"type": "boolean"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"rebuildIndexes": {
+			"description": "Admin only, the caller's certificate must carry the role attribute admin. Recovers from index drift by deleting the building, model, alarm and certificateExpiry indexes and writing them again for the assets listed in the registry. Registry entries of assets that are gone are deleted.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 0,
+					"type": "array"
+				},
+				"function": {
+					"description": "rebuildIndexes function",
+					"enum": [
+						"rebuildIndexes"
+					],
+					"type": "string"
+				},
+				"method": "invoke",
+				"result": {
+					"description": "Entries written by rebuildIndexes",
+					"properties": {
+						"assets": {
+							"description": "Assets listed in the registry",
+							"type": "integer"
+						},
+						"entries": {
+							"description": "Entries written per index",
+							"additionalProperties": {
+								"type": "integer"
+							},
+							"type": "object"
+						},
+						"removed": {
+							"description": "Entries deleted before rebuilding, stale ones included",
+							"type": "integer"
+						}
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"recordInspection": {
+			"description": "Record a safety inspection of an existing asset. A passed inspection renews the asset's certificate. Only callers with the role attribute inspector or admin record inspections, the inspector recorded is the caller's certificate attribute enrollmentId.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"inspectionID": {
+								"description": "The ID of the inspection, unique per asset. Defaults to the transaction ID.",
+								"type": "string"
+							},
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"date": {
+								"description": "Date the inspection took place. RFC3339 timestamp or a plain date such as 2016-09-21",
+								"type": "string"
+							},
+							"result": {
+								"enum": [
+									"pass",
+									"passWithDefects",
+									"fail"
+								],
+								"type": "string"
+							},
+							"defects": {
+								"description": "Defects found during the inspection.",
+								"items": {
+									"type": "string"
+								},
+								"type": "array"
+							},
+							"certificateExpiry": {
+								"description": "Expiry of the certificate issued by a passed inspection, ignored when failed. RFC3339 timestamp or a plain date such as 2016-09-21",
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID",
+							"date",
+							"result"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "recordInspection function",
+					"enum": [
+						"recordInspection"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"reportIncident": {
+			"description": "Report an incident on an existing asset, or update the severity, description, end, responder and resolution of a reported incident. Type and severity are mandatory when reporting.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"incidentID": {
+								"description": "The ID of the incident, unique per asset. Defaults to the transaction ID when reporting.",
+								"type": "string"
+							},
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"type": {
+								"enum": [
+									"entrapment",
+									"alarmButton",
+									"emergencyStop",
+									"fireServiceRecall"
+								],
+								"type": "string"
+							},
+							"severity": {
+								"enum": [
+									"low",
+									"medium",
+									"high",
+									"critical"
+								],
+								"type": "string"
+							},
+							"description": {
+								"type": "string"
+							},
+							"start": {
+								"description": "Start of the incident, defaults to the transaction time. RFC3339 timestamp.",
+								"type": "string"
+							},
+							"end": {
+								"description": "End of the incident, empty while it is ongoing. RFC3339 timestamp.",
+								"type": "string"
+							},
+							"responder": {
+								"type": "string"
+							},
+							"resolution": {
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "reportIncident function",
+					"enum": [
+						"reportIncident"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"setApprovalPolicy": {
+			"description": "Admin only, the caller's certificate must carry the role attribute admin. Set or remove the approvals an operation needs before it runs. When an approval policy is set for it, it only runs through proposeOperation, approveOperation and executeOperation.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"operation": {
+								"enum": [
+									"deleteAsset",
+									"setModel",
+									"setNameplate",
+									"setApprovalPolicy",
+									"updateContractConfig",
+									"init"
+								],
+								"type": "string"
+							},
+							"approvals": {
+								"description": "Distinct organizations that must approve, including the proposer. 0 removes the policy.",
+								"type": "integer"
+							},
+							"organizations": {
+								"description": "Organizations that may propose and approve, any when left out",
+								"items": {
+									"type": "string"
+								},
+								"type": "array"
+							},
+							"expiryHours": {
+								"description": "Hours a proposal stays open, 72 when left out",
+								"type": "number"
+							}
+						},
+						"type": "object",
+						"required": [
+							"operation",
+							"approvals"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "setApprovalPolicy function",
+					"enum": [
+						"setApprovalPolicy"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"setConfidentialFields": {
+			"description": "Store the confidential fields of an asset, encrypted by the client. Admins set new records, keys and holders. The organisation holding the key may replace the ciphertext under the same key.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"keyID": {
+								"description": "Names the key the fields were encrypted with, chosen by the client. Never the key itself.",
+								"type": "string"
+							},
+							"nonce": {
+								"description": "Base64 nonce of the cipher, when it uses one",
+								"type": "string"
+							},
+							"ciphertext": {
+								"description": "Base64 encrypted JSON of the confidential fields",
+								"type": "string"
+							},
+							"organization": {
+								"description": "Organisation holding the key, which may read the record and replace the ciphertext under the same key. Defaults to the caller's organization attribute.",
+								"type": "string"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID",
+							"keyID",
+							"ciphertext"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "setConfidentialFields function",
+					"enum": [
+						"setConfidentialFields"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"setHealthWeights": {
+			"description": "Admin only, the caller's certificate must carry the role attribute admin. Store the weights of the health score components, replacing existing weights.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"temperature": {
+								"description": "Temperature, worst at the alarm threshold. Default 20.",
+								"type": "number"
+							},
+							"speed": {
+								"description": "Speed reading flagged as anomalous. Default 10.",
+								"type": "number"
+							},
+							"power": {
+								"description": "Power reading flagged as anomalous. Default 10.",
+								"type": "number"
+							},
+							"cpu": {
+								"description": "Controller cpu load above 50%. Default 5.",
+								"type": "number"
+							},
+							"memory": {
+								"description": "Controller memory use above 50%. Default 5.",
+								"type": "number"
+							},
+							"usage": {
+								"description": "Door cycles against the rated door equipment life. Default 20.",
+								"type": "number"
+							},
+							"alarms": {
+								"description": "Active alarms, alarm work orders and anomalies in the last 30 days, worst at three. Default 30.",
+								"type": "number"
+							}
+						},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "setHealthWeights function",
+					"enum": [
+						"setHealthWeights"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"setModel": {
+			"description": "Admin only, the caller's certificate must carry the role attribute admin. Add or replace a model in the catalogue. The alarms of every asset of the model are re-evaluated against the new limits. When an approval policy is set for it, it only runs through proposeOperation, approveOperation and executeOperation.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"model": {
+								"description": "Model name as it appears on asset nameplates",
+								"type": "string"
+							},
+							"manufacturer": {
+								"type": "string"
+							},
+							"ratedLoad": {
+								"description": "Rated load in Lb",
+								"type": "number"
+							},
+							"ratedSpeed": {
+								"description": "Rated speed in feet/minute",
+								"type": "number"
+							},
+							"minTemperature": {
+								"description": "Lowest operating temperature in Fahrenheit",
+								"type": "number"
+							},
+							"maxTemperature": {
+								"description": "Highest operating temperature in Fahrenheit",
+								"type": "number"
+							},
+							"maintenanceIntervalDays": {
+								"description": "Days between maintenance visits",
+								"type": "integer"
+							}
+						},
+						"type": "object",
+						"required": [
+							"model"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "setModel function",
+					"enum": [
+						"setModel"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"setNameplate": {
+			"description": "Admin only, the caller's certificate must carry the role attribute admin. Replace the nameplate of an existing asset. The asset's alarms are re-evaluated against the new limits. Ratings and limits set on the nameplate override those of the model in the catalogue. When an approval policy is set for it, it only runs through proposeOperation, approveOperation and executeOperation.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"manufacturer": {
+								"type": "string"
+							},
+							"model": {
+								"description": "Assets can be listed by model with readAssetsByIndex",
+								"type": "string"
+							},
+							"serialNumber": {
+								"type": "string"
+							},
+							"ratedLoad": {
+								"description": "Rated load in Lb. A higher weight raises the overload alarm.",
+								"type": "number"
+							},
+							"ratedSpeed": {
+								"description": "Rated speed in feet/minute. A higher speed raises the overspeed alarm.",
+								"type": "number"
+							},
+							"floorsServed": {
+								"description": "Number of floors served, at least 2",
+								"type": "integer"
+							},
+							"installationDate": {
+								"description": "RFC3339 timestamp or a plain date such as 2016-09-01",
+								"type": "string"
+							},
+							"commissioningDate": {
+								"description": "Date the elevator entered service, not before the installation date",
+								"type": "string"
+							},
+							"minTemperature": {
+								"description": "Lowest operating temperature in Fahrenheit, overrides the model. A lower temperature raises the temperature alarm.",
+								"type": "number"
+							},
+							"maxTemperature": {
+								"description": "Highest operating temperature in Fahrenheit, overrides the model. A higher temperature raises the temperature alarm.",
+								"type": "number"
+							},
+							"maintenanceIntervalDays": {
+								"description": "Days between maintenance visits, overrides the model",
+								"type": "integer"
+							}
+						},
+						"type": "object",
+						"required": [
+							"assetID"
+						]
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "setNameplate function",
+					"enum": [
+						"setNameplate"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"setSLA": {
+			"description": "Admin only, the caller's certificate must carry the role attribute admin. Store SLA terms for an asset or a building, replacing existing terms.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "Set for asset terms. Exactly one of assetID and building.",
+								"type": "string"
+							},
+							"building": {
+								"description": "Set for building terms. Exactly one of assetID and building.",
+								"type": "string"
+							},
+							"owner": {
+								"type": "string"
+							},
+							"provider": {
+								"description": "Maintenance provider",
+								"type": "string"
+							},
+							"responseTimeHours": {
+								"description": "Maximum time from opening a work order until work starts.",
+								"type": "number"
+							},
+							"maxDowntimeHoursPerMonth": {
+								"description": "Out of service allowance per asset and calendar month, prorated for partial months.",
+								"type": "number"
+							},
+							"responsePenalty": {
+								"description": "Penalty per late response.",
+								"type": "number"
+							},
+							"downtimePenaltyPerHour": {
+								"description": "Penalty per hour out of service above the allowance.",
+								"type": "number"
+							},
+							"currency": {
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "setSLA function",
+					"enum": [
+						"setSLA"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"updateAsset": {
+			"description": "Update the state of an asset. The one argument is a JSON encoded event. AssetID is required along with one or more writable properties. Establishes the next asset state. ",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"description": "A set of fields that constitute the writable fields in an asset's state. AssetID is mandatory along with at least one writable field. In this contract pattern, a partial state is used as an event.",
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"building": {
+								"description": "The building the elevator is installed in. SLA terms and reports may be set per building.",
+								"type": "string"
+							},
+							"owner": {
+								"description": "Organisation owning the elevator. Only callers with the role attribute admin set or change it.",
+								"type": "string"
+							},
+							"provider": {
+								"description": "Organisation maintaining the elevator. Only callers with the role attribute admin set it, once set it only changes through proposeTransfer and acceptTransfer. Work orders are opened for the current provider.",
+								"type": "string"
+							},
+							"weight": {
+								"description": "Weight of the Asset in Lb",
+								"type": "number"
+							},
+							"system": {
+								"description": "Properties of micro computer installed in the elevator",
+								"properties": {
+									"cpu": {
+										"type": "number"
+									},
+									"memory": {
+										"type": "number"
+									}
+								},
+								"type": "object"
+							},
+							"temperature": {
+								"description": "Temperature of the asset in Fahrenheit.",
+								"type": "number"
+							},
+							"speed": {
+								"description": "Speed of the asset in feet/minute.",
+								"type": "number"
+							},
+							"power": {
+								"description": "Power consumption by the asset in KwH.",
+								"type": "number"
+							},
+							"energyMeter": {
+								"description": "Cumulative energy meter reading in kWh. Energy per billing period is accounted from successive readings and a lower reading is treated as a meter reset.",
+								"type": "number"
+							},
+							"floor": {
+								"description": "Floor the car is currently at or passing. Negative values are below ground level.",
+								"type": "integer"
+							},
+							"direction": {
+								"description": "Travel direction of the car.",
+								"enum": [
+									"up",
+									"down",
+									"stopped"
+								],
+								"type": "string"
+							},
+							"doorStatus": {
+								"description": "State of the car doors. The car may only move with the doors closed.",
+								"enum": [
+									"open",
+									"closing",
+									"closed",
+									"obstructed"
+								],
+								"type": "string"
+							},
+							"operatingMode": {
+								"description": "Operating mode of the elevator. The car may not move when out of service.",
+								"enum": [
+									"normal",
+									"inspection",
+									"fireService",
+									"outOfService"
+								],
+								"type": "string"
+							},
+							"deviceID": {
+								"description": "The device the readings of this update came from. Recorded in the provenance of the fields the update sets, not stored in the state. Rejected in strict validation mode.",
+								"type": "string"
+							}
+						},
+						"required": [
+							"assetID"
+						],
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "updateAsset function",
+					"enum": [
+						"updateAsset"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"updateContractConfig": {
+			"description": "Admin only, the caller's certificate must carry the role attribute admin. Change the contract configuration set at init. Fields left out keep their value, features are merged with those already set. When an approval policy is set for it, it only runs through proposeOperation, approveOperation and executeOperation.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"nickname": {
+								"description": "The nickname of the current contract",
+								"type": "string"
+							},
+							"version": {
+								"description": "Optional, must match the deployed version, which only changes with init",
+								"type": "string"
+							},
+							"anomalyZScore": {
+								"description": "z-score above which a telemetry reading is flagged as an anomaly",
+								"type": "number"
+							},
+							"units": {
+								"description": "Units readings and limits are recorded in. Readings are not converted, the default maximum temperature follows the temperature unit.",
+								"properties": {
+									"weight": {
+										"default": "lb",
+										"enum": [
+											"lb",
+											"kg"
+										],
+										"type": "string"
+									},
+									"temperature": {
+										"default": "F",
+										"enum": [
+											"F",
+											"C"
+										],
+										"type": "string"
+									},
+									"speed": {
+										"default": "fpm",
+										"description": "feet per minute or meters per second",
+										"enum": [
+											"fpm",
+											"mps"
+										],
+										"type": "string"
+									}
+								},
+								"type": "object"
+							},
+							"retention": {
+								"description": "Days records are kept, older records are removed when the asset is next updated. When a window is left out asset history is kept 365 days and other records forever.",
+								"properties": {
+									"historyDays": {
+										"description": "asset history",
+										"minimum": 1,
+										"type": "integer",
+										"default": 365
+									},
+									"telemetryDays": {
+										"description": "hourly and daily telemetry buckets",
+										"minimum": 1,
+										"type": "integer"
+									},
+									"anomalyDays": {
+										"description": "anomaly records",
+										"minimum": 1,
+										"type": "integer"
+									}
+								},
+								"type": "object"
+							},
+							"validationMode": {
+								"description": "strict rejects asset state fields the contract does not know, nested ones included, and the fields it computes: alarms, anomalies and deviceID. lenient ignores them",
+								"enum": [
+									"strict",
+									"lenient"
+								],
+								"type": "string"
+							},
+							"features": {
+								"description": "Features switched on or off, a feature is on unless set false",
+								"properties": {
+									"anomalyDetection": {
+										"type": "boolean"
+									},
+									"telemetry": {
+										"type": "boolean"
+									},
+									"alarmWorkOrders": {
+										"type": "boolean"
+									},
+									"provenance": {
+										"type": "boolean"
+									}
+								},
+								"additionalProperties": false,
+								"type": "object"
+							}
+						},
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "updateContractConfig function",
+					"enum": [
+						"updateContractConfig"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		},
+		"updateWorkOrder": {
+			"description": "Move a work order between inProgress and onHold, change its description or add a note.",
+			"properties": {
+				"args": {
+					"description": "args are JSON encoded strings",
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"workOrderID": {
+								"description": "The ID of a work order, unique per asset. Defaults to the transaction ID when opening.",
+								"type": "string"
+							},
+							"status": {
+								"enum": [
+									"inProgress",
+									"onHold"
+								],
+								"type": "string"
+							},
+							"description": {
+								"type": "string"
+							},
+							"note": {
+								"description": "Optional note appended to the work order.",
+								"type": "string"
+							}
+						},
+						"required": [
+							"assetID",
+							"workOrderID"
+						],
+						"type": "object"
+					},
+					"maxItems": 1,
+					"minItems": 1,
+					"type": "array"
+				},
+				"function": {
+					"description": "updateWorkOrder function",
+					"enum": [
+						"updateWorkOrder"
+					],
+					"type": "string"
+				},
+				"method": "invoke"
+			},
+			"type": "object"
+		}
+	},
+	"objectModelSchemas": {
+		"assetIDKey": {
+			"description": "An object containing only an assetID for use as an argument to read or delete.",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				}
+			},
+			"type": "object"
+		},
+		"event": {
+			"description": "A set of fields that constitute the writable fields in an asset's state. AssetID is mandatory along with at least one writable field. In this contract pattern, a partial state is used as an event.",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"building": {
+					"description": "The building the elevator is installed in. SLA terms and reports may be set per building.",
+					"type": "string"
+				},
+				"owner": {
+					"description": "Organisation owning the elevator. Only callers with the role attribute admin set or change it.",
+					"type": "string"
+				},
+				"provider": {
+					"description": "Organisation maintaining the elevator. Only callers with the role attribute admin set it, once set it only changes through proposeTransfer and acceptTransfer. Work orders are opened for the current provider.",
+					"type": "string"
+				},
+				"weight": {
+					"description": "Weight of the Asset in Lb",
+					"type": "number"
+				},
+				"system": {
+					"description": "Properties of micro computer installed in the elevator",
+					"properties": {
+						"cpu": {
+							"type": "number"
+						},
+						"memory": {
+							"type": "number"
+						}
+					},
+					"type": "object"
+				},
+				"temperature": {
+					"description": "Temperature of the asset in Fahrenheit.",
+					"type": "number"
+				},
+				"speed": {
+					"description": "Speed of the asset in feet/minute.",
+					"type": "number"
+				},
+				"power": {
+					"description": "Power consumption by the asset in KwH.",
+					"type": "number"
+				},
+				"energyMeter": {
+					"description": "Cumulative energy meter reading in kWh. Energy per billing period is accounted from successive readings and a lower reading is treated as a meter reset.",
+					"type": "number"
+				},
+				"floor": {
+					"description": "Floor the car is currently at or passing. Negative values are below ground level.",
+					"type": "integer"
+				},
+				"direction": {
+					"description": "Travel direction of the car.",
+					"enum": [
+						"up",
+						"down",
+						"stopped"
+					],
+					"type": "string"
+				},
+				"doorStatus": {
+					"description": "State of the car doors. The car may only move with the doors closed.",
+					"enum": [
+						"open",
+						"closing",
+						"closed",
+						"obstructed"
+					],
+					"type": "string"
+				},
+				"operatingMode": {
+					"description": "Operating mode of the elevator. The car may not move when out of service.",
+					"enum": [
+						"normal",
+						"inspection",
+						"fireService",
+						"outOfService"
+					],
+					"type": "string"
+				}
+			},
+			"required": [
+				"assetID"
+			],
+			"type": "object"
+		},
+		"initEvent": {
+			"description": "event sent to init on deployment",
+			"properties": {
+				"nickname": {
+					"default": "ELEVATOR",
+					"description": "The nickname of the current contract",
+					"type": "string"
+				},
+				"version": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"anomalyZScore": {
+					"default": 3,
+					"description": "z-score above which a telemetry reading is flagged as an anomaly",
+					"type": "number"
+				},
+				"units": {
+					"description": "Units readings and limits are recorded in. Readings are not converted, the default maximum temperature follows the temperature unit.",
+					"properties": {
+						"weight": {
+							"default": "lb",
+							"enum": [
+								"lb",
+								"kg"
+							],
+							"type": "string"
+						},
+						"temperature": {
+							"default": "F",
+							"enum": [
+								"F",
+								"C"
+							],
+							"type": "string"
+						},
+						"speed": {
+							"default": "fpm",
+							"description": "feet per minute or meters per second",
+							"enum": [
+								"fpm",
+								"mps"
+							],
+							"type": "string"
+						}
+					},
+					"type": "object"
+				},
+				"retention": {
+					"description": "Days records are kept, older records are removed when the asset is next updated. When a window is left out asset history is kept 365 days and other records forever.",
+					"properties": {
+						"historyDays": {
+							"description": "asset history",
+							"minimum": 1,
+							"type": "integer",
+							"default": 365
+						},
+						"telemetryDays": {
+							"description": "hourly and daily telemetry buckets",
+							"minimum": 1,
+							"type": "integer"
+						},
+						"anomalyDays": {
+							"description": "anomaly records",
+							"minimum": 1,
+							"type": "integer"
+						}
+					},
+					"type": "object"
+				},
+				"validationMode": {
+					"default": "lenient",
+					"description": "strict rejects asset state fields the contract does not know, nested ones included, and the fields it computes: alarms, anomalies and deviceID. lenient ignores them",
+					"enum": [
+						"strict",
+						"lenient"
+					],
+					"type": "string"
+				},
+				"features": {
+					"description": "Features switched on or off, a feature is on unless set false",
+					"properties": {
+						"anomalyDetection": {
+							"type": "boolean"
+						},
+						"telemetry": {
+							"type": "boolean"
+						},
+						"alarmWorkOrders": {
+							"type": "boolean"
+						},
+						"provenance": {
+							"type": "boolean"
+						}
+					},
+					"additionalProperties": false,
+					"type": "object"
+				}
+			},
+			"required": [
+				"version"
+			],
+			"type": "object"
+		},
+		"state": {
+			"description": "A set of fields that constitute the complete asset state.",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"building": {
+					"description": "The building the elevator is installed in. SLA terms and reports may be set per building.",
+					"type": "string"
+				},
+				"owner": {
+					"description": "Organisation owning the elevator. Only callers with the role attribute admin set or change it.",
+					"type": "string"
+				},
+				"provider": {
+					"description": "Organisation maintaining the elevator. Only callers with the role attribute admin set it, once set it only changes through proposeTransfer and acceptTransfer. Work orders are opened for the current provider.",
+					"type": "string"
+				},
+				"weight": {
+					"description": "Weight of the Asset in Lb",
+					"type": "number"
+				},
+				"system": {
+					"description": "Properties of micro computer installed in the elevator",
+					"properties": {
+						"cpu": {
+							"type": "number"
+						},
+						"memory": {
+							"type": "number"
+						}
+					},
+					"type": "object"
+				},
+				"temperature": {
+					"description": "Temperature of the asset in Fahrenheit.",
+					"type": "number"
+				},
+				"speed": {
+					"description": "Speed of the asset in feet/minute.",
+					"type": "number"
+				},
+				"power": {
+					"description": "Power consumption by the asset in KwH.",
+					"type": "number"
+				},
+				"energyMeter": {
+					"description": "Cumulative energy meter reading in kWh. Energy per billing period is accounted from successive readings and a lower reading is treated as a meter reset.",
+					"type": "number"
+				},
+				"floor": {
+					"description": "Floor the car is currently at or passing. Negative values are below ground level.",
+					"type": "integer"
+				},
+				"direction": {
+					"description": "Travel direction of the car.",
+					"enum": [
+						"up",
+						"down",
+						"stopped"
+					],
+					"type": "string"
+				},
+				"doorStatus": {
+					"description": "State of the car doors. The car may only move with the doors closed.",
+					"enum": [
+						"open",
+						"closing",
+						"closed",
+						"obstructed"
+					],
+					"type": "string"
+				},
+				"operatingMode": {
+					"description": "Operating mode of the elevator. The car may not move when out of service.",
+					"enum": [
+						"normal",
+						"inspection",
+						"fireService",
+						"outOfService"
+					],
+					"type": "string"
+				},
+				"alarms": {
+					"description": "Active alarms, computed by the contract on every update against the asset's limits, see readAssetLimits. temperature is raised outside the operating range, overload and overspeed above the rated load and speed. An alarm entered with an update opens a work order.",
+					"items": {
+						"enum": [
+							"temperature",
+							"overload",
+							"overspeed"
+						],
+						"type": "string"
+					},
+					"type": "array"
+				},
+				"anomalies": {
+					"description": "Telemetry fields whose latest reading deviated from the asset's rolling baseline by more than the configured z-score, computed by the contract. A field stays flagged until it is read again.",
+					"items": {
+						"enum": [
+							"temperature",
+							"speed",
+							"power",
+							"energy",
+							"system.cpu",
+							"system.memory"
+						],
+						"type": "string"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"usage": {
+			"description": "Usage counters derived from successive asset state updates.",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"totalTrips": {
+					"description": "Number of completed trips, counted each time a moving car stops.",
+					"type": "integer"
+				},
+				"floorsTravelled": {
+					"description": "Total number of floors travelled.",
+					"type": "integer"
+				},
+				"doorCycles": {
+					"description": "Number of completed door open / close cycles.",
+					"type": "integer"
+				},
+				"runningHours": {
+					"description": "Time the car spent moving, in hours.",
+					"type": "number"
+				},
+				"lastUpdated": {
+					"description": "Transaction time of the last state update, RFC3339.",
+					"format": "date-time",
+					"type": "string"
+				}
+			},
+			"type": "object"
+		},
+		"workOrder": {
+			"description": "A maintenance work order linked to an asset. Status moves open -> assigned -> inProgress <-> onHold -> closed, open and assigned orders may be closed directly.",
+			"properties": {
+				"workOrderID": {
+					"description": "The ID of a work order, unique per asset. Defaults to the transaction ID when opening.",
+					"type": "string"
+				},
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"status": {
+					"enum": [
+						"open",
+						"assigned",
+						"inProgress",
+						"onHold",
+						"closed"
+					],
+					"type": "string"
+				},
+				"source": {
+					"description": "manual, or alarm when opened automatically by createAsset or updateAsset",
+					"enum": [
+						"manual",
+						"alarm"
+					],
+					"type": "string"
+				},
+				"description": {
+					"type": "string"
+				},
+				"technician": {
+					"type": "string"
+				},
+				"provider": {
+					"description": "Maintenance provider responsible for the work order, moved to the new provider when an asset is transferred",
+					"type": "string"
+				},
+				"resolution": {
+					"type": "string"
+				},
+				"opened": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"assigned": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"started": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"closed": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"updated": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"notes": {
+					"items": {
+						"properties": {
+							"timestamp": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"status": {
+								"type": "string"
+							},
+							"text": {
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				},
+				"outOfService": {
+					"description": "Takes the asset out of service until the work order closes. Counts as downtime in availability and SLA reports.",
+					"type": "boolean"
+				}
+			},
+			"type": "object"
+		},
+		"inspection": {
+			"description": "A periodic safety inspection of an asset.",
+			"properties": {
+				"inspectionID": {
+					"description": "The ID of the inspection, unique per asset. Defaults to the transaction ID.",
+					"type": "string"
+				},
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"inspector": {
+					"description": "Enrollment identity of the caller that recorded the inspection.",
+					"type": "string"
+				},
+				"date": {
+					"description": "Date the inspection took place. RFC3339 timestamp or a plain date such as 2016-09-21",
+					"type": "string"
+				},
+				"result": {
+					"enum": [
+						"pass",
+						"passWithDefects",
+						"fail"
+					],
+					"type": "string"
+				},
+				"defects": {
+					"description": "Defects found during the inspection.",
+					"items": {
+						"type": "string"
+					},
+					"type": "array"
+				},
+				"certificateExpiry": {
+					"description": "Expiry of the certificate issued by a passed inspection, ignored when failed. RFC3339 timestamp or a plain date such as 2016-09-21",
+					"type": "string"
+				},
+				"recorded": {
+					"format": "date-time",
+					"type": "string",
+					"description": "Transaction time the inspection was recorded."
+				}
+			},
+			"type": "object"
+		},
+		"certificationStatus": {
+			"description": "The current safety certificate of an asset evaluated at the transaction time.",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"inspectionID": {
+					"description": "Inspection that issued the certificate.",
+					"type": "string"
+				},
+				"inspector": {
+					"description": "Inspector that issued the certificate.",
+					"type": "string"
+				},
+				"certificateExpiry": {
+					"type": "string"
+				},
+				"lastInspection": {
+					"description": "Date of the most recent inspection, passed or not.",
+					"type": "string"
+				},
+				"lastResult": {
+					"enum": [
+						"pass",
+						"passWithDefects",
+						"fail"
+					],
+					"type": "string"
+				},
+				"status": {
+					"description": "Assets that never passed an inspection are reported as expired.",
+					"enum": [
+						"valid",
+						"expiring",
+						"expired"
+					],
+					"type": "string"
+				},
+				"daysRemaining": {
+					"description": "Days until the certificate expires, negative once expired.",
+					"type": "integer"
+				}
+			},
+			"type": "object"
+		},
+		"sla": {
+			"description": "Service level agreement terms between an owner and a maintenance provider, for an asset or a building. Asset terms take precedence over building terms. Limits that are not set are not tracked.",
+			"properties": {
+				"assetID": {
+					"description": "Set for asset terms. Exactly one of assetID and building.",
+					"type": "string"
+				},
+				"building": {
+					"description": "Set for building terms. Exactly one of assetID and building.",
+					"type": "string"
+				},
+				"owner": {
+					"type": "string"
+				},
+				"provider": {
+					"description": "Maintenance provider",
+					"type": "string"
+				},
+				"responseTimeHours": {
+					"description": "Maximum time from opening a work order until work starts.",
+					"type": "number"
+				},
+				"maxDowntimeHoursPerMonth": {
+					"description": "Out of service allowance per asset and calendar month, prorated for partial months.",
+					"type": "number"
+				},
+				"responsePenalty": {
+					"description": "Penalty per late response.",
+					"type": "number"
+				},
+				"downtimePenaltyPerHour": {
+					"description": "Penalty per hour out of service above the allowance.",
+					"type": "number"
+				},
+				"currency": {
+					"type": "string"
+				},
+				"updated": {
+					"format": "date-time",
+					"type": "string"
+				}
+			},
+			"type": "object"
+		},
+		"slaReport": {
+			"description": "SLA breaches and accrued penalties for a billing period.",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"building": {
+					"type": "string"
+				},
+				"from": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"to": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"compliant": {
+					"type": "boolean"
+				},
+				"breaches": {
+					"items": {
+						"properties": {
+							"type": {
+								"enum": [
+									"responseTime",
+									"downtime"
+								],
+								"type": "string"
+							},
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"workOrderID": {
+								"description": "Late work order, responseTime breaches only.",
+								"type": "string"
+							},
+							"provider": {
+								"description": "Provider responsible for the late work order, responseTime breaches only",
+								"type": "string"
+							},
+							"month": {
+								"description": "Calendar month as 2006-01, downtime breaches only.",
+								"type": "string"
+							},
+							"limitHours": {
+								"type": "number"
+							},
+							"actualHours": {
+								"type": "number"
+							},
+							"penalty": {
+								"type": "number"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				},
+				"totalPenalty": {
+					"type": "number"
+				}
+			},
+			"type": "object"
+		},
+		"telemetryBucket": {
+			"description": "Aggregated readings of an asset over one hour or UTC day. Only readings passed in an event count, values merged from the previous state do not.",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"granularity": {
+					"enum": [
+						"hour",
+						"day"
+					],
+					"type": "string"
+				},
+				"start": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"fields": {
+					"description": "Aggregates by JSON path of the field: temperature, speed, power, energy (kWh consumed since the previous energy meter reading), system.cpu and system.memory.",
+					"additionalProperties": {
+						"properties": {
+							"min": {
+								"type": "number"
+							},
+							"max": {
+								"type": "number"
+							},
+							"avg": {
+								"type": "number"
+							},
+							"count": {
+								"type": "integer"
+							},
+							"sum": {
+								"type": "number"
+							}
+						},
+						"type": "object"
+					},
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"energyReport": {
+			"description": "Energy consumed by an asset or a building per billing period. Costs are set when a tariff was passed.",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"building": {
+					"type": "string"
+				},
+				"period": {
+					"enum": [
+						"day",
+						"month"
+					],
+					"type": "string"
+				},
+				"tariff": {
+					"description": "Rate per kWh",
+					"type": "number"
+				},
+				"currency": {
+					"type": "string"
+				},
+				"periods": {
+					"items": {
+						"properties": {
+							"start": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"end": {
+								"format": "date-time",
+								"type": "string"
+							},
+							"kwh": {
+								"type": "number"
+							},
+							"cost": {
+								"type": "number"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				},
+				"totalKwh": {
+					"type": "number"
+				},
+				"totalCost": {
+					"type": "number"
+				}
+			},
+			"type": "object"
+		},
+		"anomaly": {
+			"description": "A reading that deviated from the rolling baseline of the asset. Also emitted in an anomaly event.",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"field": {
+					"type": "string"
+				},
+				"timestamp": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"value": {
+					"type": "number"
+				},
+				"mean": {
+					"type": "number"
+				},
+				"stdDev": {
+					"type": "number"
+				},
+				"zScore": {
+					"type": "number"
+				}
+			},
+			"type": "object"
+		},
+		"healthWeights": {
+			"description": "Relative weights of the health score components. Defaults apply until weights are stored.",
+			"properties": {
+				"temperature": {
+					"description": "Temperature, worst at the alarm threshold. Default 20.",
+					"type": "number"
+				},
+				"speed": {
+					"description": "Speed reading flagged as anomalous. Default 10.",
+					"type": "number"
+				},
+				"power": {
+					"description": "Power reading flagged as anomalous. Default 10.",
+					"type": "number"
+				},
+				"cpu": {
+					"description": "Controller cpu load above 50%. Default 5.",
+					"type": "number"
+				},
+				"memory": {
+					"description": "Controller memory use above 50%. Default 5.",
+					"type": "number"
+				},
+				"usage": {
+					"description": "Door cycles against the rated door equipment life. Default 20.",
+					"type": "number"
+				},
+				"alarms": {
+					"description": "Active alarms, alarm work orders and anomalies in the last 30 days, worst at three. Default 30.",
+					"type": "number"
+				}
+			},
+			"type": "object"
+		},
+		"assetHealth": {
+			"description": "Health score of an asset from 0 to 100, 100 is best. Components without data are left out of the score.",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"score": {
+					"maximum": 100,
+					"minimum": 0,
+					"type": "number"
+				},
+				"components": {
+					"additionalProperties": {
+						"properties": {
+							"weight": {
+								"type": "number"
+							},
+							"penalty": {
+								"description": "0 is healthy, 1 is worst",
+								"maximum": 1,
+								"minimum": 0,
+								"type": "number"
+							}
+						},
+						"type": "object"
+					},
+					"type": "object"
+				},
+				"computed": {
+					"format": "date-time",
+					"type": "string"
+				}
+			},
+			"type": "object"
+		},
+		"incident": {
+			"description": "A reportable event on an asset. Reporting or updating an incident emits an incident event.",
+			"properties": {
+				"incidentID": {
+					"description": "The ID of the incident, unique per asset. Defaults to the transaction ID when reporting.",
+					"type": "string"
+				},
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"building": {
+					"description": "Building of the asset when the incident was reported.",
+					"type": "string"
+				},
+				"type": {
+					"enum": [
+						"entrapment",
+						"alarmButton",
+						"emergencyStop",
+						"fireServiceRecall"
+					],
+					"type": "string"
+				},
+				"severity": {
+					"enum": [
+						"low",
+						"medium",
+						"high",
+						"critical"
+					],
+					"type": "string"
+				},
+				"description": {
+					"type": "string"
+				},
+				"start": {
+					"description": "Start of the incident, defaults to the transaction time. RFC3339 timestamp.",
+					"type": "string"
+				},
+				"end": {
+					"description": "End of the incident, empty while it is ongoing. RFC3339 timestamp.",
+					"type": "string"
+				},
+				"responder": {
+					"type": "string"
+				},
+				"resolution": {
+					"type": "string"
+				},
+				"reported": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"updated": {
+					"format": "date-time",
+					"type": "string"
+				}
+			},
+			"type": "object"
+		},
+		"availabilityReport": {
+			"description": "Availability KPIs of an asset, or of a building summed over its assets with each asset listed.",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"uptimePercent": {
+					"type": "number"
+				},
+				"uptimeHours": {
+					"type": "number"
+				},
+				"downtimeHours": {
+					"description": "Time out of service, through the outOfService operating mode or an outOfService work order. Overlapping intervals count once.",
+					"type": "number"
+				},
+				"failures": {
+					"description": "Out of service intervals starting in the period.",
+					"type": "integer"
+				},
+				"mtbfHours": {
+					"description": "Mean time between failures, set when there were failures.",
+					"type": "number"
+				},
+				"mttrHours": {
+					"description": "Mean time to repair, set when there were failures.",
+					"type": "number"
+				},
+				"building": {
+					"type": "string"
+				},
+				"from": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"to": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"assets": {
+					"items": {
+						"properties": {
+							"assetID": {
+								"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+								"type": "string"
+							},
+							"uptimePercent": {
+								"type": "number"
+							},
+							"uptimeHours": {
+								"type": "number"
+							},
+							"downtimeHours": {
+								"description": "Time out of service, through the outOfService operating mode or an outOfService work order. Overlapping intervals count once.",
+								"type": "number"
+							},
+							"failures": {
+								"description": "Out of service intervals starting in the period.",
+								"type": "integer"
+							},
+							"mtbfHours": {
+								"description": "Mean time between failures, set when there were failures.",
+								"type": "number"
+							},
+							"mttrHours": {
+								"description": "Mean time to repair, set when there were failures.",
+								"type": "number"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"batchResults": {
+			"description": "Outcome of each item, in the order passed. When an item fails the transaction fails and the error carries these results.",
+			"items": {
+				"properties": {
+					"index": {
+						"type": "integer"
+					},
+					"assetID": {
+						"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+						"type": "string"
+					},
+					"status": {
+						"enum": [
+							"created",
+							"updated",
+							"failed",
+							"notApplied"
+						],
+						"type": "string"
+					},
+					"error": {
+						"description": "Why the item failed",
+						"type": "string"
+					}
+				},
+				"type": "object"
+			},
+			"type": "array"
+		},
+		"snapshotHeader": {
+			"description": "Metadata of an asset snapshot, the first line of an NDJSON export or the leading # comment of a CSV export.",
+			"properties": {
+				"contractVersion": {
+					"type": "string"
+				},
+				"timestamp": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"history": {
+					"type": "boolean"
+				},
+				"assets": {
+					"description": "Assets in this page",
+					"type": "integer"
+				},
+				"next": {
+					"description": "Pass as start to read the next page, absent on the last page",
+					"type": "string"
+				},
+				"nextHistory": {
+					"description": "Pass as historyStart with next to resume the history of that asset, absent when the next page starts with an asset",
+					"type": "string"
+				}
+			},
+			"type": "object"
+		},
+		"snapshotRecord": {
+			"description": "A line of an NDJSON asset snapshot. In CSV the state is flattened to dotted columns such as system.memory, with lists joined by semicolons.",
+			"properties": {
+				"record": {
+					"enum": [
+						"asset",
+						"history"
+					],
+					"type": "string"
+				},
+				"timestamp": {
+					"description": "Time of the transaction that left a past state, history records only",
+					"format": "date-time",
+					"type": "string"
+				},
+				"txID": {
+					"description": "Transaction that left a past state, history records only",
+					"type": "string"
+				},
+				"state": {
+					"description": "The asset state",
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"assetQueryResult": {
+			"description": "A page of the assets matching a query",
+			"properties": {
+				"assets": {
+					"description": "Asset states, reduced to the requested fields",
+					"items": {
+						"type": "object"
+					},
+					"type": "array"
+				},
+				"matched": {
+					"description": "Assets matching the selector across all pages",
+					"type": "integer"
+				},
+				"skip": {
+					"type": "integer"
+				},
+				"limit": {
+					"type": "integer"
+				}
+			},
+			"type": "object"
+		},
+		"indexRebuild": {
+			"description": "Entries written by rebuildIndexes",
+			"properties": {
+				"assets": {
+					"description": "Assets listed in the registry",
+					"type": "integer"
+				},
+				"entries": {
+					"description": "Entries written per index",
+					"additionalProperties": {
+						"type": "integer"
+					},
+					"type": "object"
+				},
+				"removed": {
+					"description": "Entries deleted before rebuilding, stale ones included",
+					"type": "integer"
+				}
+			},
+			"type": "object"
+		},
+		"nameplate": {
+			"description": "Static nameplate data of an asset, kept apart from its changing state",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"manufacturer": {
+					"type": "string"
+				},
+				"model": {
+					"description": "Assets can be listed by model with readAssetsByIndex",
+					"type": "string"
+				},
+				"serialNumber": {
+					"type": "string"
+				},
+				"ratedLoad": {
+					"description": "Rated load in Lb. A higher weight raises the overload alarm.",
+					"type": "number"
+				},
+				"ratedSpeed": {
+					"description": "Rated speed in feet/minute. A higher speed raises the overspeed alarm.",
+					"type": "number"
+				},
+				"floorsServed": {
+					"description": "Number of floors served, at least 2",
+					"type": "integer"
+				},
+				"installationDate": {
+					"description": "RFC3339 timestamp or a plain date such as 2016-09-01",
+					"type": "string"
+				},
+				"commissioningDate": {
+					"description": "Date the elevator entered service, not before the installation date",
+					"type": "string"
+				},
+				"updated": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"minTemperature": {
+					"description": "Lowest operating temperature in Fahrenheit, overrides the model. A lower temperature raises the temperature alarm.",
+					"type": "number"
+				},
+				"maxTemperature": {
+					"description": "Highest operating temperature in Fahrenheit, overrides the model. A higher temperature raises the temperature alarm.",
+					"type": "number"
+				},
+				"maintenanceIntervalDays": {
+					"description": "Days between maintenance visits, overrides the model",
+					"type": "integer"
+				}
+			},
+			"type": "object"
+		},
+		"model": {
+			"description": "Manufacturer specifications of an elevator model, the default limits of every asset whose nameplate names the model",
+			"properties": {
+				"model": {
+					"description": "Model name as it appears on asset nameplates",
+					"type": "string"
+				},
+				"manufacturer": {
+					"type": "string"
+				},
+				"ratedLoad": {
+					"description": "Rated load in Lb",
+					"type": "number"
+				},
+				"ratedSpeed": {
+					"description": "Rated speed in feet/minute",
+					"type": "number"
+				},
+				"minTemperature": {
+					"description": "Lowest operating temperature in Fahrenheit",
+					"type": "number"
+				},
+				"maxTemperature": {
+					"description": "Highest operating temperature in Fahrenheit",
+					"type": "number"
+				},
+				"maintenanceIntervalDays": {
+					"description": "Days between maintenance visits",
+					"type": "integer"
+				},
+				"updated": {
+					"format": "date-time",
+					"type": "string"
+				}
+			},
+			"type": "object"
+		},
+		"assetLimits": {
+			"description": "Limits in effect for an asset, each taken from its nameplate, else its model, else the contract default",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"model": {
+					"type": "string"
+				},
+				"ratedLoad": {
+					"type": "number"
+				},
+				"ratedSpeed": {
+					"type": "number"
+				},
+				"minTemperature": {
+					"type": "number"
+				},
+				"maxTemperature": {
+					"type": "number"
+				},
+				"maintenanceIntervalDays": {
+					"type": "integer"
+				},
+				"nextMaintenance": {
+					"description": "Last closed work order, or the commissioning date when there is none, plus the maintenance interval",
+					"format": "date-time",
+					"type": "string"
+				},
+				"sources": {
+					"additionalProperties": {
+						"enum": [
+							"asset",
+							"model",
+							"default"
+						],
+						"type": "string"
+					},
+					"description": "Where each limit comes from",
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"derived": {
+			"description": "Values computed by the contract from the asset's state, limits, usage and energy records on every read, never stored. A field is left out when what it is computed from is unknown.",
+			"properties": {
+				"loadFactor": {
+					"description": "Weight divided by the rated load, above 1 when overloaded",
+					"type": "number"
+				},
+				"thermalMargin": {
+					"description": "Degrees Fahrenheit below the maximum temperature of the asset's limits, negative when above",
+					"type": "number"
+				},
+				"energyPerTrip": {
+					"description": "kWh metered per completed trip over the asset's lifetime",
+					"type": "number"
+				}
+			},
+			"type": "object"
+		},
+		"assetProvenance": {
+			"description": "Who and what last wrote each field of an asset's state. Fields computed by the contract, alarms and anomalies, have no entry.",
+			"properties": {
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"fields": {
+					"additionalProperties": {
+						"description": "The write that set the current value of a field",
+						"properties": {
+							"writer": {
+								"description": "Enrollment identity from the caller's certificate attribute enrollmentId, left out when it carries none",
+								"type": "string"
+							},
+							"txID": {
+								"description": "Transaction that wrote the value",
+								"type": "string"
+							},
+							"deviceID": {
+								"description": "Device the value came from, as passed with the update",
+								"type": "string"
+							},
+							"timestamp": {
+								"description": "Transaction time of the write",
+								"format": "date-time",
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"description": "Provenance keyed by field name",
+					"type": "object"
+				}
+			},
+			"type": "object"
+		},
+		"confidentialFields": {
+			"description": "Attributes of an asset that must not be readable by every member of the chain. The client encrypts this JSON object, for example with AES-GCM using the assetID as additional data, and stores the result with setConfidentialFields. The key never reaches the chain.",
+			"properties": {
+				"tenantTerms": {
+					"description": "Terms of the contract with the building tenant",
+					"type": "string"
+				},
+				"accessCodes": {
+					"description": "Codes giving access to the machine room and car",
+					"items": {
+						"type": "string"
+					},
+					"type": "array"
+				},
+				"inspectorNotes": {
+					"description": "Notes inspectors keep apart from inspection records",
+					"type": "string"
+				}
+			},
+			"type": "object"
+		},
+		"transfer": {
+			"description": "Handover of the maintenance of an asset from one provider to another",
+			"properties": {
+				"transferID": {
+					"description": "The transaction ID of proposeTransfer",
+					"type": "string"
+				},
+				"assetID": {
+					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"type": "string"
+				},
+				"from": {
+					"description": "Provider handing over, left out for an asset without provider",
+					"type": "string"
+				},
+				"to": {
+					"description": "Provider taking over",
+					"type": "string"
+				},
+				"proposedBy": {
+					"description": "Organisation of the caller that proposed the transfer",
+					"type": "string"
+				},
+				"status": {
+					"enum": [
+						"pending",
+						"accepted",
+						"cancelled"
+					],
+					"type": "string"
+				},
+				"checklist": {
+					"description": "Handover checklist, every item is done once accepted",
+					"items": {
+						"properties": {
+							"item": {
+								"type": "string"
+							},
+							"done": {
+								"type": "boolean"
+							},
+							"note": {
+								"type": "string"
+							}
 						},
 						"required": [
-							"assetID"
+							"item"
 						],
 						"type": "object"
 					},
-					"maxItems": 1,
-					"minItems": 1,
 					"type": "array"
 				},
-				"function": {
-					"description": "updateAsset function",
+				"note": {
+					"type": "string"
+				},
+				"proposed": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"closed": {
+					"description": "Transaction time of the acceptance or cancellation",
+					"format": "date-time",
+					"type": "string"
+				},
+				"snapshot": {
+					"description": "State of the asset when the transfer took effect",
+					"properties": {
+						"assetID": {
+							"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+							"type": "string"
+						},
+						"building": {
+							"description": "The building the elevator is installed in. SLA terms and reports may be set per building.",
+							"type": "string"
+						},
+						"owner": {
+							"description": "Organisation owning the elevator. Only callers with the role attribute admin set or change it.",
+							"type": "string"
+						},
+						"provider": {
+							"description": "Organisation maintaining the elevator. Only callers with the role attribute admin set it, once set it only changes through proposeTransfer and acceptTransfer. Work orders are opened for the current provider.",
+							"type": "string"
+						},
+						"weight": {
+							"description": "Weight of the Asset in Lb",
+							"type": "number"
+						},
+						"system": {
+							"description": "Properties of micro computer installed in the elevator",
+							"properties": {
+								"cpu": {
+									"type": "number"
+								},
+								"memory": {
+									"type": "number"
+								}
+							},
+							"type": "object"
+						},
+						"temperature": {
+							"description": "Temperature of the asset in Fahrenheit.",
+							"type": "number"
+						},
+						"speed": {
+							"description": "Speed of the asset in feet/minute.",
+							"type": "number"
+						},
+						"power": {
+							"description": "Power consumption by the asset in KwH.",
+							"type": "number"
+						},
+						"energyMeter": {
+							"description": "Cumulative energy meter reading in kWh. Energy per billing period is accounted from successive readings and a lower reading is treated as a meter reset.",
+							"type": "number"
+						},
+						"floor": {
+							"description": "Floor the car is currently at or passing. Negative values are below ground level.",
+							"type": "integer"
+						},
+						"direction": {
+							"description": "Travel direction of the car.",
+							"enum": [
+								"up",
+								"down",
+								"stopped"
+							],
+							"type": "string"
+						},
+						"doorStatus": {
+							"description": "State of the car doors. The car may only move with the doors closed.",
+							"enum": [
+								"open",
+								"closing",
+								"closed",
+								"obstructed"
+							],
+							"type": "string"
+						},
+						"operatingMode": {
+							"description": "Operating mode of the elevator. The car may not move when out of service.",
+							"enum": [
+								"normal",
+								"inspection",
+								"fireService",
+								"outOfService"
+							],
+							"type": "string"
+						},
+						"alarms": {
+							"description": "Active alarms, computed by the contract on every update against the asset's limits, see readAssetLimits. temperature is raised outside the operating range, overload and overspeed above the rated load and speed. An alarm entered with an update opens a work order.",
+							"items": {
+								"enum": [
+									"temperature",
+									"overload",
+									"overspeed"
+								],
+								"type": "string"
+							},
+							"type": "array"
+						},
+						"anomalies": {
+							"description": "Telemetry fields whose latest reading deviated from the asset's rolling baseline by more than the configured z-score, computed by the contract. A field stays flagged until it is read again.",
+							"items": {
+								"enum": [
+									"temperature",
+									"speed",
+									"power",
+									"energy",
+									"system.cpu",
+									"system.memory"
+								],
+								"type": "string"
+							},
+							"type": "array"
+						}
+					},
+					"type": "object"
+				},
+				"workOrders": {
+					"description": "Open work orders moved to the new provider",
+					"items": {
+						"type": "string"
+					},
+					"type": "array"
+				}
+			},
+			"type": "object"
+		},
+		"approvalPolicy": {
+			"description": "Approvals an operation needs before it runs",
+			"properties": {
+				"operation": {
 					"enum": [
-						"updateAsset"
+						"deleteAsset",
+						"setModel",
+						"setNameplate",
+						"setApprovalPolicy",
+						"updateContractConfig",
+						"init"
 					],
 					"type": "string"
 				},
-				"method": "invoke"
+				"approvals": {
+					"description": "Distinct organizations that must approve, including the proposer. 0 removes the policy.",
+					"type": "integer"
+				},
+				"organizations": {
+					"description": "Organizations that may propose and approve, any when left out",
+					"items": {
+						"type": "string"
+					},
+					"type": "array"
+				},
+				"expiryHours": {
+					"description": "Hours a proposal stays open, 72 when left out",
+					"type": "number"
+				},
+				"updated": {
+					"format": "date-time",
+					"type": "string"
+				}
 			},
 			"type": "object"
-		}
-	},
-	"objectModelSchemas": {
-		"assetIDKey": {
-			"description": "An object containing only an assetID for use as an argument to read or delete.",
+		},
+		"proposal": {
+			"description": "An operation waiting for approvals, executed once enough organizations approved it",
 			"properties": {
-				"assetID": {
-					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+				"proposalID": {
+					"description": "The transaction ID of proposeOperation",
+					"type": "string"
+				},
+				"operation": {
+					"enum": [
+						"deleteAsset",
+						"setModel",
+						"setNameplate",
+						"setApprovalPolicy",
+						"updateContractConfig",
+						"init"
+					],
+					"type": "string"
+				},
+				"args": {
+					"description": "The JSON argument the operation runs with",
+					"type": "object"
+				},
+				"note": {
+					"type": "string"
+				},
+				"proposedBy": {
+					"description": "Organization of the proposer, also its first approval",
+					"type": "string"
+				},
+				"required": {
+					"description": "Approvals the policy required when proposed",
+					"type": "integer"
+				},
+				"approvals": {
+					"items": {
+						"properties": {
+							"organization": {
+								"type": "string"
+							},
+							"timestamp": {
+								"format": "date-time",
+								"type": "string"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
+				},
+				"status": {
+					"description": "A pending proposal past its expiry is reported as expired",
+					"enum": [
+						"pending",
+						"executed",
+						"expired"
+					],
+					"type": "string"
+				},
+				"created": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"expires": {
+					"format": "date-time",
+					"type": "string"
+				},
+				"executed": {
+					"format": "date-time",
 					"type": "string"
 				}
 			},
 			"type": "object"
 		},
-		"event": {
-			"description": "A set of fields that constitute the writable fields in an asset's state. AssetID is mandatory along with at least one writable field. In this contract pattern, a partial state is used as an event.",
+		"contractState": {
+			"description": "The contract version, the configuration set at init and changed with updateContractConfig, the last migration and the number of assets and active alarms",
 			"properties": {
-				"assetID": {
+				"nickname": {
+					"default": "ELEVATOR",
+					"description": "The nickname of the current contract",
+					"type": "string"
+				},
+				"version": {
 					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
 					"type": "string"
 				},
-				"weight": {
-					"description": "Weight of the Asset in Lb",
+				"anomalyZScore": {
+					"default": 3,
+					"description": "z-score above which a telemetry reading is flagged as an anomaly",
 					"type": "number"
 				},
-				"system": {
-					"description": "Properties of micro computer installed in the elevator",
+				"units": {
+					"description": "Units readings and limits are recorded in. Readings are not converted, the default maximum temperature follows the temperature unit.",
 					"properties": {
-						"cpu": {
-							"type": "number"
+						"weight": {
+							"default": "lb",
+							"enum": [
+								"lb",
+								"kg"
+							],
+							"type": "string"
 						},
-						"memory": {
-							"type": "number"
+						"temperature": {
+							"default": "F",
+							"enum": [
+								"F",
+								"C"
+							],
+							"type": "string"
+						},
+						"speed": {
+							"default": "fpm",
+							"description": "feet per minute or meters per second",
+							"enum": [
+								"fpm",
+								"mps"
+							],
+							"type": "string"
 						}
 					},
 					"type": "object"
 				},
-				"temperature": {
-					"description": "Temperature of the asset in Fahrenheit.",
-					"type": "number"
+				"retention": {
+					"description": "Days records are kept, older records are removed when the asset is next updated. When a window is left out asset history is kept 365 days and other records forever.",
+					"properties": {
+						"historyDays": {
+							"description": "asset history",
+							"minimum": 1,
+							"type": "integer",
+							"default": 365
+						},
+						"telemetryDays": {
+							"description": "hourly and daily telemetry buckets",
+							"minimum": 1,
+							"type": "integer"
+						},
+						"anomalyDays": {
+							"description": "anomaly records",
+							"minimum": 1,
+							"type": "integer"
+						}
+					},
+					"type": "object"
 				},
-				"speed": {
-					"description": "Speed of the asset in feet/minute.",
-					"type": "number"
+				"validationMode": {
+					"default": "lenient",
+					"description": "strict rejects asset state fields the contract does not know, nested ones included, and the fields it computes: alarms, anomalies and deviceID. lenient ignores them",
+					"enum": [
+						"strict",
+						"lenient"
+					],
+					"type": "string"
 				},
-				"power": {
-					"description": "Power consumption by the asset in KwH.",
-					"type": "number"
+				"features": {
+					"description": "Features switched on or off, a feature is on unless set false",
+					"properties": {
+						"anomalyDetection": {
+							"type": "boolean"
+						},
+						"telemetry": {
+							"type": "boolean"
+						},
+						"alarmWorkOrders": {
+							"type": "boolean"
+						},
+						"provenance": {
+							"type": "boolean"
+						}
+					},
+					"additionalProperties": false,
+					"type": "object"
+				},
+				"updated": {
+					"description": "Time the configuration was last changed",
+					"format": "date-time",
+					"type": "string"
+				},
+				"lastMigration": {
+					"description": "The last run of init, on deployment or upgrade",
+					"properties": {
+						"fromVersion": {
+							"description": "Version stored before, left out on first deployment",
+							"type": "string"
+						},
+						"toVersion": {
+							"type": "string"
+						},
+						"txID": {
+							"type": "string"
+						},
+						"timestamp": {
+							"format": "date-time",
+							"type": "string"
+						}
+					},
+					"type": "object"
+				},
+				"assets": {
+					"description": "Entries of the asset registry",
+					"type": "integer"
+				},
+				"activeAlarms": {
+					"description": "Alarms active on assets, an asset in two alarms counts twice",
+					"type": "integer"
 				}
 			},
-			"required": [
-				"assetID"
-			],
 			"type": "object"
 		},
-		"initEvent": {
-			"description": "event sent to init on deployment",
+		"diagnostics": {
+			"description": "Consistency of the stored contract state, asset registry, secondary indexes and usage counters with the assets listed in the registry. rebuildIndexes repairs the registry and indexes.",
 			"properties": {
-				"nickname": {
-					"default": "ELEVATOR",
-					"description": "The nickname of the current contract",
-					"type": "string"
-				},
 				"version": {
-					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
+					"description": "Version of the running chaincode",
 					"type": "string"
+				},
+				"consistent": {
+					"description": "No check found a problem",
+					"type": "boolean"
+				},
+				"assets": {
+					"description": "Assets listed in the registry",
+					"type": "integer"
+				},
+				"checks": {
+					"items": {
+						"properties": {
+							"name": {
+								"enum": [
+									"contract",
+									"registry",
+									"building",
+									"alarm",
+									"certificateExpiry",
+									"model",
+									"usage"
+								],
+								"type": "string"
+							},
+							"checked": {
+								"description": "Records and entries checked",
+								"type": "integer"
+							},
+							"problems": {
+								"description": "Inconsistencies found",
+								"type": "integer"
+							},
+							"details": {
+								"description": "The first 100 inconsistencies found",
+								"items": {
+									"type": "string"
+								},
+								"type": "array"
+							}
+						},
+						"type": "object"
+					},
+					"type": "array"
 				}
 			},
-			"required": [
-				"version"
-			],
 			"type": "object"
 		},
-		"state": {
-			"description": "A set of fields that constitute the complete asset state.",
+		"confidentialRecord": {
+			"description": "The confidential fields of an asset as stored on the ledger, encrypted by the client. Returned by readAsset to the organisation holding the key and to admins.",
 			"properties": {
 				"assetID": {
 					"description": "The ID of a managed asset. The resource focal point for a smart contract.",
 					"type": "string"
 				},
-				"weight": {
-					"description": "Weight of the Asset in Lb",
-					"type": "number"
+				"keyID": {
+					"description": "Names the key the fields were encrypted with, chosen by the client. Never the key itself.",
+					"type": "string"
 				},
-				"system": {
-					"description": "Properties of micro computer installed in the elevator",
-					"properties": {
-						"cpu": {
-							"type": "number"
-						},
-						"memory": {
-							"type": "number"
-						}
-					},
-					"type": "object"
+				"nonce": {
+					"description": "Base64 nonce of the cipher, when it uses one",
+					"type": "string"
 				},
-				"temperature": {
-					"description": "Temperature of the asset in Fahrenheit.",
-					"type": "number"
+				"ciphertext": {
+					"description": "Base64 encrypted JSON of the confidential fields",
+					"type": "string"
 				},
-				"speed": {
-					"description": "Speed of the asset in feet/minute.",
-					"type": "number"
+				"organization": {
+					"description": "Organisation holding the key, which may read the record and replace the ciphertext under the same key",
+					"type": "string"
 				},
-				"power": {
-					"description": "Power consumption by the asset in KwH.",
-					"type": "number"
+				"updated": {
+					"description": "Transaction time of the last change",
+					"format": "date-time",
+					"type": "string"
 				}
 			},
 			"type": "object"
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchemasAndSamples(t *testing.T) {
+	var decoded interface{}
+	err := json.Unmarshal([]byte(schemas), &decoded)
+	if err != nil {
+		t.Fatalf("schemas are not valid JSON: %v", err)
+	}
+	err = json.Unmarshal([]byte(samples), &decoded)
+	if err != nil {
+		t.Fatalf("samples are not valid JSON: %v", err)
+	}
+	// the import command reads the createAsset schema
+	schema, err := assetStateSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Type != "object" || schema.Properties["assetID"] == nil || schema.Properties["system"].Properties["cpu"] == nil {
+		t.Fatalf("unexpected createAsset schema %+v", schema)
+	}
+}
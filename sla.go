@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// SLAKEYPREFIX - object type for service level agreements, keyed by scope and assetID or building
+const SLAKEYPREFIX string = "SLA"
+
+// SLA scopes, asset terms take precedence over the terms of the asset's building
+const (
+	SLASCOPEASSET    string = "asset"
+	SLASCOPEBUILDING string = "building"
+)
+
+// SLA breach types
+const (
+	BREACHRESPONSETIME string = "responseTime"
+	BREACHDOWNTIME     string = "downtime"
+)
+
+// SLA - service level agreement terms between an owner and a maintenance provider.
+// Limits that are not set are not tracked.
+type SLA struct {
+	AssetID                  string   `json:"assetID,omitempty"`                  // set for asset terms
+	Building                 string   `json:"building,omitempty"`                 // set for building terms
+	Owner                    string   `json:"owner,omitempty"`                    // building or asset owner
+	Provider                 string   `json:"provider,omitempty"`                 // maintenance provider
+	ResponseTimeHours        *float64 `json:"responseTimeHours,omitempty"`        // max time from opening a work order until work starts
+	MaxDowntimeHoursPerMonth *float64 `json:"maxDowntimeHoursPerMonth,omitempty"` // out of service allowance per asset and calendar month
+	ResponsePenalty          float64  `json:"responsePenalty"`                    // penalty per late response
+	DowntimePenaltyPerHour   float64  `json:"downtimePenaltyPerHour"`             // penalty per hour out of service above the allowance
+	Currency                 string   `json:"currency,omitempty"`
+	Updated                  string   `json:"updated"`
+}
+
+// SLABreach - a single breach of SLA terms
+type SLABreach struct {
+	Type        string  `json:"type"` // responseTime or downtime
+	AssetID     string  `json:"assetID"`
+	WorkOrderID string  `json:"workOrderID,omitempty"` // late work order, responseTime breaches only
+	Provider    string  `json:"provider,omitempty"`    // provider responsible for the late work order, responseTime breaches only
+	Month       string  `json:"month,omitempty"`       // calendar month as 2006-01, downtime breaches only
+	LimitHours  float64 `json:"limitHours"`
+	ActualHours float64 `json:"actualHours"`
+	Penalty     float64 `json:"penalty"`
+}
+
+// SLAReport - breaches and accrued penalties for a billing period
+type SLAReport struct {
+	AssetID      string      `json:"assetID,omitempty"`
+	Building     string      `json:"building,omitempty"`
+	From         string      `json:"from"`
+	To           string      `json:"to"`
+	Compliant    bool        `json:"compliant"`
+	Breaches     []SLABreach `json:"breaches"`
+	TotalPenalty float64     `json:"totalPenalty"`
+}
+
+// slaQuery - argument to readSLA and readSLAReport
+type slaQuery struct {
+	AssetID  string `json:"assetID,omitempty"`
+	Building string `json:"building,omitempty"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+}
+
+//******************** setSLA ********************/
+
+// setSLA - admin only, sets the terms and penalties of an asset or a building
+func (t *SimpleChaincode) setSLA(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var sla SLA
+	err := t.requireRole(stub, ROLEADMIN)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with SLA terms")
+	}
+	err = json.Unmarshal([]byte(args[0]), &sla)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	key, err := slaKey(sla.AssetID, sla.Building)
+	if err != nil {
+		return nil, err
+	}
+	if (sla.ResponseTimeHours != nil && *sla.ResponseTimeHours < 0) ||
+		(sla.MaxDowntimeHoursPerMonth != nil && *sla.MaxDowntimeHoursPerMonth < 0) ||
+		sla.ResponsePenalty < 0 || sla.DowntimePenaltyPerHour < 0 {
+		return nil, errors.New("SLA limits and penalties cannot be negative")
+	}
+	sla.AssetID = strings.TrimSpace(sla.AssetID)
+	sla.Building = strings.TrimSpace(sla.Building)
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	sla.Updated = formatTime(now)
+	slaJSON, err := json.Marshal(sla)
+	if err != nil {
+		return nil, errors.New("Marshal failed for SLA" + fmt.Sprint(err))
+	}
+	err = stub.PutState(key, slaJSON)
+	if err != nil {
+		return nil, errors.New("PUT ledger state failed for SLA: " + fmt.Sprint(err))
+	}
+	return nil, nil
+}
+
+//******************** readSLA ********************/
+
+// readSLA - returns the terms that apply to an asset or a building
+func (t *SimpleChaincode) readSLA(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var sla *SLA
+	query, err := t.validateSLAQuery(args)
+	if err != nil {
+		return nil, err
+	}
+	if query.AssetID != "" {
+		sla, err = t.getAssetSLA(stub, query.AssetID)
+	} else {
+		sla, err = t.getSLA(stub, SLASCOPEBUILDING, query.Building)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sla == nil {
+		return nil, errors.New("No SLA found")
+	}
+	return json.Marshal(sla)
+}
+
+//******************** readSLAReport ********************/
+
+// readSLAReport - evaluates the SLA terms of an asset, or of every asset in a building,
+// over the billing period [from, to). The period ends at the transaction time by default.
+func (t *SimpleChaincode) readSLAReport(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var assetIDs []string
+	query, err := t.validateSLAQuery(args)
+	if err != nil {
+		return nil, err
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := reportPeriod(query.From, query.To, now)
+	if err != nil {
+		return nil, err
+	}
+	if query.AssetID != "" {
+		assetIDs = []string{query.AssetID}
+	} else {
+		assetIDs, err = t.getBuildingAssets(stub, query.Building)
+		if err != nil {
+			return nil, err
+		}
+	}
+	report := SLAReport{
+		AssetID:   query.AssetID,
+		Building:  query.Building,
+		From:      formatTime(from),
+		To:        formatTime(to),
+		Compliant: true,
+		Breaches:  []SLABreach{},
+	}
+	for _, assetID := range assetIDs {
+		sla, err := t.getAssetSLA(stub, assetID)
+		if err != nil {
+			return nil, err
+		}
+		if sla == nil {
+			continue
+		}
+		breaches, err := t.evaluateSLA(stub, assetID, *sla, from, to, now)
+		if err != nil {
+			return nil, err
+		}
+		for _, breach := range breaches {
+			report.Breaches = append(report.Breaches, breach)
+			report.TotalPenalty += breach.Penalty
+		}
+	}
+	report.Compliant = len(report.Breaches) == 0
+	return json.Marshal(report)
+}
+
+/*********************************  internal: SLA ****************************/
+
+// evaluateSLA - response time breaches for work orders opened in the period and
+// downtime breaches for every calendar month the period covers
+func (t *SimpleChaincode) evaluateSLA(stub shim.ChaincodeStubInterface, assetID string, sla SLA, from time.Time, to time.Time, now time.Time) ([]SLABreach, error) {
+	var breaches []SLABreach
+	if sla.ResponseTimeHours != nil {
+		orders, err := t.getWorkOrders(stub, assetID)
+		if err != nil {
+			return nil, err
+		}
+		for _, order := range orders {
+			opened, err := parseTime(order.Opened)
+			if err != nil || opened.Before(from) || !opened.Before(to) {
+				continue
+			}
+			// work that has not started yet is measured until now
+			responded := now
+			if order.Started != "" {
+				responded, _ = parseTime(order.Started)
+			} else if order.Closed != "" {
+				responded, _ = parseTime(order.Closed)
+			}
+			hours := responded.Sub(opened).Hours()
+			if hours > *sla.ResponseTimeHours {
+				breaches = append(breaches, SLABreach{
+					Type:        BREACHRESPONSETIME,
+					AssetID:     assetID,
+					WorkOrderID: order.WorkOrderID,
+					Provider:    order.Provider,
+					LimitHours:  *sla.ResponseTimeHours,
+					ActualHours: hours,
+					Penalty:     sla.ResponsePenalty,
+				})
+			}
+		}
+	}
+	if sla.MaxDowntimeHoursPerMonth != nil {
+		outages, err := t.getOutages(stub, assetID)
+		if err != nil {
+			return nil, err
+		}
+		monthStart := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+		for monthStart.Before(to) {
+			monthEnd := monthStart.AddDate(0, 1, 0)
+			windowStart, windowEnd := monthStart, monthEnd
+			if windowStart.Before(from) {
+				windowStart = from
+			}
+			if windowEnd.After(to) {
+				windowEnd = to
+			}
+			downtime := downtimeIn(outages, windowStart, windowEnd, now)
+			// the allowance is prorated for months the period covers in part
+			allowance := *sla.MaxDowntimeHoursPerMonth * windowEnd.Sub(windowStart).Hours() / monthEnd.Sub(monthStart).Hours()
+			if downtime.Hours() > allowance {
+				breaches = append(breaches, SLABreach{
+					Type:        BREACHDOWNTIME,
+					AssetID:     assetID,
+					Month:       monthStart.Format("2006-01"),
+					LimitHours:  allowance,
+					ActualHours: downtime.Hours(),
+					Penalty:     (downtime.Hours() - allowance) * sla.DowntimePenaltyPerHour,
+				})
+			}
+			monthStart = monthEnd
+		}
+	}
+	return breaches, nil
+}
+
+// validateSLAQuery - exactly one of assetID and building is mandatory
+func (t *SimpleChaincode) validateSLAQuery(args []string) (slaQuery, error) {
+	var query slaQuery
+	if len(args) != 1 {
+		return query, errors.New("Incorrect number of arguments. Expecting a JSON string with assetID or building")
+	}
+	err := json.Unmarshal([]byte(args[0]), &query)
+	if err != nil {
+		return query, errors.New("Unable to unmarshal input JSON data")
+	}
+	query.AssetID = strings.TrimSpace(query.AssetID)
+	query.Building = strings.TrimSpace(query.Building)
+	_, err = slaKey(query.AssetID, query.Building)
+	return query, err
+}
+
+// reportPeriod - parses the [from, to) period of a report, to defaults to now
+func reportPeriod(fromArg string, toArg string, now time.Time) (time.Time, time.Time, error) {
+	from, err := parseTime(fromArg)
+	if err != nil {
+		return from, now, errors.New("Invalid or missing from: " + fromArg)
+	}
+	to := now
+	if toArg != "" {
+		to, err = parseTime(toArg)
+		if err != nil {
+			return from, to, errors.New("Invalid to: " + toArg)
+		}
+	}
+	if !to.After(from) {
+		return from, to, errors.New("Period end must be after its start")
+	}
+	return from.UTC(), to.UTC(), nil
+}
+
+// slaKey - the ledger key of asset or building terms
+func slaKey(assetID string, building string) (string, error) {
+	assetID = strings.TrimSpace(assetID)
+	building = strings.TrimSpace(building)
+	if (assetID == "") == (building == "") {
+		return "", errors.New("Expecting either an assetID or a building")
+	}
+	if strings.Contains(assetID+building, KEYSEPARATOR) {
+		return "", errors.New("Input JSON data contains an invalid character")
+	}
+	if assetID != "" {
+		return compositeKey(SLAKEYPREFIX, SLASCOPEASSET, assetID), nil
+	}
+	return compositeKey(SLAKEYPREFIX, SLASCOPEBUILDING, building), nil
+}
+
+// getSLA - terms stored for a scope, nil when there are none
+func (t *SimpleChaincode) getSLA(stub shim.ChaincodeStubInterface, scope string, id string) (*SLA, error) {
+	var sla SLA
+	slaBytes, err := stub.GetState(compositeKey(SLAKEYPREFIX, scope, id))
+	if err != nil || len(slaBytes) == 0 {
+		return nil, nil
+	}
+	err = json.Unmarshal(slaBytes, &sla)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal SLA data obtained from ledger")
+	}
+	return &sla, nil
+}
+
+// getAssetSLA - the asset's own terms, or those of its building, nil when neither exists
+func (t *SimpleChaincode) getAssetSLA(stub shim.ChaincodeStubInterface, assetID string) (*SLA, error) {
+	var state AssetState
+	sla, err := t.getSLA(stub, SLASCOPEASSET, assetID)
+	if err != nil || sla != nil {
+		return sla, err
+	}
+	assetBytes, err := stub.GetState(assetID)
+	if err != nil || len(assetBytes) == 0 {
+		return nil, nil
+	}
+	err = json.Unmarshal(assetBytes, &state)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal state data obtained from ledger")
+	}
+	if state.Building == nil {
+		return nil, nil
+	}
+	return t.getSLA(stub, SLASCOPEBUILDING, *state.Building)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSLAReport(t *testing.T) {
+	var sla SLA
+	var report SLAReport
+	stub := newTestStub(t)
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","building":"B1","provider":"Acme","operatingMode":"normal"}`)
+	stub.mustInvoke(t, "setSLA", `{"building":"B1","owner":"Main St","provider":"Acme","responseTimeHours":4,"responsePenalty":100,"maxDowntimeHoursPerMonth":1,"downtimePenaltyPerHour":50}`)
+	stub.as("", "")
+
+	// a response after 6 hours and 3 hours out of service
+	stub.mustInvoke(t, "openWorkOrder", `{"assetID":"E1","workOrderID":"W1"}`)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","operatingMode":"outOfService"}`)
+	stub.now = stub.now.Add(3 * time.Hour)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","operatingMode":"normal"}`)
+	stub.now = stub.now.Add(3 * time.Hour)
+	stub.mustInvoke(t, "assignTechnician", `{"assetID":"E1","workOrderID":"W1","technician":"Sam"}`)
+	stub.mustInvoke(t, "updateWorkOrder", `{"assetID":"E1","workOrderID":"W1","status":"inProgress"}`)
+
+	stub.mustQuery(t, "readSLA", `{"assetID":"E1"}`, &sla)
+	if sla.Building != "B1" || *sla.ResponseTimeHours != 4 {
+		t.Fatalf("asset did not get the building terms %+v", sla)
+	}
+	stub.mustQuery(t, "readSLAReport", `{"building":"B1","from":"2016-09-01","to":"2016-10-01"}`, &report)
+	if report.Compliant || len(report.Breaches) != 2 || report.TotalPenalty != 200 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if report.Breaches[0].Type != BREACHRESPONSETIME || report.Breaches[0].Provider != "Acme" || report.Breaches[1].Type != BREACHDOWNTIME {
+		t.Fatalf("unexpected breaches %+v", report.Breaches)
+	}
+}
+
+func TestSLARejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFail(t, "setSLA", `{"building":"B1","responseTimeHours":4}`, "not allowed")
+	stub.as(ROLEADMIN, "")
+	stub.mustFail(t, "setSLA", `{"responseTimeHours":4}`, "Expecting either an assetID or a building")
+	stub.mustFail(t, "setSLA", `{"assetID":"E1","building":"B1"}`, "Expecting either an assetID or a building")
+	stub.mustFail(t, "setSLA", `{"building":"B1","responseTimeHours":-1}`, "cannot be negative")
+	stub.mustFail(t, "setSLA", `{"building":"B\u00001"}`, "invalid character")
+	stub.mustFailQuery(t, "readSLA", `{"building":"B1"}`, "No SLA found")
+	stub.mustFailQuery(t, "readSLAReport", `{"building":"B1","from":"2016-09-02","to":"2016-09-01"}`, "Period end must be after")
+	stub.mustFailQuery(t, "readSLAReport", `{"building":"B1"}`, "Invalid or missing from")
+}
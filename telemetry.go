@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// TELEMETRYKEYPREFIX - object type for telemetry buckets, keyed by assetID, granularity and bucket start
+const TELEMETRYKEYPREFIX string = "TELEMETRY"
+
+// telemetry bucket granularities
+const (
+	GRANULARITYHOUR string = "hour"
+	GRANULARITYDAY  string = "day"
+)
+
+// telemetry fields aggregated in buckets, named by their JSON path in the asset state
+const (
+	FIELDTEMPERATURE string = "temperature"
+	FIELDSPEED       string = "speed"
+	FIELDPOWER       string = "power"
+	FIELDCPU         string = "system.cpu"
+	FIELDMEMORY      string = "system.memory"
+	FIELDENERGY      string = "energy" // kWh consumed since the previous energy meter reading
+)
+
+// FieldAggregate - min, max, average and count of the readings of one field in a bucket
+type FieldAggregate struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Avg   float64 `json:"avg"`
+	Count int64   `json:"count"`
+	Sum   float64 `json:"sum"`
+}
+
+// TelemetryBucket - aggregated readings of an asset over one hour or day
+type TelemetryBucket struct {
+	AssetID     string                     `json:"assetID"`
+	Granularity string                     `json:"granularity"`
+	Start       string                     `json:"start"`
+	Fields      map[string]*FieldAggregate `json:"fields"`
+}
+
+//******************** readAssetAggregates ********************/
+
+// readAssetAggregates - returns the buckets of an asset that start in [from, to) at the
+// requested granularity, hour by default. The range ends at the transaction time by default.
+func (t *SimpleChaincode) readAssetAggregates(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var query struct {
+		AssetID     string `json:"assetID"`
+		From        string `json:"from"`
+		To          string `json:"to"`
+		Granularity string `json:"granularity"`
+	}
+	var buckets = []TelemetryBucket{}
+
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting a JSON string with mandatory assetID and from")
+	}
+	err := json.Unmarshal([]byte(args[0]), &query)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal input JSON data")
+	}
+	query.AssetID = strings.TrimSpace(query.AssetID)
+	if query.AssetID == "" || strings.Contains(query.AssetID, KEYSEPARATOR) {
+		return nil, errors.New("Asset id is mandatory in the input JSON data")
+	}
+	if query.Granularity == "" {
+		query.Granularity = GRANULARITYHOUR
+	}
+	if !isOneOf(query.Granularity, GRANULARITYHOUR, GRANULARITYDAY) {
+		return nil, errors.New("Invalid granularity: " + query.Granularity)
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := reportPeriod(query.From, query.To, now)
+	if err != nil {
+		return nil, err
+	}
+	from = bucketStart(from, query.Granularity)
+	iter, err := stub.RangeQueryState(
+		compositeKey(TELEMETRYKEYPREFIX, query.AssetID, query.Granularity, timeKey(from)),
+		compositeKey(TELEMETRYKEYPREFIX, query.AssetID, query.Granularity, timeKey(to)))
+	if err != nil {
+		return nil, errors.New("Unable to read telemetry from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		var bucket TelemetryBucket
+		_, bucketBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read telemetry from ledger: " + fmt.Sprint(err))
+		}
+		err = json.Unmarshal(bucketBytes, &bucket)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal telemetry data obtained from ledger")
+		}
+		// the end of the range may be inclusive
+		start, err := parseTime(bucket.Start)
+		if err != nil || !start.Before(to) {
+			continue
+		}
+		buckets = append(buckets, bucket)
+	}
+	bucketsJSON, err := json.Marshal(buckets)
+	if err != nil {
+		return nil, errors.New("Marshal failed for telemetry" + fmt.Sprint(err))
+	}
+	return bucketsJSON, nil
+}
+
+/*********************************  internal: telemetry buckets ****************************/
+
+// updateTelemetry - adds the readings passed in an update to the hourly and daily buckets
+// of the asset. Only the incoming event counts, values merged from the previous state do not.
+func (t *SimpleChaincode) updateTelemetry(stub shim.ChaincodeStubInterface, assetID string, readings map[string]float64) error {
+	if len(readings) == 0 {
+		return nil
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return err
+	}
+	for _, granularity := range []string{GRANULARITYHOUR, GRANULARITYDAY} {
+		var bucket TelemetryBucket
+		start := bucketStart(now, granularity)
+		key := compositeKey(TELEMETRYKEYPREFIX, assetID, granularity, timeKey(start))
+		bucketBytes, err := stub.GetState(key)
+		if err == nil && len(bucketBytes) > 0 {
+			err = json.Unmarshal(bucketBytes, &bucket)
+			if err != nil {
+				return errors.New("Unable to unmarshal telemetry data obtained from ledger")
+			}
+		} else {
+			bucket = TelemetryBucket{
+				AssetID:     assetID,
+				Granularity: granularity,
+				Start:       formatTime(start),
+				Fields:      map[string]*FieldAggregate{},
+			}
+		}
+		for field, value := range readings {
+			agg, found := bucket.Fields[field]
+			if !found {
+				agg = &FieldAggregate{Min: value, Max: value}
+				bucket.Fields[field] = agg
+			}
+			if value < agg.Min {
+				agg.Min = value
+			}
+			if value > agg.Max {
+				agg.Max = value
+			}
+			agg.Count++
+			agg.Sum += value
+			agg.Avg = agg.Sum / float64(agg.Count)
+		}
+		bucketJSON, err := json.Marshal(bucket)
+		if err != nil {
+			return errors.New("Marshal failed for telemetry" + fmt.Sprint(err))
+		}
+		err = stub.PutState(key, bucketJSON)
+		if err != nil {
+			return errors.New("PUT ledger state failed for telemetry: " + fmt.Sprint(err))
+		}
+	}
+	return nil
+}
+
+// telemetryReadings - the numeric telemetry fields present in a state, by JSON path. The energy
+// meter is cumulative, the energy consumed since the previous reading is read instead.
+func telemetryReadings(state AssetState, consumed *float64) map[string]float64 {
+	readings := map[string]float64{}
+	if state.Temperature != nil {
+		readings[FIELDTEMPERATURE] = *state.Temperature
+	}
+	if state.Speed != nil {
+		readings[FIELDSPEED] = *state.Speed
+	}
+	if state.Power != nil {
+		readings[FIELDPOWER] = *state.Power
+	}
+	if consumed != nil {
+		readings[FIELDENERGY] = *consumed
+	}
+	if state.System != nil && state.System.CPU != nil {
+		readings[FIELDCPU] = *state.System.CPU
+	}
+	if state.System != nil && state.System.Memory != nil {
+		readings[FIELDMEMORY] = *state.System.Memory
+	}
+	return readings
+}
+
+// bucketStart - start of the hour or UTC day containing tm
+func bucketStart(tm time.Time, granularity string) time.Time {
+	tm = tm.UTC()
+	if granularity == GRANULARITYDAY {
+		return time.Date(tm.Year(), tm.Month(), tm.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return tm.Truncate(time.Hour)
+}
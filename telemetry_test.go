@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAssetAggregates(t *testing.T) {
+	var hours, days []TelemetryBucket
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","temperature":70,"system":{"cpu":20}}`)
+	stub.now = stub.now.Add(30 * time.Minute)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","temperature":80}`)
+	stub.now = stub.now.Add(40 * time.Minute)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","temperature":90}`)
+
+	stub.mustQuery(t, "readAssetAggregates", `{"assetID":"E1","from":"2016-09-01"}`, &hours)
+	if len(hours) != 2 {
+		t.Fatalf("expecting two hourly buckets, got %+v", hours)
+	}
+	first := hours[0].Fields[FIELDTEMPERATURE]
+	if hours[0].Start != "2016-09-01T12:00:00Z" || first.Min != 70 || first.Max != 80 || first.Avg != 75 || first.Count != 2 {
+		t.Fatalf("unexpected first hour %+v %+v", hours[0], first)
+	}
+	// only the fields passed in an update are read
+	if hours[1].Fields[FIELDCPU] != nil || hours[1].Fields[FIELDTEMPERATURE].Count != 1 {
+		t.Fatalf("unexpected second hour %+v", hours[1])
+	}
+	stub.mustQuery(t, "readAssetAggregates", `{"assetID":"E1","from":"2016-09-01","granularity":"day"}`, &days)
+	if len(days) != 1 || days[0].Fields[FIELDTEMPERATURE].Avg != 80 || days[0].Fields[FIELDCPU].Count != 1 {
+		t.Fatalf("unexpected daily bucket %+v", days)
+	}
+	stub.mustQuery(t, "readAssetAggregates", `{"assetID":"E1","from":"2016-08-01","to":"2016-09-01"}`, &hours)
+	if len(hours) != 0 {
+		t.Fatalf("expecting no buckets before the first reading, got %+v", hours)
+	}
+}
+
+func TestAssetAggregatesRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFailQuery(t, "readAssetAggregates", `{"from":"2016-09-01"}`, "Asset id is mandatory")
+	stub.mustFailQuery(t, "readAssetAggregates", `{"assetID":"E1","from":"2016-09-01","granularity":"week"}`, "Invalid granularity")
+	stub.mustFailQuery(t, "readAssetAggregates", `{"assetID":"E1"}`, "Invalid or missing from")
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// TRANSFERKEYPREFIX - object type for maintenance provider transfers, keyed by assetID and transferID
+const TRANSFERKEYPREFIX string = "TRANSFER"
+
+// transfer states
+const (
+	TRANSFERPENDING   string = "pending"
+	TRANSFERACCEPTED  string = "accepted"
+	TRANSFERCANCELLED string = "cancelled"
+)
+
+// ChecklistItem - a handover step the new provider confirms when accepting a transfer
+type ChecklistItem struct {
+	Item string `json:"item"`
+	Done bool   `json:"done"`
+	Note string `json:"note,omitempty"`
+}
+
+// Transfer - handover of the maintenance of an asset from one provider to another. The transfer
+// is proposed by the owner or the current provider and takes effect once the new provider accepts.
+type Transfer struct {
+	TransferID string          `json:"transferID"`
+	AssetID    string          `json:"assetID"`
+	From       string          `json:"from,omitempty"` // provider handing over, not set for an asset without provider
+	To         string          `json:"to"`             // provider taking over
+	ProposedBy string          `json:"proposedBy"`     // organisation of the caller that proposed the transfer
+	Status     string          `json:"status"`         // pending, accepted or cancelled
+	Checklist  []ChecklistItem `json:"checklist,omitempty"`
+	Note       string          `json:"note,omitempty"`
+	Proposed   string          `json:"proposed"`
+	Closed     string          `json:"closed,omitempty"`     // transaction time of the acceptance or cancellation
+	Snapshot   *AssetState     `json:"snapshot,omitempty"`   // state of the asset when the transfer took effect
+	WorkOrders []string        `json:"workOrders,omitempty"` // open work orders moved to the new provider
+}
+
+// TransferEvent - argument to the transfer functions
+type TransferEvent struct {
+	AssetID    string          `json:"assetID"`
+	TransferID string          `json:"transferID,omitempty"`
+	To         string          `json:"to,omitempty"`        // proposeTransfer only
+	Checklist  []ChecklistItem `json:"checklist,omitempty"` // items to propose, or confirmed on acceptance
+	Note       string          `json:"note,omitempty"`
+}
+
+//******************** proposeTransfer ********************/
+
+// proposeTransfer - proposes a new maintenance provider for an asset. The caller's organisation
+// must be the asset's owner or current provider, unless the caller is an admin.
+func (t *SimpleChaincode) proposeTransfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	event, err := t.validateTransferInput(args, false)
+	if err != nil {
+		return nil, err
+	}
+	event.To = strings.TrimSpace(event.To)
+	if event.To == "" {
+		return nil, errors.New("New provider is mandatory in the input JSON data")
+	}
+	state, err := t.getTransferAsset(stub, event.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	from := ""
+	if state.Provider != nil {
+		from = *state.Provider
+	}
+	if event.To == from {
+		return nil, errors.New("Asset is already maintained by " + from)
+	}
+	org := callerOrganization(stub)
+	if org == "" || (org != from && (state.Owner == nil || org != *state.Owner)) {
+		err = t.requireRole(stub, ROLEADMIN)
+		if err != nil {
+			return nil, errors.New("Only the owner or the current provider may propose a transfer: " + fmt.Sprint(err))
+		}
+	}
+	pending, err := t.getPendingTransfer(stub, event.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return nil, errors.New("Transfer already pending: " + pending.TransferID)
+	}
+	for i := range event.Checklist {
+		event.Checklist[i].Item = strings.TrimSpace(event.Checklist[i].Item)
+		if event.Checklist[i].Item == "" {
+			return nil, errors.New("Checklist items cannot be empty")
+		}
+		event.Checklist[i].Done = false
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	transfer := Transfer{
+		TransferID: stub.GetTxID(),
+		AssetID:    event.AssetID,
+		From:       from,
+		To:         event.To,
+		ProposedBy: org,
+		Status:     TRANSFERPENDING,
+		Checklist:  event.Checklist,
+		Note:       event.Note,
+		Proposed:   formatTime(now),
+	}
+	return nil, t.putTransfer(stub, transfer)
+}
+
+//******************** acceptTransfer ********************/
+
+// acceptTransfer - the new provider accepts a pending transfer, confirming every checklist item.
+// The asset, its open work orders and its own SLA terms move to the new provider. The SLA of the
+// asset's building moves too once the old provider maintains no other asset in the building.
+func (t *SimpleChaincode) acceptTransfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	event, err := t.validateTransferInput(args, true)
+	if err != nil {
+		return nil, err
+	}
+	transfer, err := t.getTransfer(stub, event.AssetID, event.TransferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != TRANSFERPENDING {
+		return nil, errors.New("Transfer is " + transfer.Status + ": " + transfer.TransferID)
+	}
+	if callerOrganization(stub) != transfer.To {
+		return nil, errors.New("Only " + transfer.To + " may accept the transfer")
+	}
+	for i, item := range transfer.Checklist {
+		for _, confirmed := range event.Checklist {
+			if strings.TrimSpace(confirmed.Item) == item.Item {
+				transfer.Checklist[i].Done = confirmed.Done
+				transfer.Checklist[i].Note = confirmed.Note
+			}
+		}
+		if !transfer.Checklist[i].Done {
+			return nil, errors.New("Checklist item is not done: " + item.Item)
+		}
+	}
+	state, err := t.getTransferAsset(stub, event.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	tm, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	now := formatTime(tm)
+
+	// the snapshot records the state handed over, before the provider changes
+	snapshot := state
+	transfer.Snapshot = &snapshot
+	state.Provider = &transfer.To
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return nil, errors.New("Marshal failed for contract state" + fmt.Sprint(err))
+	}
+	err = stub.PutState(event.AssetID, stateJSON)
+	if err != nil {
+		return nil, errors.New("PUT ledger state failed: " + fmt.Sprint(err))
+	}
+	err = t.updateAssetRegistry(stub, event.AssetID, &state)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := t.getWorkOrders(stub, event.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range orders {
+		if order.Status == WOCLOSED {
+			continue
+		}
+		order.Provider = transfer.To
+		order.Updated = now
+		note := "Transferred to " + transfer.To
+		if transfer.From != "" {
+			note = "Transferred from " + transfer.From + " to " + transfer.To
+		}
+		addWorkOrderNote(&order, now, note)
+		err = t.putWorkOrder(stub, order)
+		if err != nil {
+			return nil, err
+		}
+		transfer.WorkOrders = append(transfer.WorkOrders, order.WorkOrderID)
+	}
+	// the asset's own terms always move, the building's once no asset of it is left with the old provider
+	slaScopes := [][]string{{SLASCOPEASSET, event.AssetID}}
+	if state.Building != nil && *state.Building != "" && transfer.From != "" {
+		handedOver, err := t.buildingHandedOver(stub, *state.Building, transfer.From)
+		if err != nil {
+			return nil, err
+		}
+		if handedOver {
+			slaScopes = append(slaScopes, []string{SLASCOPEBUILDING, *state.Building})
+		}
+	}
+	for _, scope := range slaScopes {
+		sla, err := t.getSLA(stub, scope[0], scope[1])
+		if err != nil {
+			return nil, err
+		}
+		if sla == nil || (scope[0] == SLASCOPEBUILDING && sla.Provider != transfer.From) {
+			continue
+		}
+		sla.Provider = transfer.To
+		sla.Updated = now
+		slaJSON, err := json.Marshal(sla)
+		if err != nil {
+			return nil, errors.New("Marshal failed for SLA" + fmt.Sprint(err))
+		}
+		err = stub.PutState(compositeKey(SLAKEYPREFIX, scope[0], scope[1]), slaJSON)
+		if err != nil {
+			return nil, errors.New("PUT ledger state failed for SLA: " + fmt.Sprint(err))
+		}
+	}
+
+	transfer.Status = TRANSFERACCEPTED
+	transfer.Closed = now
+	if event.Note != "" {
+		transfer.Note = strings.TrimSpace(transfer.Note + "\n" + event.Note)
+	}
+	return nil, t.putTransfer(stub, transfer)
+}
+
+//******************** cancelTransfer ********************/
+
+// cancelTransfer - withdraws a pending transfer, by the organisation that proposed it, or
+// declines it, by the new provider. Admins may cancel any pending transfer.
+func (t *SimpleChaincode) cancelTransfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	event, err := t.validateTransferInput(args, true)
+	if err != nil {
+		return nil, err
+	}
+	transfer, err := t.getTransfer(stub, event.AssetID, event.TransferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != TRANSFERPENDING {
+		return nil, errors.New("Transfer is " + transfer.Status + ": " + transfer.TransferID)
+	}
+	org := callerOrganization(stub)
+	if org == "" || !isOneOf(org, transfer.ProposedBy, transfer.To) {
+		err = t.requireRole(stub, ROLEADMIN)
+		if err != nil {
+			return nil, errors.New("Only the proposer or the new provider may cancel a transfer: " + fmt.Sprint(err))
+		}
+	}
+	transfer.Status = TRANSFERCANCELLED
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Closed = formatTime(now)
+	if event.Note != "" {
+		transfer.Note = strings.TrimSpace(transfer.Note + "\n" + event.Note)
+	}
+	return nil, t.putTransfer(stub, transfer)
+}
+
+//******************** readTransfers ********************/
+
+// readTransfers - the transfers of an asset, pending and past. Transfers are kept when the asset
+// itself is deleted.
+func (t *SimpleChaincode) readTransfers(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	event, err := t.validateTransferInput(args, false)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := t.getTransfers(stub, event.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	if transfers == nil {
+		transfers = []Transfer{}
+	}
+	return json.Marshal(transfers)
+}
+
+/*********************************  internal: transfers ****************************/
+
+// validateTransferInput - unmarshals the single JSON argument, assetID is always mandatory
+// and transferID when the function acts on an existing transfer
+func (t *SimpleChaincode) validateTransferInput(args []string, needID bool) (TransferEvent, error) {
+	var event TransferEvent
+	if len(args) != 1 {
+		return event, errors.New("Incorrect number of arguments. Expecting a JSON string with mandatory assetID")
+	}
+	err := json.Unmarshal([]byte(args[0]), &event)
+	if err != nil {
+		return event, errors.New("Unable to unmarshal input JSON data")
+	}
+	event.AssetID = strings.TrimSpace(event.AssetID)
+	event.TransferID = strings.TrimSpace(event.TransferID)
+	if event.AssetID == "" {
+		return event, errors.New("Asset id is mandatory in the input JSON data")
+	}
+	if needID && event.TransferID == "" {
+		return event, errors.New("Transfer id is mandatory in the input JSON data")
+	}
+	if strings.Contains(event.AssetID, KEYSEPARATOR) || strings.Contains(event.TransferID, KEYSEPARATOR) {
+		return event, errors.New("Input JSON data contains an invalid character")
+	}
+	return event, nil
+}
+
+// getTransferAsset - the current state of the asset being transferred
+func (t *SimpleChaincode) getTransferAsset(stub shim.ChaincodeStubInterface, assetID string) (AssetState, error) {
+	var state AssetState
+	assetBytes, err := stub.GetState(assetID)
+	if err != nil || len(assetBytes) == 0 {
+		return state, errors.New("Asset does not exist: " + assetID)
+	}
+	err = json.Unmarshal(assetBytes, &state)
+	if err != nil {
+		return state, errors.New("Unable to unmarshal state data obtained from ledger")
+	}
+	return state, nil
+}
+
+func (t *SimpleChaincode) getTransfer(stub shim.ChaincodeStubInterface, assetID string, transferID string) (Transfer, error) {
+	var transfer Transfer
+	transferBytes, err := stub.GetState(compositeKey(TRANSFERKEYPREFIX, assetID, transferID))
+	if err != nil || len(transferBytes) == 0 {
+		return transfer, errors.New("Transfer does not exist: " + transferID)
+	}
+	err = json.Unmarshal(transferBytes, &transfer)
+	if err != nil {
+		return transfer, errors.New("Unable to unmarshal transfer data obtained from ledger")
+	}
+	return transfer, nil
+}
+
+// getTransfers - all transfers of an asset, in key order
+func (t *SimpleChaincode) getTransfers(stub shim.ChaincodeStubInterface, assetID string) ([]Transfer, error) {
+	var transfers []Transfer
+	startKey, endKey := compositeRange(TRANSFERKEYPREFIX, assetID)
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return nil, errors.New("Unable to read transfers from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		var transfer Transfer
+		_, transferBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read transfers from ledger: " + fmt.Sprint(err))
+		}
+		err = json.Unmarshal(transferBytes, &transfer)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal transfer data obtained from ledger")
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers, nil
+}
+
+// getPendingTransfer - the pending transfer of an asset, nil when there is none
+func (t *SimpleChaincode) getPendingTransfer(stub shim.ChaincodeStubInterface, assetID string) (*Transfer, error) {
+	transfers, err := t.getTransfers(stub, assetID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range transfers {
+		if transfers[i].Status == TRANSFERPENDING {
+			return &transfers[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (t *SimpleChaincode) putTransfer(stub shim.ChaincodeStubInterface, transfer Transfer) error {
+	transferJSON, err := json.Marshal(transfer)
+	if err != nil {
+		return errors.New("Marshal failed for transfer" + fmt.Sprint(err))
+	}
+	err = stub.PutState(compositeKey(TRANSFERKEYPREFIX, transfer.AssetID, transfer.TransferID), transferJSON)
+	if err != nil {
+		return errors.New("PUT ledger state failed for transfer: " + fmt.Sprint(err))
+	}
+	return nil
+}
+
+// validateOwnership - the owner is set or changed by an admin only, and the provider is set by
+// an admin and changes only through a transfer. oldState is nil when the asset is created, which
+// leaves both unset before.
+func (t *SimpleChaincode) validateOwnership(stub shim.ChaincodeStubInterface, oldState *AssetState, newState AssetState) error {
+	if oldState == nil {
+		oldState = &AssetState{}
+	}
+	oldProvider, newProvider := stringValue(oldState.Provider), stringValue(newState.Provider)
+	if oldProvider != "" && newProvider != oldProvider {
+		return errors.New("Provider changes through proposeTransfer and acceptTransfer")
+	}
+	if oldProvider == "" && newProvider != "" {
+		err := t.requireRole(stub, ROLEADMIN)
+		if err != nil {
+			return errors.New("Only an admin may set the provider: " + fmt.Sprint(err))
+		}
+	}
+	if stringValue(newState.Owner) != stringValue(oldState.Owner) {
+		err := t.requireRole(stub, ROLEADMIN)
+		if err != nil {
+			return errors.New("Only an admin may change the owner: " + fmt.Sprint(err))
+		}
+	}
+	return nil
+}
+
+// buildingHandedOver - true when no asset of the building is still maintained by provider
+func (t *SimpleChaincode) buildingHandedOver(stub shim.ChaincodeStubInterface, building string, provider string) (bool, error) {
+	assetIDs, err := t.getBuildingAssets(stub, building)
+	if err != nil {
+		return false, err
+	}
+	for _, assetID := range assetIDs {
+		state, err := t.getTransferAsset(stub, assetID)
+		if err != nil {
+			return false, err
+		}
+		if stringValue(state.Provider) == provider {
+			return false, nil
+		}
+	}
+	return true, nil
+}
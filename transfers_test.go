@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestTransferAccepted(t *testing.T) {
+	var transfers []Transfer
+	var orders []WorkOrder
+	var assetSLA, buildingSLA SLA
+	stub := newTestStub(t)
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","building":"B1","owner":"Main St","provider":"Acme"}`)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E2","building":"B1","provider":"Acme"}`)
+	stub.mustInvoke(t, "setSLA", `{"assetID":"E1","provider":"Acme","responseTimeHours":2}`)
+	stub.mustInvoke(t, "setSLA", `{"building":"B1","provider":"Acme","responseTimeHours":4}`)
+	stub.as("", "")
+	stub.mustInvoke(t, "openWorkOrder", `{"assetID":"E1","workOrderID":"W1"}`)
+
+	stub.as("", "Main St")
+	stub.mustInvoke(t, "proposeTransfer", `{"assetID":"E1","to":"Lift Co","checklist":[{"item":"keys"}]}`)
+	stub.mustQuery(t, "readTransfers", `{"assetID":"E1"}`, &transfers)
+	if len(transfers) != 1 || transfers[0].Status != TRANSFERPENDING || transfers[0].From != "Acme" || transfers[0].ProposedBy != "Main St" {
+		t.Fatalf("unexpected transfers %+v", transfers)
+	}
+	id := transfers[0].TransferID
+
+	stub.as("", "Lift Co")
+	stub.mustFail(t, "acceptTransfer", `{"assetID":"E1","transferID":"`+id+`"}`, "Checklist item is not done: keys")
+	stub.mustInvoke(t, "acceptTransfer", `{"assetID":"E1","transferID":"`+id+`","checklist":[{"item":"keys","done":true}]}`)
+	if asset := stub.readAsset(t, "E1"); asset.Provider == nil || *asset.Provider != "Lift Co" {
+		t.Fatalf("provider did not move %+v", asset.Provider)
+	}
+	var accepted []Transfer
+	stub.mustQuery(t, "readTransfers", `{"assetID":"E1"}`, &accepted)
+	if accepted[0].Status != TRANSFERACCEPTED || accepted[0].Snapshot == nil || *accepted[0].Snapshot.Provider != "Acme" || len(accepted[0].WorkOrders) != 1 {
+		t.Fatalf("unexpected accepted transfer %+v", accepted[0])
+	}
+	stub.mustQuery(t, "readWorkOrders", `{"assetID":"E1"}`, &orders)
+	if orders[0].Provider != "Lift Co" {
+		t.Fatalf("work order did not move %+v", orders[0])
+	}
+	stub.mustQuery(t, "readSLA", `{"assetID":"E1"}`, &assetSLA)
+	stub.mustQuery(t, "readSLA", `{"building":"B1"}`, &buildingSLA)
+	if assetSLA.Provider != "Lift Co" || buildingSLA.Provider != "Acme" {
+		t.Fatalf("building SLA moved while E2 is still with Acme %+v %+v", assetSLA, buildingSLA)
+	}
+
+	// the building terms follow once its last asset is handed over
+	stub.as("", "Acme")
+	stub.mustInvoke(t, "proposeTransfer", `{"assetID":"E2","to":"Lift Co"}`)
+	var pending []Transfer
+	stub.mustQuery(t, "readTransfers", `{"assetID":"E2"}`, &pending)
+	stub.as("", "Lift Co")
+	stub.mustInvoke(t, "acceptTransfer", `{"assetID":"E2","transferID":"`+pending[0].TransferID+`"}`)
+	var movedSLA SLA
+	stub.mustQuery(t, "readSLA", `{"building":"B1"}`, &movedSLA)
+	if movedSLA.Provider != "Lift Co" {
+		t.Fatalf("building SLA did not move %+v", movedSLA)
+	}
+}
+
+func TestTransferRejected(t *testing.T) {
+	var transfers []Transfer
+	stub := newTestStub(t)
+	stub.as("", "Acme")
+	stub.mustFail(t, "createAsset", `{"assetID":"E1","owner":"Acme"}`, "Only an admin may change the owner")
+	stub.mustFail(t, "createAsset", `{"assetID":"E1","provider":"Acme"}`, "Only an admin may set the provider")
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+	stub.mustFail(t, "updateAsset", `{"assetID":"E1","provider":"Acme"}`, "Only an admin may set the provider")
+	stub.mustFail(t, "updateAsset", `{"assetID":"E1","owner":"Acme"}`, "Only an admin may change the owner")
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","owner":"Main St","provider":"Acme"}`)
+	stub.mustFail(t, "updateAsset", `{"assetID":"E1","provider":"Lift Co"}`, "Provider changes through proposeTransfer")
+
+	stub.as("", "Acme")
+	stub.mustFail(t, "proposeTransfer", `{"assetID":"E1"}`, "New provider is mandatory")
+	stub.mustFail(t, "proposeTransfer", `{"assetID":"E1","to":"Acme"}`, "Asset is already maintained by Acme")
+	stub.mustFail(t, "proposeTransfer", `{"assetID":"E9","to":"Lift Co"}`, "Asset does not exist")
+	stub.as("", "Other")
+	stub.mustFail(t, "proposeTransfer", `{"assetID":"E1","to":"Lift Co"}`, "Only the owner or the current provider")
+	stub.as("", "Acme")
+	stub.mustInvoke(t, "proposeTransfer", `{"assetID":"E1","to":"Lift Co"}`)
+	stub.mustFail(t, "proposeTransfer", `{"assetID":"E1","to":"Other"}`, "Transfer already pending")
+	stub.mustQuery(t, "readTransfers", `{"assetID":"E1"}`, &transfers)
+	id := transfers[0].TransferID
+
+	stub.as("", "Other")
+	stub.mustFail(t, "acceptTransfer", `{"assetID":"E1","transferID":"`+id+`"}`, "Only Lift Co may accept the transfer")
+	stub.mustFail(t, "cancelTransfer", `{"assetID":"E1","transferID":"`+id+`"}`, "Only the proposer or the new provider may cancel")
+	stub.as("", "Lift Co")
+	stub.mustInvoke(t, "cancelTransfer", `{"assetID":"E1","transferID":"`+id+`"}`)
+	stub.mustFail(t, "acceptTransfer", `{"assetID":"E1","transferID":"`+id+`"}`, "Transfer is cancelled")
+	if asset := stub.readAsset(t, "E1"); *asset.Provider != "Acme" {
+		t.Fatalf("cancelled transfer moved the provider %+v", asset.Provider)
+	}
+}
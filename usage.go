@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// USAGEKEYPREFIX - object type for usage counter records
+const USAGEKEYPREFIX string = "USAGE"
+
+// AssetUsage - usage counters derived from successive asset state updates
+type AssetUsage struct {
+	AssetID         string  `json:"assetID"`         // asset the counters belong to
+	TotalTrips      int64   `json:"totalTrips"`      // completed trips, counted when the car stops
+	FloorsTravelled int64   `json:"floorsTravelled"` // sum of floor changes between updates
+	DoorCycles      int64   `json:"doorCycles"`      // completed door open / close cycles
+	RunningHours    float64 `json:"runningHours"`    // time spent moving, in hours
+	LastUpdated     string  `json:"lastUpdated"`     // RFC3339 timestamp of the last state update
+}
+
+//*************readAssetUsage*******************/
+
+func (t *SimpleChaincode) readAssetUsage(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	stateIn, err := t.validateInput(args)
+	if err != nil {
+		return nil, err
+	}
+	usageBytes, err := stub.GetState(compositeKey(USAGEKEYPREFIX, *stateIn.AssetID))
+	if err != nil || len(usageBytes) == 0 {
+		return nil, errors.New("Unable to get asset usage from ledger")
+	}
+	return usageBytes, nil
+}
+
+/*********************************  internal: updateAssetUsage ****************************/
+
+// updateAssetUsage - advances the usage counters of an asset from its previous
+// state to its new state. oldState is nil when the asset is created.
+func (t *SimpleChaincode) updateAssetUsage(stub shim.ChaincodeStubInterface, oldState *AssetState, newState AssetState) error {
+	var usage AssetUsage
+	assetID := *newState.AssetID
+	key := compositeKey(USAGEKEYPREFIX, assetID)
+	now, err := txTime(stub)
+	if err != nil {
+		return err
+	}
+
+	usageBytes, err := stub.GetState(key)
+	if err == nil && len(usageBytes) > 0 {
+		err = json.Unmarshal(usageBytes, &usage)
+		if err != nil {
+			return errors.New("Unable to unmarshal usage data obtained from ledger")
+		}
+	}
+	usage.AssetID = assetID
+
+	if oldState != nil {
+		wasMoving := isMoving(*oldState)
+		// completed trip
+		if wasMoving && !isMoving(newState) {
+			usage.TotalTrips++
+		}
+		if oldState.Floor != nil && newState.Floor != nil && *oldState.Floor != *newState.Floor {
+			usage.FloorsTravelled += int64(math.Abs(float64(*newState.Floor - *oldState.Floor)))
+		}
+		// a door cycle completes when the doors close again
+		if oldState.DoorStatus != nil && newState.DoorStatus != nil &&
+			*oldState.DoorStatus != DOORCLOSED && *newState.DoorStatus == DOORCLOSED {
+			usage.DoorCycles++
+		}
+		// the car was running for the whole interval since the last update
+		if wasMoving && usage.LastUpdated != "" {
+			last, err := parseTime(usage.LastUpdated)
+			if err == nil && now.After(last) {
+				usage.RunningHours += now.Sub(last).Hours()
+			}
+		}
+	}
+	usage.LastUpdated = formatTime(now)
+
+	usageJSON, err := json.Marshal(usage)
+	if err != nil {
+		return errors.New("Marshal failed for asset usage" + fmt.Sprint(err))
+	}
+	err = stub.PutState(key, usageJSON)
+	if err != nil {
+		return errors.New("PUT ledger state failed for asset usage: " + fmt.Sprint(err))
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAssetUsage(t *testing.T) {
+	var usage AssetUsage
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","floor":1,"direction":"stopped","doorStatus":"open"}`)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","doorStatus":"closed"}`)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","direction":"up","speed":200}`)
+	stub.now = stub.now.Add(30 * time.Minute)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","floor":5,"direction":"stopped","speed":0}`)
+
+	stub.mustQuery(t, "readAssetUsage", `{"assetID":"E1"}`, &usage)
+	if usage.TotalTrips != 1 || usage.FloorsTravelled != 4 || usage.DoorCycles != 1 || usage.RunningHours != 0.5 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+	if usage.LastUpdated != formatTime(stub.now) {
+		t.Fatalf("lastUpdated %s, expecting %s", usage.LastUpdated, formatTime(stub.now))
+	}
+}
+
+func TestAssetUsageRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFailQuery(t, "readAssetUsage", `{"assetID":"E1"}`, "Unable to get asset usage")
+	stub.mustFailQuery(t, "readAssetUsage", `{}`, "mandatory")
+}
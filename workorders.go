@@ -0,0 +1,377 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// WORKORDERKEYPREFIX - object type for work order records, keyed by assetID and workOrderID
+const WORKORDERKEYPREFIX string = "WORKORDER"
+
+// work order states
+const (
+	WOOPEN       string = "open"
+	WOASSIGNED   string = "assigned"
+	WOINPROGRESS string = "inProgress"
+	WOONHOLD     string = "onHold"
+	WOCLOSED     string = "closed"
+)
+
+// work order sources
+const (
+	WOSOURCEMANUAL string = "manual"
+	WOSOURCEALARM  string = "alarm"
+)
+
+// workOrderTransitions - the work order state machine, current state to allowed next states
+var workOrderTransitions = map[string][]string{
+	WOOPEN:       {WOASSIGNED, WOCLOSED},
+	WOASSIGNED:   {WOASSIGNED, WOINPROGRESS, WOCLOSED},
+	WOINPROGRESS: {WOONHOLD, WOCLOSED},
+	WOONHOLD:     {WOINPROGRESS, WOCLOSED},
+}
+
+// WorkOrderNote - a timestamped note on a work order
+type WorkOrderNote struct {
+	Timestamp string `json:"timestamp"`
+	Status    string `json:"status"` // work order status when the note was added
+	Text      string `json:"text"`
+}
+
+// WorkOrder - a maintenance work order linked to an asset
+type WorkOrder struct {
+	WorkOrderID  string          `json:"workOrderID"`
+	AssetID      string          `json:"assetID"`
+	Status       string          `json:"status"`
+	Source       string          `json:"source"` // manual, or alarm when opened by createOrUpdateAsset
+	Description  string          `json:"description,omitempty"`
+	Technician   string          `json:"technician,omitempty"`
+	Provider     string          `json:"provider,omitempty"` // maintenance provider responsible, follows the asset on transfer
+	Resolution   string          `json:"resolution,omitempty"`
+	Opened       string          `json:"opened"`
+	Assigned     string          `json:"assigned,omitempty"`
+	Started      string          `json:"started,omitempty"`
+	Closed       string          `json:"closed,omitempty"`
+	Updated      string          `json:"updated"`
+	Notes        []WorkOrderNote `json:"notes,omitempty"`
+	OutOfService bool            `json:"outOfService,omitempty"` // the asset is out of service until the order closes
+}
+
+// WorkOrderEvent - argument to the work order functions
+type WorkOrderEvent struct {
+	AssetID      string `json:"assetID"`
+	WorkOrderID  string `json:"workOrderID,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Technician   string `json:"technician,omitempty"`
+	Status       string `json:"status,omitempty"`
+	Resolution   string `json:"resolution,omitempty"`
+	Note         string `json:"note,omitempty"`
+	OutOfService bool   `json:"outOfService,omitempty"` // openWorkOrder only, takes the asset out of service
+}
+
+//******************** openWorkOrder ********************/
+
+func (t *SimpleChaincode) openWorkOrder(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var state AssetState
+
+	event, err := t.validateWorkOrderInput(args, false)
+	if err != nil {
+		return nil, err
+	}
+	assetBytes, err := stub.GetState(event.AssetID)
+	if err != nil || len(assetBytes) == 0 {
+		return nil, errors.New("Asset does not exist: " + event.AssetID)
+	}
+	err = json.Unmarshal(assetBytes, &state)
+	if err != nil {
+		return nil, errors.New("Unable to unmarshal state data obtained from ledger")
+	}
+	if event.WorkOrderID == "" {
+		event.WorkOrderID = stub.GetTxID()
+	}
+	existing, err := stub.GetState(compositeKey(WORKORDERKEYPREFIX, event.AssetID, event.WorkOrderID))
+	if err == nil && len(existing) > 0 {
+		return nil, errors.New("Work order already exists: " + event.WorkOrderID)
+	}
+	order, err := t.newWorkOrder(stub, state, event.WorkOrderID, WOSOURCEMANUAL, event.Description)
+	if err != nil {
+		return nil, err
+	}
+	order.OutOfService = event.OutOfService
+	addWorkOrderNote(&order, order.Opened, event.Note)
+	if order.OutOfService {
+		err = t.putOutage(stub, Outage{AssetID: order.AssetID, Start: order.Opened, Reason: OUTAGEWORKORDER + order.WorkOrderID})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, t.putWorkOrder(stub, order)
+}
+
+//******************** assignTechnician ********************/
+
+func (t *SimpleChaincode) assignTechnician(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	event, err := t.validateWorkOrderInput(args, true)
+	if err != nil {
+		return nil, err
+	}
+	if event.Technician == "" {
+		return nil, errors.New("Technician is mandatory when assigning a work order")
+	}
+	order, err := t.getWorkOrder(stub, event.AssetID, event.WorkOrderID)
+	if err != nil {
+		return nil, err
+	}
+	tm, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	now := formatTime(tm)
+	err = transitionWorkOrder(&order, WOASSIGNED, now)
+	if err != nil {
+		return nil, err
+	}
+	order.Technician = event.Technician
+	order.Assigned = now
+	addWorkOrderNote(&order, now, event.Note)
+	return nil, t.putWorkOrder(stub, order)
+}
+
+//******************** updateWorkOrder ********************/
+
+func (t *SimpleChaincode) updateWorkOrder(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	event, err := t.validateWorkOrderInput(args, true)
+	if err != nil {
+		return nil, err
+	}
+	order, err := t.getWorkOrder(stub, event.AssetID, event.WorkOrderID)
+	if err != nil {
+		return nil, err
+	}
+	tm, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	now := formatTime(tm)
+	if event.Status != "" && event.Status != order.Status {
+		// assignment and closure carry extra data and have their own functions
+		if event.Status == WOASSIGNED || event.Status == WOCLOSED {
+			return nil, errors.New("Use assignTechnician or closeWorkOrder to move a work order to " + event.Status)
+		}
+		err = transitionWorkOrder(&order, event.Status, now)
+		if err != nil {
+			return nil, err
+		}
+		if event.Status == WOINPROGRESS && order.Started == "" {
+			order.Started = now
+		}
+	} else if event.Note == "" && event.Description == "" {
+		return nil, errors.New("Nothing to update, expecting a status, description or note")
+	} else if order.Status == WOCLOSED {
+		return nil, errors.New("Work order is closed: " + order.WorkOrderID)
+	}
+	if event.Description != "" {
+		order.Description = event.Description
+	}
+	order.Updated = now
+	addWorkOrderNote(&order, now, event.Note)
+	return nil, t.putWorkOrder(stub, order)
+}
+
+//******************** closeWorkOrder ********************/
+
+func (t *SimpleChaincode) closeWorkOrder(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	event, err := t.validateWorkOrderInput(args, true)
+	if err != nil {
+		return nil, err
+	}
+	order, err := t.getWorkOrder(stub, event.AssetID, event.WorkOrderID)
+	if err != nil {
+		return nil, err
+	}
+	tm, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	now := formatTime(tm)
+	err = transitionWorkOrder(&order, WOCLOSED, now)
+	if err != nil {
+		return nil, err
+	}
+	order.Closed = now
+	order.Resolution = event.Resolution
+	addWorkOrderNote(&order, now, event.Note)
+	if order.OutOfService {
+		err = t.closeOutages(stub, order.AssetID, OUTAGEWORKORDER+order.WorkOrderID, tm)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, t.putWorkOrder(stub, order)
+}
+
+//******************** readWorkOrders ********************/
+
+// readWorkOrders - returns the work orders of an asset, optionally only those in a given status.
+// Work orders are service history and are kept when the asset itself is deleted.
+func (t *SimpleChaincode) readWorkOrders(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	event, err := t.validateWorkOrderInput(args, false)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := t.getWorkOrders(stub, event.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	var selected = []WorkOrder{}
+	for _, order := range orders {
+		if event.Status == "" || event.Status == order.Status {
+			selected = append(selected, order)
+		}
+	}
+	ordersJSON, err := json.Marshal(selected)
+	if err != nil {
+		return nil, errors.New("Marshal failed for work orders" + fmt.Sprint(err))
+	}
+	return ordersJSON, nil
+}
+
+/*********************************  internal: work orders ****************************/
+
+// validateWorkOrderInput - unmarshals the single JSON argument, assetID is always mandatory
+// and workOrderID when the function acts on an existing order
+func (t *SimpleChaincode) validateWorkOrderInput(args []string, needID bool) (WorkOrderEvent, error) {
+	var event WorkOrderEvent
+	if len(args) != 1 {
+		return event, errors.New("Incorrect number of arguments. Expecting a JSON string with mandatory assetID")
+	}
+	err := json.Unmarshal([]byte(args[0]), &event)
+	if err != nil {
+		return event, errors.New("Unable to unmarshal input JSON data")
+	}
+	event.AssetID = strings.TrimSpace(event.AssetID)
+	event.WorkOrderID = strings.TrimSpace(event.WorkOrderID)
+	if event.AssetID == "" {
+		return event, errors.New("Asset id is mandatory in the input JSON data")
+	}
+	if needID && event.WorkOrderID == "" {
+		return event, errors.New("Work order id is mandatory in the input JSON data")
+	}
+	if strings.Contains(event.AssetID, KEYSEPARATOR) || strings.Contains(event.WorkOrderID, KEYSEPARATOR) {
+		return event, errors.New("Input JSON data contains an invalid character")
+	}
+	return event, nil
+}
+
+// newWorkOrder - a work order in the open state, for the current provider of the asset
+func (t *SimpleChaincode) newWorkOrder(stub shim.ChaincodeStubInterface, state AssetState, workOrderID string, source string, description string) (WorkOrder, error) {
+	tm, err := txTime(stub)
+	if err != nil {
+		return WorkOrder{}, err
+	}
+	now := formatTime(tm)
+	order := WorkOrder{
+		WorkOrderID: workOrderID,
+		AssetID:     *state.AssetID,
+		Status:      WOOPEN,
+		Source:      source,
+		Description: description,
+		Opened:      now,
+		Updated:     now,
+	}
+	if state.Provider != nil {
+		order.Provider = *state.Provider
+	}
+	return order, nil
+}
+
+// openAlarmWorkOrders - opens a work order for every alarm the asset entered with this update
+func (t *SimpleChaincode) openAlarmWorkOrders(stub shim.ChaincodeStubInterface, oldState *AssetState, newState AssetState) error {
+	for _, alarm := range newState.Alarms {
+		if oldState != nil && isOneOf(alarm, oldState.Alarms...) {
+			continue
+		}
+		order, err := t.newWorkOrder(stub, newState, stub.GetTxID()+"-"+alarm, WOSOURCEALARM,
+			"Opened automatically, asset entered "+alarm+" alarm")
+		if err != nil {
+			return err
+		}
+		err = t.putWorkOrder(stub, order)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transitionWorkOrder - moves a work order to its next state if the state machine allows it
+func transitionWorkOrder(order *WorkOrder, status string, now string) error {
+	allowed, found := workOrderTransitions[order.Status]
+	if !found || !isOneOf(status, allowed...) {
+		return errors.New("Work order " + order.WorkOrderID + " cannot move from " + order.Status + " to " + status)
+	}
+	order.Status = status
+	order.Updated = now
+	return nil
+}
+
+// addWorkOrderNote - appends a note when one was passed
+func addWorkOrderNote(order *WorkOrder, now string, text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	order.Notes = append(order.Notes, WorkOrderNote{Timestamp: now, Status: order.Status, Text: text})
+}
+
+func (t *SimpleChaincode) getWorkOrder(stub shim.ChaincodeStubInterface, assetID string, workOrderID string) (WorkOrder, error) {
+	var order WorkOrder
+	orderBytes, err := stub.GetState(compositeKey(WORKORDERKEYPREFIX, assetID, workOrderID))
+	if err != nil || len(orderBytes) == 0 {
+		return order, errors.New("Work order does not exist: " + workOrderID)
+	}
+	err = json.Unmarshal(orderBytes, &order)
+	if err != nil {
+		return order, errors.New("Unable to unmarshal work order data obtained from ledger")
+	}
+	return order, nil
+}
+
+// getWorkOrders - all work orders of an asset, in key order
+func (t *SimpleChaincode) getWorkOrders(stub shim.ChaincodeStubInterface, assetID string) ([]WorkOrder, error) {
+	var orders []WorkOrder
+	startKey, endKey := compositeRange(WORKORDERKEYPREFIX, assetID)
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return nil, errors.New("Unable to read work orders from ledger: " + fmt.Sprint(err))
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		var order WorkOrder
+		_, orderBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("Unable to read work orders from ledger: " + fmt.Sprint(err))
+		}
+		err = json.Unmarshal(orderBytes, &order)
+		if err != nil {
+			return nil, errors.New("Unable to unmarshal work order data obtained from ledger")
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+func (t *SimpleChaincode) putWorkOrder(stub shim.ChaincodeStubInterface, order WorkOrder) error {
+	orderJSON, err := json.Marshal(order)
+	if err != nil {
+		return errors.New("Marshal failed for work order" + fmt.Sprint(err))
+	}
+	err = stub.PutState(compositeKey(WORKORDERKEYPREFIX, order.AssetID, order.WorkOrderID), orderJSON)
+	if err != nil {
+		return errors.New("PUT ledger state failed for work order: " + fmt.Sprint(err))
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestWorkOrderLifecycle(t *testing.T) {
+	var orders []WorkOrder
+	stub := newTestStub(t)
+	stub.as(ROLEADMIN, "")
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","provider":"Acme"}`)
+	stub.as("", "")
+	stub.mustInvoke(t, "openWorkOrder", `{"assetID":"E1","workOrderID":"W1","description":"Door sticks","note":"reported by tenant"}`)
+	stub.mustInvoke(t, "assignTechnician", `{"assetID":"E1","workOrderID":"W1","technician":"Sam"}`)
+	stub.mustInvoke(t, "updateWorkOrder", `{"assetID":"E1","workOrderID":"W1","status":"inProgress"}`)
+	stub.mustInvoke(t, "updateWorkOrder", `{"assetID":"E1","workOrderID":"W1","status":"onHold","note":"waiting for parts"}`)
+	stub.mustInvoke(t, "updateWorkOrder", `{"assetID":"E1","workOrderID":"W1","status":"inProgress"}`)
+	stub.mustInvoke(t, "closeWorkOrder", `{"assetID":"E1","workOrderID":"W1","resolution":"Replaced door roller"}`)
+
+	stub.mustQuery(t, "readWorkOrders", `{"assetID":"E1"}`, &orders)
+	if len(orders) != 1 {
+		t.Fatalf("expecting one work order, got %+v", orders)
+	}
+	order := orders[0]
+	if order.Status != WOCLOSED || order.Technician != "Sam" || order.Provider != "Acme" || order.Source != WOSOURCEMANUAL ||
+		order.Resolution != "Replaced door roller" || order.Started == "" || order.Closed == "" || len(order.Notes) != 2 {
+		t.Fatalf("unexpected work order %+v", order)
+	}
+}
+
+func TestWorkOrderOpenedByAlarm(t *testing.T) {
+	var orders []WorkOrder
+	stub := newTestStub(t)
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1","temperature":70}`)
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","temperature":120}`)
+	// staying in alarm opens no second order
+	stub.mustInvoke(t, "updateAsset", `{"assetID":"E1","temperature":125}`)
+
+	stub.mustQuery(t, "readWorkOrders", `{"assetID":"E1","status":"open"}`, &orders)
+	if len(orders) != 1 || orders[0].Source != WOSOURCEALARM {
+		t.Fatalf("expecting one alarm work order, got %+v", orders)
+	}
+}
+
+func TestWorkOrderRejected(t *testing.T) {
+	stub := newTestStub(t)
+	stub.mustFail(t, "openWorkOrder", `{"assetID":"E1"}`, "Asset does not exist")
+	stub.mustInvoke(t, "createAsset", `{"assetID":"E1"}`)
+	stub.mustFail(t, "openWorkOrder", `{"workOrderID":"W1"}`, "Asset id is mandatory")
+	stub.mustFail(t, "openWorkOrder", `{"assetID":"E1","workOrderID":"W\u00001"}`, "invalid character")
+	stub.mustInvoke(t, "openWorkOrder", `{"assetID":"E1","workOrderID":"W1"}`)
+	stub.mustFail(t, "openWorkOrder", `{"assetID":"E1","workOrderID":"W1"}`, "already exists")
+	stub.mustFail(t, "assignTechnician", `{"assetID":"E1","workOrderID":"W1"}`, "Technician is mandatory")
+	stub.mustFail(t, "updateWorkOrder", `{"assetID":"E1","workOrderID":"W1","status":"inProgress"}`, "cannot move from open")
+	stub.mustFail(t, "updateWorkOrder", `{"assetID":"E1","workOrderID":"W1","status":"closed"}`, "Use assignTechnician or closeWorkOrder")
+	stub.mustFail(t, "updateWorkOrder", `{"assetID":"E1","workOrderID":"W1"}`, "Nothing to update")
+	stub.mustFail(t, "closeWorkOrder", `{"assetID":"E1","workOrderID":"W2"}`, "does not exist")
+	stub.mustInvoke(t, "closeWorkOrder", `{"assetID":"E1","workOrderID":"W1"}`)
+	stub.mustFail(t, "closeWorkOrder", `{"assetID":"E1","workOrderID":"W1"}`, "cannot move from closed")
+	stub.mustFail(t, "updateWorkOrder", `{"assetID":"E1","workOrderID":"W1","note":"late"}`, "closed")
+}